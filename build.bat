@@ -7,7 +7,7 @@ echo   w2app Build Script
 echo ========================================
 echo.
 
-echo [1/2] Building stub...
+echo [1/3] Building stub...
 go build -mod=vendor -ldflags="-s -w -H windowsgui" -o internal\generator\stubs\stub-windows-amd64.exe .\cmd\stub
 if %errorlevel% neq 0 (
     echo [ERROR] Failed to build stub
@@ -15,7 +15,15 @@ if %errorlevel% neq 0 (
 )
 echo       Done!
 
-echo [2/2] Building w2app...
+echo [2/3] Writing stub manifest...
+go run -mod=vendor .\cmd\w2app platforms --write-manifest internal\generator\stubs
+if %errorlevel% neq 0 (
+    echo [ERROR] Failed to write stub manifest
+    exit /b 1
+)
+echo       Done!
+
+echo [3/3] Building w2app...
 go build -mod=vendor -ldflags="-s -w" -o w2app.exe .\cmd\w2app
 if %errorlevel% neq 0 (
     echo [ERROR] Failed to build w2app
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"os"
+	"slices"
+	"time"
+
+	"github.com/jchv/go-webview2"
+	"github.com/user/w2app/internal/appdata"
+	"github.com/user/w2app/internal/config"
+)
+
+var (
+	dataRoot string // Data directory of the app, holding the default profile and the named profiles
+	dataDir  string // Data directory of the current profile: WebView2 user data and window state
+)
+
+// resolveDataDir returns the data directory of the app
+func resolveDataDir(cfg *config.AppConfig) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	return appdata.Dir(cfg.DataDir, cfg.Portable, cfg.AppID, appdata.Env{
+		Exe:          exe,
+		LocalAppData: os.Getenv("LOCALAPPDATA"),
+		AppData:      os.Getenv("AppData"),
+	})
+}
+
+// clearTargets returns the data to remove on exit
+func clearTargets(cfg *config.AppConfig) []string {
+	targets := slices.Clone(cfg.ClearOnExit)
+	if cfg.ClearCacheOnExit && !slices.Contains(targets, appdata.ClearCache) {
+		targets = append(targets, appdata.ClearCache)
+	}
+	return targets
+}
+
+// clearAppData removes this app's cache, cookies or storage after the window
+// has closed. The browser process keeps its files open for a moment after the
+// controller is closed, so removal is retried; whatever is still locked is
+// removed on the next launch instead.
+func clearAppData(w webview2.WebView, cfg *config.AppConfig) {
+	targets := clearTargets(cfg)
+	if len(targets) == 0 || dataDir == "" {
+		return
+	}
+
+	if chromium := chromiumOf(w); chromium != nil {
+		if controller := chromium.GetController(); controller != nil {
+			controller.Close()
+		}
+	}
+
+	var err error
+	for range 30 {
+		if err = appdata.Clear(dataDir, targets); err == nil {
+			return
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	debugLog("Clear on exit: %v", err)
+	if err := appdata.MarkPending(dataDir, targets); err != nil {
+		debugLog("Clear on exit: %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"os"
+
+	"github.com/user/w2app/internal/config"
+	"github.com/user/w2app/internal/navpolicy"
+)
+
+// startURL returns the page the app opens with: the URL of a deep link passed
+// on the command line, or else the configured URL. A deep link the navigation
+// policy does not allow in the app is ignored.
+func startURL(cfg *config.AppConfig) string {
+	target, ok := deepLinks.Args(os.Args[1:])
+	if !ok {
+		return cfg.URL
+	}
+	if navPolicy.Decide(navpolicy.Request{URL: target, UserInitiated: true}) != navpolicy.Allow {
+		debugLog("Deep link %s not allowed in the app, opening %s", target, cfg.URL)
+		return cfg.URL
+	}
+	debugLog("Starting at deep link %s", target)
+	return target
+}
@@ -0,0 +1,395 @@
+package main
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf16"
+	"unsafe"
+
+	"github.com/energye/systray"
+	"github.com/go-toast/toast"
+	"github.com/jchv/go-webview2/pkg/edge"
+	"github.com/user/w2app/internal/appdata"
+	"github.com/user/w2app/internal/config"
+	"github.com/user/w2app/internal/download"
+	"golang.org/x/sys/windows"
+)
+
+// Flags for the save dialog
+const (
+	OFN_OVERWRITEPROMPT = 0x00000002
+	OFN_NOCHANGEDIR     = 0x00000008
+	OFN_PATHMUSTEXIST   = 0x00000800
+	OFN_EXPLORER        = 0x00080000
+)
+
+var (
+	comdlg32             = syscall.NewLazyDLL("comdlg32.dll")
+	procGetSaveFileNameW = comdlg32.NewProc("GetSaveFileNameW")
+)
+
+// openFileName is OPENFILENAMEW
+type openFileName struct {
+	lStructSize       uint32
+	hwndOwner         uintptr
+	hInstance         uintptr
+	lpstrFilter       *uint16
+	lpstrCustomFilter *uint16
+	nMaxCustFilter    uint32
+	nFilterIndex      uint32
+	lpstrFile         *uint16
+	nMaxFile          uint32
+	lpstrFileTitle    *uint16
+	nMaxFileTitle     uint32
+	lpstrInitialDir   *uint16
+	lpstrTitle        *uint16
+	flags             uint32
+	nFileOffset       uint16
+	nFileExtension    uint16
+	lpstrDefExt       *uint16
+	lCustData         uintptr
+	lpfnHook          uintptr
+	lpTemplateName    *uint16
+	pvReserved        uintptr
+	dwReserved        uint32
+	flagsEx           uint32
+}
+
+// activeDownload is a download that has not finished yet
+type activeDownload struct {
+	path string
+	url  string
+}
+
+var (
+	downloadDir string // Folder downloads are saved to
+
+	// Downloads in progress and the paths they will be saved to. Only
+	// touched on the UI thread.
+	activeDownloads   = map[*edge.ICoreWebView2DownloadOperation]activeDownload{}
+	reservedDownloads = map[string]bool{}
+
+	// Recent downloads shown in the tray
+	recentDownloads   []download.Entry
+	recentDownloadsMu sync.Mutex
+	downloadsMenu     []*systray.MenuItem
+	downloadsEmpty    *systray.MenuItem
+
+	downloadToastShortcut sync.Once
+)
+
+// downloadsConfig returns the downloads section of the config, or the defaults
+func downloadsConfig(cfg *config.AppConfig) config.Downloads {
+	if cfg.Downloads == nil {
+		return config.Downloads{}
+	}
+	return *cfg.Downloads
+}
+
+// resolveDownloadDir returns the folder downloads are saved to
+func resolveDownloadDir(cfg *config.AppConfig) (string, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return "", err
+	}
+	userDownloads, _ := windows.KnownFolderPath(windows.FOLDERID_Downloads, 0)
+	return download.Dir(downloadsConfig(cfg).Folder, userDownloads, appdata.Env{Exe: exe})
+}
+
+// watchDownloads handles the downloads started in a webview
+func watchDownloads(chromium *edge.Chromium) {
+	chromium.DownloadStartingCallback = onDownloadStarting
+	chromium.DownloadStateChangedCallback = onDownloadStateChanged
+}
+
+// onDownloadStarting picks where a download is saved. The app shows its own
+// toast and tray list instead of the WebView2 download flyout.
+func onDownloadStarting(sender *edge.ICoreWebView2, args *edge.ICoreWebView2DownloadStartingEventArgs) {
+	op, err := args.GetDownloadOperation()
+	if err != nil {
+		return
+	}
+	uri, _ := op.GetUri()
+	suggested, _ := args.GetResultFilePath()
+	name := download.FileName(suggested)
+	cfg := downloadsConfig(appConfig)
+
+	if !download.Allowed(name, cfg.AllowedTypes) {
+		debugLog("Download of %s blocked: file type not allowed", name)
+		args.PutCancel(true)
+		op.Release()
+		go showDownloadToast("Download blocked", name+" is not an allowed file type", "")
+		return
+	}
+	args.PutHandled(true)
+
+	if cfg.Mode == download.ModeAsk {
+		deferral, err := args.GetDeferral()
+		if err == nil {
+			// The save dialog is modal, so it is not shown inside the
+			// event handler
+			args.AddRef()
+			mainWindow.Dispatch(func() {
+				defer args.Release()
+				defer deferral.Release()
+				defer deferral.Complete()
+
+				path, ok := askSavePath(name, cfg.AllowedTypes)
+				if !ok || !download.Allowed(filepath.Base(path), cfg.AllowedTypes) {
+					debugLog("Download of %s cancelled", name)
+					args.PutCancel(true)
+					op.Release()
+					return
+				}
+				startDownload(args, op, path, uri)
+			})
+			return
+		}
+	}
+
+	path := download.Unique(filepath.Join(downloadDir, name), downloadExists)
+	startDownload(args, op, path, uri)
+}
+
+// startDownload saves the download to path and follows it until it finishes
+func startDownload(args *edge.ICoreWebView2DownloadStartingEventArgs, op *edge.ICoreWebView2DownloadOperation, path, uri string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		debugLog("Download folder: %v", err)
+		args.PutCancel(true)
+		op.Release()
+		return
+	}
+	args.PutResultFilePath(path)
+
+	chromium := chromiumOf(mainWindow)
+	if chromium == nil || chromium.WatchDownload(op) != nil {
+		op.Release()
+		return
+	}
+	activeDownloads[op] = activeDownload{path: path, url: uri}
+	reservedDownloads[strings.ToLower(path)] = true
+	debugLog("Downloading %s to %s", uri, path)
+}
+
+// onDownloadStateChanged records a finished download and tells the user
+func onDownloadStateChanged(op *edge.ICoreWebView2DownloadOperation) {
+	d, ok := activeDownloads[op]
+	if !ok {
+		return
+	}
+	state, err := op.GetState()
+	if err != nil || state == edge.COREWEBVIEW2_DOWNLOAD_STATE_IN_PROGRESS {
+		return
+	}
+	delete(activeDownloads, op)
+	delete(reservedDownloads, strings.ToLower(d.path))
+
+	if state == edge.COREWEBVIEW2_DOWNLOAD_STATE_COMPLETED {
+		if path, err := op.GetResultFilePath(); err == nil && path != "" {
+			d.path = path
+		}
+		debugLog("Download finished: %s", d.path)
+		addRecentDownload(download.Entry{Path: d.path, URL: d.url, Time: time.Now()})
+		go showDownloadToast("Download complete", filepath.Base(d.path), d.path)
+	} else {
+		reason, _ := op.GetInterruptReason()
+		debugLog("Download of %s interrupted (reason %d)", d.url, reason)
+	}
+	op.Release()
+}
+
+// downloadExists reports whether a path is taken by a file or by a download
+// that has not finished yet
+func downloadExists(path string) bool {
+	if reservedDownloads[strings.ToLower(path)] {
+		return true
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// askSavePath shows the save dialog for a download named name
+func askSavePath(name string, types []string) (string, bool) {
+	file := make([]uint16, 32768)
+	copy(file, windows.StringToUTF16(name))
+
+	filter := "All files\x00*.*\x00"
+	if len(types) > 0 {
+		var patterns []string
+		for _, t := range types {
+			patterns = append(patterns, "*"+download.NormalizeType(t))
+		}
+		filter = "Allowed files\x00" + strings.Join(patterns, ";") + "\x00"
+	}
+	// The filter is a list of NUL-separated strings ending in two NULs
+	filterUTF16 := utf16.Encode([]rune(filter + "\x00"))
+
+	ofn := openFileName{
+		hwndOwner:       mainHwnd,
+		lpstrFilter:     &filterUTF16[0],
+		lpstrFile:       &file[0],
+		nMaxFile:        uint32(len(file)),
+		lpstrInitialDir: windows.StringToUTF16Ptr(downloadDir),
+		flags:           OFN_OVERWRITEPROMPT | OFN_NOCHANGEDIR | OFN_PATHMUSTEXIST | OFN_EXPLORER,
+	}
+	if ext := strings.TrimPrefix(filepath.Ext(name), "."); ext != "" {
+		ofn.lpstrDefExt = windows.StringToUTF16Ptr(ext)
+	}
+	ofn.lStructSize = uint32(unsafe.Sizeof(ofn))
+
+	ret, _, _ := procGetSaveFileNameW.Call(uintptr(unsafe.Pointer(&ofn)))
+	if ret == 0 {
+		return "", false
+	}
+	return windows.UTF16ToString(file), true
+}
+
+// showDownloadToast shows a toast about a download. Clicking a toast for a
+// finished download opens the file.
+func showDownloadToast(title, message, path string) {
+	// Toasts need the Start Menu shortcut carrying the AppUserModelID
+	downloadToastShortcut.Do(func() {
+		if !appConfig.EnableNotification {
+			if err := ensureStartMenuShortcut(); err != nil {
+				debugLog("Start Menu shortcut: %v", err)
+			}
+		}
+	})
+
+	notification := toast.Notification{
+		AppID:   appUserModelID,
+		Title:   toastEscape(title),
+		Message: toastEscape(message),
+		Audio:   toast.Default,
+	}
+	if path != "" {
+		notification.ActivationType = "protocol"
+		notification.ActivationArguments = toastEscape(fileURL(path))
+		notification.Actions = []toast.Action{
+			{Type: "protocol", Label: "Open", Arguments: toastEscape(fileURL(path))},
+			{Type: "protocol", Label: "Show in folder", Arguments: toastEscape(fileURL(filepath.Dir(path)))},
+		}
+	}
+	if notificationIconPath == "" {
+		notificationIconPath = extractNotificationIcon()
+	}
+	notification.Icon = notificationIconPath
+
+	if err := notification.Push(); err != nil {
+		debugLog("Toast error: %v", err)
+	}
+}
+
+// toastEscape makes text safe inside the toast XML, which go-toast passes
+// through a PowerShell here-string
+func toastEscape(s string) string {
+	return strings.NewReplacer(
+		"&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;", "'", "&apos;",
+		"$", "&#36;", "`", "&#96;",
+	).Replace(s)
+}
+
+// fileURL returns the file:// URL of a local path
+func fileURL(path string) string {
+	return "file:///" + strings.ReplaceAll(filepath.ToSlash(path), " ", "%20")
+}
+
+// loadRecentDownloads reads the downloads recorded by earlier runs
+func loadRecentDownloads() {
+	entries, err := download.Recent(dataDir)
+	if err != nil {
+		debugLog("Recent downloads: %v", err)
+	}
+	recentDownloadsMu.Lock()
+	recentDownloads = entries
+	recentDownloadsMu.Unlock()
+}
+
+func addRecentDownload(e download.Entry) {
+	entries, err := download.AddRecent(dataDir, e)
+	if err != nil {
+		debugLog("Recent downloads: %v", err)
+		return
+	}
+	recentDownloadsMu.Lock()
+	recentDownloads = entries
+	recentDownloadsMu.Unlock()
+	updateDownloadsMenu()
+}
+
+// recentDownload returns the i-th recent download that still exists
+func recentDownload(i int) (download.Entry, bool) {
+	recentDownloadsMu.Lock()
+	defer recentDownloadsMu.Unlock()
+	for _, e := range recentDownloads {
+		if _, err := os.Stat(e.Path); err != nil {
+			continue
+		}
+		if i == 0 {
+			return e, true
+		}
+		i--
+	}
+	return download.Entry{}, false
+}
+
+// addDownloadsMenu adds the Downloads submenu to the tray. It has a fixed
+// number of slots that are filled with the recent downloads.
+func addDownloadsMenu() {
+	mDownloads := systray.AddMenuItem("Downloads", "Recent downloads")
+	downloadsEmpty = mDownloads.AddSubMenuItem("No downloads yet", "")
+	downloadsEmpty.Disable()
+
+	for i := 0; i < download.MaxRecent; i++ {
+		item := mDownloads.AddSubMenuItem("", "")
+		mOpen := item.AddSubMenuItem("Open", "Open the file")
+		mOpen.Click(func() {
+			if e, ok := recentDownload(i); ok {
+				openBrowser(e.Path)
+			}
+		})
+		mFolder := item.AddSubMenuItem("Show in folder", "Show the file in Explorer")
+		mFolder.Click(func() {
+			if e, ok := recentDownload(i); ok {
+				exec.Command("explorer", "/select,", e.Path).Start()
+			}
+		})
+		downloadsMenu = append(downloadsMenu, item)
+	}
+
+	mFolder := mDownloads.AddSubMenuItem("Open downloads folder", "Open the folder downloads are saved to")
+	mFolder.Click(func() {
+		os.MkdirAll(downloadDir, 0755)
+		exec.Command("explorer", downloadDir).Start()
+	})
+	updateDownloadsMenu()
+}
+
+// updateDownloadsMenu fills the tray slots with the recent downloads
+func updateDownloadsMenu() {
+	if downloadsEmpty == nil {
+		return
+	}
+	shown := 0
+	for i, item := range downloadsMenu {
+		e, ok := recentDownload(i)
+		if !ok {
+			item.Hide()
+			continue
+		}
+		item.SetTitle(filepath.Base(e.Path))
+		item.SetTooltip(e.Path)
+		item.Show()
+		shown++
+	}
+	if shown == 0 {
+		downloadsEmpty.Show()
+	} else {
+		downloadsEmpty.Hide()
+	}
+}
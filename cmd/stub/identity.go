@@ -0,0 +1,132 @@
+package main
+
+import (
+	"crypto/md5"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+
+	"github.com/user/w2app/internal/config"
+	"golang.org/x/sys/windows/registry"
+)
+
+var (
+	procFindWindowExW = user32.NewProc("FindWindowExW")
+	procSetPropW      = user32.NewProc("SetPropW")
+	procGetPropW      = user32.NewProc("GetPropW")
+	procRemovePropW   = user32.NewProc("RemovePropW")
+)
+
+var (
+	// appID identifies the app across launches and renames. Every resource
+	// that has to be unique per app (single instance mutex, window lookup,
+	// AUMID, temp files, auto-start entry) is derived from it.
+	appID string
+
+	// profile is the profile this instance runs, "" for the default profile
+	profile string
+)
+
+// resolveAppID returns the app ID from the config. Apps built before app IDs
+// existed fall back to their title, so an upgraded app keeps its auto-start
+// entry and notification identity.
+func resolveAppID(cfg *config.AppConfig) string {
+	if cfg.AppID != "" {
+		return cfg.AppID
+	}
+	return cfg.Title
+}
+
+// instanceKey returns the key for resources that belong to one profile of
+// the app: single instance mutex, window lookup and notification files. The
+// default profile uses the app ID itself, as apps did before profiles.
+// Profile names are case-insensitive like their data directories, so "Work"
+// and "work" share one instance.
+func instanceKey(id, profile string) string {
+	if profile == "" {
+		return id
+	}
+	return id + "#" + strings.ToLower(profile)
+}
+
+// mutexName returns the name of the single instance mutex
+func mutexName(id string) string {
+	return fmt.Sprintf("Global\\w2app_%x", md5.Sum([]byte(id)))
+}
+
+// tempFile returns a per-app file in the temp directory
+func tempFile(id, suffix string) string {
+	return filepath.Join(os.TempDir(), fmt.Sprintf("w2app-%s-%s", sanitizeFileName(id), suffix))
+}
+
+// windowPropName is the window property that marks the main window of the
+// app, so another instance can find it without relying on the title
+func windowPropName(id string) *uint16 {
+	name, _ := syscall.UTF16PtrFromString("w2app.window." + id)
+	return name
+}
+
+// markAppWindow tags the main window with the app ID and profile
+func markAppWindow(hwnd uintptr) {
+	procSetPropW.Call(hwnd, uintptr(unsafe.Pointer(windowPropName(instanceKey(appID, profile)))), 1)
+}
+
+// unmarkAppWindow removes the tag before the window is destroyed
+func unmarkAppWindow(hwnd uintptr) {
+	procRemovePropW.Call(hwnd, uintptr(unsafe.Pointer(windowPropName(instanceKey(appID, profile)))))
+}
+
+// findAppWindow returns the main window of the running instance of the app
+// for a profile. Apps without an app ID may still be running an older stub
+// that does not tag its window, so they fall back to looking it up by title.
+func findAppWindow(cfg *config.AppConfig, profile string) uintptr {
+	prop := windowPropName(instanceKey(resolveAppID(cfg), profile))
+	for hwnd := uintptr(0); ; {
+		hwnd, _, _ = procFindWindowExW.Call(0, hwnd, 0, 0)
+		if hwnd == 0 {
+			break
+		}
+		if tagged, _, _ := procGetPropW.Call(hwnd, uintptr(unsafe.Pointer(prop))); tagged != 0 {
+			return hwnd
+		}
+	}
+
+	if cfg.AppID != "" || cfg.Title == "" || profile != "" {
+		return 0
+	}
+	title, _ := syscall.UTF16PtrFromString(cfg.Title)
+	hwnd, _, _ := procFindWindowW.Call(0, uintptr(unsafe.Pointer(title)))
+	return hwnd
+}
+
+// migrateAutoStart renames an auto-start entry that was keyed on the title
+// (before the app had an app ID) to the app ID, as long as it starts this exe
+func migrateAutoStart(cfg *config.AppConfig) {
+	if cfg.AppID == "" || cfg.Title == "" {
+		return
+	}
+	key, err := registry.OpenKey(registry.CURRENT_USER, startupRegistryKey, registry.QUERY_VALUE|registry.SET_VALUE)
+	if err != nil {
+		return
+	}
+	defer key.Close()
+
+	value, _, err := key.GetStringValue(cfg.Title)
+	if err != nil {
+		return
+	}
+	exePath, err := os.Executable()
+	if err != nil || !strings.Contains(strings.ToLower(value), strings.ToLower(exePath)) {
+		return
+	}
+	if _, _, err := key.GetStringValue(appID); err != nil {
+		if err := key.SetStringValue(appID, value); err != nil {
+			debugLog("Auto-start migration: %v", err)
+			return
+		}
+	}
+	key.DeleteValue(cfg.Title)
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/user/w2app/internal/config"
+	"github.com/user/w2app/internal/ipc"
+)
+
+// ASFW_ANY lets any process set the foreground window
+const ASFW_ANY = ^uintptr(0)
+
+var procAllowSetForegroundWindow = user32.NewProc("AllowSetForegroundWindow")
+
+var (
+	ipcServer *ipc.Server
+
+	// pendingNotification is the last notification shown. A relaunch without
+	// arguments (Windows started the exe instead of the protocol URL when the
+	// toast was clicked) clicks it.
+	pendingNotification   string
+	pendingNotificationMu sync.Mutex
+)
+
+// startIPCServer listens for messages from later launches of this app and
+// profile
+func startIPCServer() {
+	s, err := ipc.Listen(instanceKey(appID, profile), handleIPCMessage)
+	if err != nil {
+		debugLog("IPC: %v", err)
+		return
+	}
+	ipcServer = s
+}
+
+func stopIPCServer() {
+	if ipcServer != nil {
+		ipcServer.Close()
+		ipcServer = nil
+	}
+}
+
+// handleIPCMessage handles a message from another launch. It is called from
+// an IPC goroutine, one per connection, so the work is done on the UI thread
+// through Dispatch.
+func handleIPCMessage(m ipc.Message) error {
+	debugLog("IPC message: %+v", m)
+	if mainWindow == nil {
+		return errors.New("window belum siap")
+	}
+
+	switch m.Type {
+	case ipc.TypeShow:
+		mainWindow.Dispatch(showMainWindow)
+	case ipc.TypeNotificationClick:
+		mainWindow.Dispatch(func() {
+			clickNotification(m.ID)
+		})
+	case ipc.TypeOpenURL:
+		mainWindow.Dispatch(func() {
+			showMainWindow()
+			openURL(m.URL)
+		})
+	case ipc.TypeArgv:
+		if target, ok := deepLinks.Args(m.Args); ok {
+			debugLog("Deep link: %s", target)
+			mainWindow.Dispatch(func() {
+				showMainWindow()
+				openURL(target)
+			})
+		} else if len(m.Args) == 0 && appConfig.EnableNotification {
+			if id := takePendingNotification(); id != "" {
+				mainWindow.Dispatch(func() {
+					clickNotification(id)
+				})
+			}
+		}
+	}
+	return nil
+}
+
+// clickNotification shows the window and runs the click handler of a
+// notification in the page. Must run on the UI thread.
+func clickNotification(id string) {
+	showMainWindow()
+	quoted, _ := json.Marshal(id)
+	js := fmt.Sprintf("if(window._w2appHandleNotificationClick) window._w2appHandleNotificationClick(%s);", quoted)
+	debugLog("Notification click: %s", js)
+	mainWindow.Eval(js)
+}
+
+func setPendingNotification(id string) {
+	pendingNotificationMu.Lock()
+	pendingNotification = id
+	pendingNotificationMu.Unlock()
+}
+
+func takePendingNotification() string {
+	pendingNotificationMu.Lock()
+	defer pendingNotificationMu.Unlock()
+	id := pendingNotification
+	pendingNotification = ""
+	return id
+}
+
+// forwardToInstance hands this launch over to the running instance of the
+// app: it shows the window, clicks the notification if notifId is set and
+// passes the command-line arguments on. It falls back to a window message if
+// the running instance does not answer.
+func forwardToInstance(cfg *config.AppConfig, notifId string) {
+	msgs := []ipc.Message{{Type: ipc.TypeShow}}
+	if notifId != "" {
+		msgs = append(msgs, ipc.Message{Type: ipc.TypeNotificationClick, ID: notifId})
+	}
+	msgs = append(msgs, ipc.Message{Type: ipc.TypeArgv, Args: os.Args[1:]})
+
+	// The running instance is in the background; this launch has the
+	// foreground and lets it bring its window to the front
+	procAllowSetForegroundWindow.Call(ASFW_ANY)
+
+	if err := ipc.Send(instanceKey(resolveAppID(cfg), profile), 0, msgs...); err != nil {
+		debugLog("IPC: %v, focusing the window instead", err)
+		focusExistingWindow(cfg)
+	}
+}
@@ -1,10 +1,7 @@
 package main
 
 import (
-	"bytes"
-	"crypto/md5"
 	"encoding/binary"
-	"encoding/json"
 	"fmt"
 	"net/url"
 	"os"
@@ -21,7 +18,15 @@ import (
 	"github.com/energye/systray"
 	"github.com/go-toast/toast"
 	"github.com/jchv/go-webview2"
+	"github.com/jchv/go-webview2/pkg/edge"
+	"github.com/user/w2app/internal/appdata"
 	"github.com/user/w2app/internal/config"
+	"github.com/user/w2app/internal/deeplink"
+	"github.com/user/w2app/internal/navpolicy"
+	"github.com/user/w2app/internal/netfilter"
+	"github.com/user/w2app/internal/permission"
+	"github.com/user/w2app/internal/userscript"
+	"github.com/user/w2app/internal/winframe"
 	"golang.org/x/sys/windows/registry"
 )
 
@@ -30,6 +35,10 @@ var (
 	procCreateMutex        = kernel32.NewProc("CreateMutexW")
 	procGetModuleHandle    = kernel32.NewProc("GetModuleHandleW")
 	procGetCurrentThreadId = kernel32.NewProc("GetCurrentThreadId")
+	procFindResourceW      = kernel32.NewProc("FindResourceW")
+	procLoadResource       = kernel32.NewProc("LoadResource")
+	procLockResource       = kernel32.NewProc("LockResource")
+	procSizeofResource     = kernel32.NewProc("SizeofResource")
 
 	user32                       = syscall.NewLazyDLL("user32.dll")
 	procSetForegroundWindow      = user32.NewProc("SetForegroundWindow")
@@ -58,6 +67,10 @@ var (
 	procAttachThreadInput        = user32.NewProc("AttachThreadInput")
 	procBringWindowToTop         = user32.NewProc("BringWindowToTop")
 	procSetFocus                 = user32.NewProc("SetFocus")
+	procReleaseCapture           = user32.NewProc("ReleaseCapture")
+	procGetCursorPos             = user32.NewProc("GetCursorPos")
+	procIsIconic                 = user32.NewProc("IsIconic")
+	procEnumDisplayMonitors      = user32.NewProc("EnumDisplayMonitors")
 
 	shell32                                     = syscall.NewLazyDLL("shell32.dll")
 	procExtractIconEx                           = shell32.NewProc("ExtractIconExW")
@@ -93,6 +106,7 @@ const (
 	IMAGE_ICON     = 1
 	LR_DEFAULTSIZE = 0x00000040
 	LR_SHARED      = 0x00008000
+	RT_RCDATA      = 10
 	SW_HIDE        = 0
 	SW_SHOW        = 5
 	SW_MAXIMIZE    = 3
@@ -101,11 +115,28 @@ const (
 	GWL_STYLE      = -16
 	GWL_EXSTYLE    = -20
 
+	// Messages used to remember the window geometry
+	WM_DESTROY     = 0x0002
+	WM_MOVE        = 0x0003
+	SIZE_RESTORED  = 0
+	SIZE_MINIMIZED = 1
+	SIZE_MAXIMIZED = 2
+
+	// Messages handled for frameless windows
+	WM_NCCALCSIZE    = 0x0083
+	WM_GETMINMAXINFO = 0x0024
+	WM_NCLBUTTONDOWN = 0x00A1
+
 	// SetWindowPos flags
 	SWP_FRAMECHANGED = 0x0020
 	SWP_NOMOVE       = 0x0002
 	SWP_NOSIZE       = 0x0001
 	SWP_NOZORDER     = 0x0004
+	SWP_NOACTIVATE   = 0x0010
+
+	// SetWindowPos insert-after handles
+	HWND_TOPMOST   = ^uintptr(0) // -1
+	HWND_NOTOPMOST = ^uintptr(1) // -2
 
 	// Window styles
 	WS_OVERLAPPEDWINDOW = 0x00CF0000
@@ -118,15 +149,19 @@ const (
 	WS_EX_APPWINDOW  = 0x00040000
 
 	// GetSystemMetrics
-	SM_CXSCREEN = 0
-	SM_CYSCREEN = 1
+	SM_CXFRAME = 32
+	SM_CYFRAME = 33
+
+	SM_CXPADDEDBORDER = 92
 
 	DWMWA_USE_IMMERSIVE_DARK_MODE = 20
 	DWMWA_CAPTION_COLOR           = 35
 	DWMWA_TEXT_COLOR              = 36
 
 	// Monitor info
+	MONITOR_DEFAULTTOPRIMARY = 0x00000001
 	MONITOR_DEFAULTTONEAREST = 0x00000002
+	MONITORINFOF_PRIMARY     = 0x00000001
 )
 
 // Global variables
@@ -143,6 +178,8 @@ var (
 	isFullscreenMode   bool   // Track if fullscreen mode is enabled
 	startedFromStartup bool   // Track if started from Windows startup
 	appUserModelID     string // AppUserModelID for toast notifications
+	navPolicy          *navpolicy.Policy
+	deepLinks          *deeplink.Mapper
 )
 
 func main() {
@@ -152,9 +189,19 @@ func main() {
 	// Check for special arguments
 	var showWindow bool
 	var notifId string
+	var uninstallApp bool
 
-	for _, arg := range os.Args[1:] {
+	args := os.Args[1:]
+	for i := 0; i < len(args); i++ {
+		arg := args[i]
 		switch {
+		case arg == "--profile" && i+1 < len(args):
+			i++
+			profile = args[i]
+			debugLog("Found --profile %s", profile)
+		case strings.HasPrefix(arg, "--profile="):
+			profile = strings.TrimPrefix(arg, "--profile=")
+			debugLog("Found --profile %s", profile)
 		case arg == "--show" || arg == "/show":
 			showWindow = true
 			debugLog("Found --show flag")
@@ -166,33 +213,27 @@ func main() {
 			notifId = strings.TrimPrefix(arg, "--notif-id=")
 			showWindow = true
 			debugLog("Found --notif-id=%s", notifId)
-		case strings.HasPrefix(arg, "w2app://"):
-			// Parse protocol URL: w2app://notification?id=123
-			debugLog("Found protocol URL: %s", arg)
-			if parsedURL, err := url.Parse(arg); err == nil {
-				if parsedURL.Host == "notification" {
-					notifId = parsedURL.Query().Get("id")
-					showWindow = true
-					debugLog("Parsed notification ID from protocol URL: %s", notifId)
-				}
-			} else {
-				debugLog("Failed to parse protocol URL: %v", err)
+		case arg == "--uninstall" || arg == "/uninstall":
+			uninstallApp = true
+			debugLog("Found --uninstall flag")
+		default:
+			// Protocol URL of a clicked toast: <app scheme>://notification?id=123
+			if parsedURL, ok := parseNotificationURL(arg); ok {
+				notifId = parsedURL.Query().Get("id")
+				profile = parsedURL.Query().Get("profile")
+				showWindow = true
+				debugLog("Parsed notification ID from protocol URL: %s", notifId)
 			}
 		}
 	}
 
-	// Handle show/notification click - focus existing window and optionally trigger callback
+	// Handle show/notification click - hand over to the running instance
 	if showWindow {
 		debugLog("showWindow=true, reading config...")
 		cfg, err := readEmbeddedConfig()
-		if err == nil && cfg.Title != "" {
-			debugLog("Focusing existing window: %s", cfg.Title)
-			focusExistingWindow(cfg.Title)
-			if notifId != "" {
-				// Send notification click to existing instance via window message
-				debugLog("Sending notification click for id=%s", notifId)
-				sendNotificationClick(cfg.Title, notifId)
-			}
+		if err == nil && resolveAppID(cfg) != "" {
+			debugLog("Forwarding to running instance: %s (notifId=%s)", resolveAppID(cfg), notifId)
+			forwardToInstance(cfg, notifId)
 		} else {
 			debugLog("Failed to read config or empty title: err=%v", err)
 		}
@@ -206,6 +247,23 @@ func main() {
 		return
 	}
 	appConfig = cfg
+	appID = resolveAppID(cfg)
+	alwaysOnTop = cfg.AlwaysOnTop
+
+	// Remove protocols, auto-start and shortcut, e.g. from an uninstaller
+	if uninstallApp {
+		if err := uninstall(cfg); err != nil {
+			showError("Gagal menghapus registrasi app: " + err.Error())
+		}
+		return
+	}
+
+	if profile != "" {
+		if err := appdata.CheckProfileName(profile); err != nil {
+			showError(err.Error())
+			return
+		}
+	}
 
 	// Validasi config
 	if cfg.URL == "" {
@@ -213,15 +271,35 @@ func main() {
 		return
 	}
 
+	navPolicy, err = navpolicy.New(cfg)
+	if err != nil {
+		showError("Aturan navigasi tidak valid: " + err.Error())
+		return
+	}
+
+	deepLinks, err = deeplink.New(cfg)
+	if err != nil {
+		showError("Deep link tidak valid: " + err.Error())
+		return
+	}
+
+	permissionPolicy, err = permission.New(cfg.Permissions)
+	if err != nil {
+		showError("Izin tidak valid: " + err.Error())
+		return
+	}
+
+	netFilter, err = netfilter.New(cfg.Network)
+	if err != nil {
+		showError("Aturan network tidak valid: " + err.Error())
+		return
+	}
+
 	// Single instance check
 	if cfg.SingleInstance {
-		if !acquireLock(cfg.Title) {
-			debugLog("Another instance running, focusing existing window")
-			focusExistingWindow(cfg.Title)
-			// Check if there's a pending notification click (from toast activation)
-			if cfg.EnableNotification {
-				handlePendingNotificationClick(cfg.Title)
-			}
+		if !acquireLock(instanceKey(appID, profile)) {
+			debugLog("Another instance running, forwarding to it")
+			forwardToInstance(cfg, "")
 			return
 		}
 	}
@@ -238,18 +316,37 @@ func main() {
 	}
 
 	appTitle = cfg.Title
+	if appID == "" {
+		appID = cfg.Title
+	}
+	migrateAutoStart(cfg)
+
+	// Per-app data directory for the WebView2 profile and window state
+	dataRoot, err = resolveDataDir(cfg)
+	if err == nil {
+		dataDir = appdata.ProfileDir(dataRoot, profile)
+		err = os.MkdirAll(dataDir, 0755)
+	}
+	if err != nil {
+		showError("Direktori data tidak valid: " + err.Error())
+		return
+	}
+
+	// Folder for downloads and the downloads listed in the tray
+	downloadDir, err = resolveDownloadDir(cfg)
+	if err != nil {
+		showError("Folder download tidak valid: " + err.Error())
+		return
+	}
+	loadRecentDownloads()
+	loadPermissionAnswers()
 
 	// Setup AppUserModelID for toast notifications (must be done early)
-	appUserModelID = generateAppUserModelID(cfg.Title)
+	appUserModelID = generateAppUserModelID(cfg)
 	setProcessAppUserModelID(appUserModelID)
 
-	// Register custom protocol for toast activation
-	if cfg.EnableNotification {
-		exePath, _ := os.Executable()
-		if err := registerProtocol("w2app", exePath); err != nil {
-			debugLog("Warning: failed to register protocol: %v", err)
-		}
-	}
+	// Register the app scheme for toast activation and the claimed protocols
+	registerProtocols(cfg)
 
 	// Create Start Menu shortcut with AppUserModelID (required for toast notifications)
 	if cfg.EnableNotification {
@@ -279,8 +376,8 @@ func onTrayReady() {
 	// Set tray icon
 	iconData := loadTrayIcon()
 	systray.SetIcon(iconData)
-	systray.SetTitle(appTitle)
-	systray.SetTooltip(appTitle)
+	systray.SetTitle(windowTitle())
+	systray.SetTooltip(windowTitle())
 
 	// Double-click on tray icon to show window
 	systray.SetOnDClick(func(menu systray.IMenu) {
@@ -297,6 +394,28 @@ func onTrayReady() {
 	mHide := systray.AddMenuItem("Hide", "Hide window")
 	systray.AddSeparator()
 
+	mOnTop := systray.AddMenuItemCheckbox("Always on top", "Keep the window above other windows", alwaysOnTop)
+	mOnTop.Click(func() {
+		if mOnTop.Checked() {
+			setAlwaysOnTop(false)
+			mOnTop.Uncheck()
+		} else {
+			setAlwaysOnTop(true)
+			mOnTop.Check()
+		}
+	})
+	systray.AddSeparator()
+
+	// Recent downloads
+	addDownloadsMenu()
+	systray.AddSeparator()
+
+	// Profile switcher
+	if profilesEnabled(appConfig) {
+		addProfileMenu()
+		systray.AddSeparator()
+	}
+
 	// Add auto-startup checkbox if enabled in config
 	var mAutoStart *systray.MenuItem
 	if appConfig.EnableAutoStart {
@@ -342,12 +461,18 @@ func runWebView() {
 	// Determine if should start hidden (for tray apps starting minimized)
 	shouldStartHidden := cfg.EnableTray && (cfg.StartMinimized || startedFromStartup)
 
+	// Data that could not be cleared on the last exit, before WebView2 opens it
+	if err := appdata.ClearPending(dataDir); err != nil {
+		debugLog("Clear pending data: %v", err)
+	}
+
 	// Buat webview
 	w := webview2.NewWithOptions(webview2.WebViewOptions{
 		Debug:     !cfg.DisableDevTools,
+		DataPath:  dataDir,
 		AutoFocus: true,
 		WindowOptions: webview2.WindowOptions{
-			Title:  cfg.Title,
+			Title:  windowTitle(),
 			Width:  uint(cfg.Width),
 			Height: uint(cfg.Height),
 			Center: !shouldStartHidden, // Don't center if hidden (will be off-screen)
@@ -364,6 +489,7 @@ func runWebView() {
 
 	mainWindow = w
 	mainHwnd = uintptr(w.Window())
+	markAppWindow(mainHwnd)
 
 	// If started hidden, hide the window now (it was shown off-screen for proper embedding)
 	if shouldStartHidden {
@@ -379,24 +505,53 @@ func runWebView() {
 		setTitleBarColor(mainHwnd, cfg.TitleBarColor)
 	}
 
-	// Subclass window if we need to intercept messages:
+	// Put the window back where it was last time, unless the config asks
+	// for a monitor or position
+	savedState := restoreWindowState(mainHwnd)
+	if hasPlacement(cfg) && !shouldStartHidden {
+		placeWindow(mainHwnd)
+	}
+
+	// Subclass window to intercept messages:
 	// - Close to tray
 	// - Minimize to tray
 	// - Single instance (to handle WM_APP_SHOW from other instances)
-	if (cfg.EnableTray && (cfg.CloseToTray || cfg.MinimizeToTray)) || cfg.SingleInstance {
-		subclassWindow(mainHwnd)
-	}
-
-	// Apply window state: fullscreen or maximized (only if not starting minimized)
+	// - Frameless (to hide the frame)
+	// - Window geometry (saved when the window is destroyed)
+	subclassWindow(mainHwnd)
+
+	// Frame, resize border and always-on-top. Done after subclassing so the
+	// frame change already goes through WM_NCCALCSIZE.
+	applyWindowOptions(mainHwnd, cfg)
+	rememberWindowGeometry(mainHwnd)
+
+	// Apply window state: fullscreen or maximized (only if not starting minimized).
+	// A saved maximized state wins over the config.
+	maximized := cfg.StartMaximized
+	if savedState != nil {
+		maximized = savedState.Maximized
+	}
+	isFullscreenMode = wantsFullscreen(cfg)
 	if !cfg.StartMinimized && !startedFromStartup {
-		if cfg.Fullscreen {
-			isFullscreenMode = true
+		if isFullscreenMode {
 			setFullscreen(mainHwnd, true)
-		} else if cfg.StartMaximized {
+		} else if maximized {
 			procShowWindow.Call(mainHwnd, SW_MAXIMIZE)
 		}
 	}
 
+	// Enforce navigation policy on every top-level navigation
+	// (links, redirects, form posts, location.href changes) and decide where
+	// new windows (window.open, target=_blank) open, answer permission
+	// requests from the permissions config, and filter requests
+	if chromium := chromiumOf(w); chromium != nil {
+		chromium.NavigationStartingCallback = onNavigationStarting
+		chromium.NewWindowRequestedCallback = onNewWindowRequested
+		chromium.PermissionRequestedCallback = onPermissionRequested
+		watchDownloads(chromium)
+		watchNetwork(chromium)
+	}
+
 	// Bind functions
 	w.Bind("openExternal", func(url string) {
 		openBrowser(url)
@@ -404,6 +559,23 @@ func runWebView() {
 
 	w.Bind("toggleFullscreen", func() {})
 
+	// Frameless windows are moved and resized from the page
+	if cfg.Frameless {
+		w.Bind("w2appStartDrag", func() {
+			startWindowMove(winframe.HitCaption)
+		})
+		w.Bind("w2appStartResize", func(x, y, width, height float64) {
+			if cfg.Resizable {
+				startResize(x, y, width, height)
+			}
+		})
+		w.Bind("w2appToggleMaximize", func() {
+			if cfg.Resizable {
+				toggleMaximize()
+			}
+		})
+	}
+
 	// Bind notification functions
 	if cfg.EnableNotification {
 		// Function to show native toast with app icon
@@ -419,35 +591,86 @@ func runWebView() {
 		})
 	}
 
+	// Exceptions from inject_js and userscripts go to the app log
+	w.Bind(userscript.ErrorFunc, func(name, message string) {
+		debugLog("Script %s: %s", name, message)
+	})
+
 	// Build init script (notification script is included if enabled)
 	initScript := buildInitScript(cfg)
 	w.Init(initScript)
 
-	// Start notification click checker
-	if cfg.EnableNotification {
-		go func() {
-			ticker := time.NewTicker(500 * time.Millisecond)
-			defer ticker.Stop()
-			for {
-				select {
-				case <-ticker.C:
-					if shouldReallyQuit {
-						return
-					}
-					checkNotificationClick()
-				}
-			}
-		}()
+	// Each userscript is a separate init script, so a syntax error only
+	// stops that script
+	for _, s := range cfg.UserScripts {
+		js, err := userscript.Script(s)
+		if err != nil {
+			debugLog("Userscript %s: %v", s.Name, err)
+			continue
+		}
+		w.Init(js)
 	}
 
+	// Messages from later launches: show, notification clicks, URLs
+	startIPCServer()
+
 	// Navigate
-	w.Navigate(cfg.URL)
+	w.Navigate(startURL(cfg))
 
 	w.Run()
+	stopIPCServer()
+	closePopups()
 
 	// After webview closes
-	if cfg.ClearCacheOnExit {
-		clearWebViewCache()
+	clearAppData(w, cfg)
+}
+
+// chromiumOf returns the Chromium browser behind a webview, or nil
+func chromiumOf(w webview2.WebView) *edge.Chromium {
+	if p, ok := w.(interface{ Chromium() *edge.Chromium }); ok {
+		return p.Chromium()
+	}
+	return nil
+}
+
+// onNavigationStarting applies the navigation policy before WebView2 loads a URL
+func onNavigationStarting(sender *edge.ICoreWebView2, args *edge.ICoreWebView2NavigationStartingEventArgs) {
+	uri, err := args.GetUri()
+	if err != nil {
+		return
+	}
+	userInitiated, _ := args.GetIsUserInitiated()
+	redirect, _ := args.GetIsRedirected()
+	from, _ := sender.GetSource()
+
+	action := navPolicy.Decide(navpolicy.Request{
+		URL:           uri,
+		From:          from,
+		UserInitiated: userInitiated,
+		Redirect:      redirect,
+	})
+
+	switch action {
+	case navpolicy.External:
+		debugLog("Navigation to %s opened in browser", uri)
+		args.PutCancel(true)
+		go openBrowser(uri)
+	case navpolicy.Block:
+		debugLog("Navigation to %s blocked", uri)
+		args.PutCancel(true)
+	}
+}
+
+// openURL opens a URL handed to the app from outside the page (deep links,
+// later launches) in the main window
+func openURL(target string) {
+	switch navPolicy.Decide(navpolicy.Request{URL: target, UserInitiated: true}) {
+	case navpolicy.Allow:
+		mainWindow.Navigate(target)
+	case navpolicy.External:
+		openBrowser(target)
+	default:
+		debugLog("Opening %s blocked", target)
 	}
 }
 
@@ -467,21 +690,35 @@ func customWndProc(hwnd, msg, wParam, lParam uintptr) uintptr {
 		cmd := wParam & 0xFFF0
 
 		// Intercept minimize
-		if cmd == SC_MINIMIZE && appConfig.MinimizeToTray {
+		if cmd == SC_MINIMIZE && appConfig.EnableTray && appConfig.MinimizeToTray {
 			hideMainWindow()
 			return 0
 		}
 		// Intercept close
-		if cmd == SC_CLOSE && appConfig.CloseToTray && !shouldReallyQuit {
+		if cmd == SC_CLOSE && appConfig.EnableTray && appConfig.CloseToTray && !shouldReallyQuit {
 			hideMainWindow()
 			return 0
 		}
 	}
-	if msg == WM_CLOSE && appConfig.CloseToTray && !shouldReallyQuit {
+	if msg == WM_CLOSE && appConfig.EnableTray && appConfig.CloseToTray && !shouldReallyQuit {
 		hideMainWindow()
 		return 0
 	}
 
+	if appConfig.Frameless && !isFullscreenMode {
+		if ret, handled := framelessWndProc(hwnd, msg, wParam, lParam); handled {
+			return ret
+		}
+	}
+
+	switch msg {
+	case WM_MOVE, WM_SIZE:
+		trackWindowGeometry(hwnd, msg, wParam)
+	case WM_DESTROY:
+		saveWindowState(hwnd)
+		unmarkAppWindow(hwnd)
+	}
+
 	// Call original window procedure
 	ret, _, _ := procCallWindowProcW.Call(originalWndProc, hwnd, msg, wParam, lParam)
 	return ret
@@ -518,10 +755,12 @@ func showMainWindow() {
 		// Determine if we should maximize
 		shouldMaximize := isFullscreenMode || wasMaximized || appConfig.StartMaximized
 
-		// Only center window if NOT going to maximize/fullscreen
-		// Maximize will handle positioning itself
-		if !shouldMaximize {
-			centerWindow(mainHwnd)
+		// Move the window to the configured monitor and position first, so
+		// maximize happens on that monitor. Otherwise only center it if it has
+		// no position yet (started hidden without a saved state) and is not
+		// going to maximize/fullscreen, which handles positioning itself.
+		if hasPlacement(appConfig) || (!shouldMaximize && !geometryKnown) {
+			placeWindow(mainHwnd)
 		}
 
 		// Show window
@@ -536,6 +775,7 @@ func showMainWindow() {
 			procShowWindow.Call(mainHwnd, SW_RESTORE)
 		}
 		isWindowHidden = false
+		rememberWindowGeometry(mainHwnd)
 	} else {
 		// Window is visible, just restore if minimized and bring to front
 		// Check if currently maximized to preserve state
@@ -551,25 +791,6 @@ func showMainWindow() {
 	bringWindowToFront(mainHwnd)
 }
 
-// centerWindow moves a window to the center of the screen
-func centerWindow(hwnd uintptr) {
-	var rect struct {
-		Left, Top, Right, Bottom int32
-	}
-	procGetWindowRect.Call(hwnd, uintptr(unsafe.Pointer(&rect)))
-
-	windowWidth := rect.Right - rect.Left
-	windowHeight := rect.Bottom - rect.Top
-
-	screenWidth, _, _ := procGetSystemMetrics.Call(SM_CXSCREEN)
-	screenHeight, _, _ := procGetSystemMetrics.Call(SM_CYSCREEN)
-
-	posX := (int32(screenWidth) - windowWidth) / 2
-	posY := (int32(screenHeight) - windowHeight) / 2
-
-	procSetWindowPos.Call(hwnd, 0, uintptr(posX), uintptr(posY), 0, 0, SWP_NOSIZE|SWP_NOZORDER)
-}
-
 // bringWindowToFront forces window to foreground using multiple techniques
 func bringWindowToFront(hwnd uintptr) {
 	if hwnd == 0 {
@@ -615,6 +836,9 @@ func hideMainWindow() {
 		}
 	}
 
+	// The app may be ended while it sits in the tray
+	saveWindowState(mainHwnd)
+
 	procShowWindow.Call(mainHwnd, SW_HIDE)
 	isWindowHidden = true
 }
@@ -624,6 +848,7 @@ func quitApp() {
 	if mainWindow != nil {
 		// Use Dispatch to safely terminate from another goroutine
 		mainWindow.Dispatch(func() {
+			saveWindowState(mainHwnd)
 			mainWindow.Terminate()
 		})
 	} else {
@@ -697,11 +922,7 @@ type MONITORINFO struct {
 // setFullscreen applies fullscreen mode to the window
 func setFullscreen(hwnd uintptr, fullscreen bool) {
 	if fullscreen {
-		// Get monitor info
-		hMonitor, _, _ := procMonitorFromWindow.Call(hwnd, MONITOR_DEFAULTTONEAREST)
-		var mi MONITORINFO
-		mi.CbSize = uint32(unsafe.Sizeof(mi))
-		procGetMonitorInfoW.Call(hMonitor, uintptr(unsafe.Pointer(&mi)))
+		m := fullscreenMonitor(hwnd)
 
 		// GWL_STYLE = -16 as unsigned 64-bit
 		gwlStyle := uintptr(0xFFFFFFFFFFFFFFF0)
@@ -709,10 +930,10 @@ func setFullscreen(hwnd uintptr, fullscreen bool) {
 		// Remove window decorations and set fullscreen
 		procSetWindowLongPtrW.Call(hwnd, gwlStyle, uintptr(WS_POPUP|WS_VISIBLE))
 		procSetWindowPos.Call(hwnd, 0,
-			uintptr(mi.RcMonitor.Left),
-			uintptr(mi.RcMonitor.Top),
-			uintptr(mi.RcMonitor.Right-mi.RcMonitor.Left),
-			uintptr(mi.RcMonitor.Bottom-mi.RcMonitor.Top),
+			uintptr(m.Bounds.Left),
+			uintptr(m.Bounds.Top),
+			uintptr(m.Bounds.Width()),
+			uintptr(m.Bounds.Height()),
 			0x0040) // SWP_SHOWWINDOW
 	}
 }
@@ -999,13 +1220,16 @@ func showNativeNotification(title, body, iconURL, notifId, tag string) {
 
 	// Ensure AUMID is set
 	if appUserModelID == "" {
-		appUserModelID = generateAppUserModelID(appTitle)
+		appUserModelID = generateAppUserModelID(appConfig)
 		debugLog("Generated AUMID: %s", appUserModelID)
 	}
 
 	// Build activation URL using custom protocol
-	// When user clicks toast, Windows will launch: w2app://notification?id=123
-	activationArgs := fmt.Sprintf("w2app://notification?id=%s", notifId)
+	// When user clicks toast, Windows will launch: <app scheme>://notification?id=123
+	activationArgs := fmt.Sprintf("%s://notification?id=%s", appScheme(), url.QueryEscape(notifId))
+	if profile != "" {
+		activationArgs += "&profile=" + url.QueryEscape(profile)
+	}
 	debugLog("ActivationArguments: %s", activationArgs)
 
 	notification := toast.Notification{
@@ -1027,8 +1251,9 @@ func showNativeNotification(title, body, iconURL, notifId, tag string) {
 
 	debugLog("Pushing notification with AppID=%s, Icon=%s, ActivationType=foreground, ActivationArgs=%s", appUserModelID, notificationIconPath, activationArgs)
 
-	// Save pending notification - this will be read when toast is clicked and app relaunches
-	savePendingNotification(appTitle, notifId)
+	// Remember the notification in case the toast relaunches the exe without
+	// the protocol URL
+	setPendingNotification(notifId)
 
 	if err := notification.Push(); err != nil {
 		debugLog("Toast error: %v", err)
@@ -1045,8 +1270,7 @@ func extractNotificationIcon() string {
 	}
 
 	// Create temp file for icon
-	tmpDir := os.TempDir()
-	iconPath := filepath.Join(tmpDir, fmt.Sprintf("w2app-%s-icon.png", sanitizeFileName(appTitle)))
+	iconPath := tempFile(appID, "icon.png")
 
 	// Check if already exists
 	if _, err := os.Stat(iconPath); err == nil {
@@ -1060,7 +1284,7 @@ func extractNotificationIcon() string {
 	}
 
 	// Write ICO data to temp file (toast supports ICO)
-	icoPath := filepath.Join(tmpDir, fmt.Sprintf("w2app-%s-icon.ico", sanitizeFileName(appTitle)))
+	icoPath := tempFile(appID, "icon.ico")
 	if err := os.WriteFile(icoPath, iconData, 0644); err != nil {
 		return ""
 	}
@@ -1092,25 +1316,9 @@ func debugLog(format string, args ...interface{}) {
 func buildInitScript(cfg *config.AppConfig) string {
 	var scripts []string
 
-	// External link handler
-	scripts = append(scripts, `
-		document.addEventListener('click', function(e) {
-			var target = e.target;
-			while (target && target.tagName !== 'A') {
-				target = target.parentElement;
-			}
-			if (target && target.href) {
-				try {
-					var url = new URL(target.href);
-					var currentHost = window.location.hostname;
-					if (url.hostname !== currentHost && url.hostname !== '' && url.protocol.startsWith('http')) {
-						e.preventDefault();
-						openExternal(target.href);
-					}
-				} catch(e) {}
-			}
-		}, true);
-	`)
+	if cfg.Frameless {
+		scripts = append(scripts, getFramelessScript(cfg.Resizable))
+	}
 
 	if cfg.DisableContextMenu {
 		scripts = append(scripts, `
@@ -1245,9 +1453,11 @@ func buildInitScript(cfg *config.AppConfig) string {
 	if cfg.InjectJS != "" {
 		scripts = append(scripts, fmt.Sprintf(`
 			(function() {
-				try { %s } catch(e) {}
+				try { %s } catch(e) {
+					if (typeof window.%s === 'function') window.%s('inject_js', String(e && e.stack || e));
+				}
 			})();
-		`, cfg.InjectJS))
+		`, cfg.InjectJS, userscript.ErrorFunc, userscript.ErrorFunc))
 	}
 
 	if cfg.UserAgent != "" {
@@ -1288,7 +1498,7 @@ func buildInitScript(cfg *config.AppConfig) string {
 		});
 	`)
 
-	if cfg.Fullscreen {
+	if wantsFullscreen(cfg) {
 		scripts = append(scripts, `
 			document.addEventListener('DOMContentLoaded', function() {
 				setTimeout(function() {
@@ -1436,40 +1646,64 @@ func escapeJS(s string) string {
 }
 
 func readEmbeddedConfig() (*config.AppConfig, error) {
+	// Fast path: the loader has already mapped our resources
+	if data := loadConfigResource(); data != nil {
+		return config.Decode(data)
+	}
+
+	// Older apps keep the config after the PE image
 	exePath, err := os.Executable()
 	if err != nil {
 		return nil, fmt.Errorf("gagal mendapatkan path executable: %w", err)
 	}
 
-	data, err := os.ReadFile(exePath)
+	emb, err := config.ReadEmbedded(exePath)
 	if err != nil {
-		return nil, fmt.Errorf("gagal membaca executable: %w", err)
+		return nil, err
 	}
 
-	marker := []byte(config.ConfigMarker)
-	idx := bytes.LastIndex(data, marker)
-	if idx == -1 {
-		return nil, fmt.Errorf("config marker tidak ditemukan")
+	return emb.Config, nil
+}
+
+// loadConfigResource returns the config JSON stored as an RCDATA resource, or nil
+func loadConfigResource() []byte {
+	if runtime.GOOS != "windows" {
+		return nil
 	}
 
-	configData := data[idx+len(marker):]
-	configData = bytes.TrimRight(configData, "\x00 \n\r\t")
+	hModule, _, _ := procGetModuleHandle.Call(0)
+	if hModule == 0 {
+		return nil
+	}
 
-	var cfg config.AppConfig
-	if err := json.Unmarshal(configData, &cfg); err != nil {
-		return nil, fmt.Errorf("gagal parse config JSON: %w", err)
+	namePtr, _ := syscall.UTF16PtrFromString(config.ResourceName)
+	hRes, _, _ := procFindResourceW.Call(hModule, uintptr(unsafe.Pointer(namePtr)), RT_RCDATA)
+	if hRes == 0 {
+		return nil
+	}
+
+	size, _, _ := procSizeofResource.Call(hModule, hRes)
+	hData, _, _ := procLoadResource.Call(hModule, hRes)
+	if size == 0 || hData == 0 {
+		return nil
+	}
+
+	ptr, _, _ := procLockResource.Call(hData)
+	if ptr == 0 {
+		return nil
 	}
 
-	return &cfg, nil
+	// Resource memory belongs to the module, copy it out
+	data := unsafe.Slice((*byte)(unsafe.Pointer(ptr)), size)
+	return append([]byte(nil), data...)
 }
 
-func acquireLock(name string) bool {
+func acquireLock(id string) bool {
 	if runtime.GOOS != "windows" {
 		return true
 	}
 
-	mutexName := fmt.Sprintf("Global\\w2app_%x", md5.Sum([]byte(name)))
-	mutexNamePtr, _ := syscall.UTF16PtrFromString(mutexName)
+	mutexNamePtr, _ := syscall.UTF16PtrFromString(mutexName(id))
 
 	ret, _, err := procCreateMutex.Call(0, 1, uintptr(unsafe.Pointer(mutexNamePtr)))
 	if ret == 0 {
@@ -1483,14 +1717,12 @@ func acquireLock(name string) bool {
 	return true
 }
 
-func focusExistingWindow(title string) {
+func focusExistingWindow(cfg *config.AppConfig) {
 	if runtime.GOOS != "windows" {
 		return
 	}
 
-	titlePtr, _ := syscall.UTF16PtrFromString(title)
-	hwnd, _, _ := procFindWindowW.Call(0, uintptr(unsafe.Pointer(titlePtr)))
-	if hwnd != 0 {
+	if hwnd := findAppWindow(cfg, profile); hwnd != 0 {
 		// Send custom message to the existing window to show itself
 		// This allows the main instance to handle showing with correct state (maximized/fullscreen)
 		procSendMessageW.Call(hwnd, WM_APP_SHOW, 0, 0)
@@ -1515,21 +1747,6 @@ func openBrowser(url string) {
 	cmd.Start()
 }
 
-func clearWebViewCache() {
-	localAppData := os.Getenv("LOCALAPPDATA")
-	if localAppData == "" {
-		return
-	}
-
-	cachePaths := []string{
-		filepath.Join(localAppData, "Microsoft", "Edge", "User Data", "Default", "Cache"),
-	}
-
-	for _, p := range cachePaths {
-		os.RemoveAll(p)
-	}
-}
-
 func showError(msg string) {
 	if runtime.GOOS == "windows" {
 		script := fmt.Sprintf(`Add-Type -AssemblyName System.Windows.Forms; [System.Windows.Forms.MessageBox]::Show('%s', 'Error', 'OK', 'Error')`, strings.ReplaceAll(msg, "'", "''"))
@@ -1621,85 +1838,6 @@ func setTitleBarColor(hwnd uintptr, colorStr string) {
 	}
 }
 
-// sendNotificationClick writes notification ID to a temp file for the main instance to read
-func sendNotificationClick(appName, notifId string) {
-	notifFile := filepath.Join(os.TempDir(), fmt.Sprintf("w2app-%s-notif.txt", sanitizeFileName(appName)))
-	debugLog("sendNotificationClick: writing notifId=%s to %s", notifId, notifFile)
-	os.WriteFile(notifFile, []byte(notifId), 0644)
-}
-
-// savePendingNotification saves the notification ID when a toast is shown
-// This will be read when the toast is clicked and app is relaunched
-func savePendingNotification(appName, notifId string) {
-	pendingFile := filepath.Join(os.TempDir(), fmt.Sprintf("w2app-%s-pending.txt", sanitizeFileName(appName)))
-	debugLog("savePendingNotification: saving notifId=%s to %s", notifId, pendingFile)
-	os.WriteFile(pendingFile, []byte(notifId), 0644)
-}
-
-// handlePendingNotificationClick checks for pending notification and sends click to main instance
-func handlePendingNotificationClick(appName string) {
-	pendingFile := filepath.Join(os.TempDir(), fmt.Sprintf("w2app-%s-pending.txt", sanitizeFileName(appName)))
-	data, err := os.ReadFile(pendingFile)
-	if err != nil {
-		debugLog("handlePendingNotificationClick: no pending file found")
-		return
-	}
-
-	// Remove the pending file
-	os.Remove(pendingFile)
-
-	notifId := strings.TrimSpace(string(data))
-	if notifId == "" {
-		debugLog("handlePendingNotificationClick: pending file empty")
-		return
-	}
-
-	debugLog("handlePendingNotificationClick: found pending notifId=%s, sending click", notifId)
-	sendNotificationClick(appName, notifId)
-}
-
-// checkNotificationClick checks if there's a notification click signal from a relaunched instance
-// The flow is:
-// 1. User clicks toast -> Windows relaunches app (or tries to)
-// 2. Second instance detects main instance running, reads pending file, writes to notif file
-// 3. Main instance polls notif file here and handles the click
-// IMPORTANT: We ONLY process the notif file (written by 2nd instance), NOT the pending file directly
-// The pending file is just storage for the notifId - we don't auto-show when it's created
-func checkNotificationClick() {
-	if mainWindow == nil {
-		return
-	}
-
-	// Only check the notif file (signal from relaunched instance that toast was clicked)
-	notifFile := filepath.Join(os.TempDir(), fmt.Sprintf("w2app-%s-notif.txt", sanitizeFileName(appTitle)))
-	data, err := os.ReadFile(notifFile)
-	if err != nil {
-		// No notif file means no toast was clicked
-		return
-	}
-
-	// Remove the file immediately to prevent re-processing
-	os.Remove(notifFile)
-
-	notifId := strings.TrimSpace(string(data))
-	if notifId == "" {
-		debugLog("checkNotificationClick: notif file was empty")
-		return
-	}
-
-	debugLog("checkNotificationClick: found notif file, notifId=%s", notifId)
-
-	// Show window first (toast was clicked, so user wants to see the app)
-	showMainWindow()
-
-	// Execute JavaScript to handle notification click
-	mainWindow.Dispatch(func() {
-		js := fmt.Sprintf("if(window._w2appHandleNotificationClick) window._w2appHandleNotificationClick(%s);", notifId)
-		debugLog("checkNotificationClick: executing JS: %s", js)
-		mainWindow.Eval(js)
-	})
-}
-
 // isWindowVisible checks if the main window is currently visible
 func isWindowVisible() bool {
 	if mainHwnd == 0 {
@@ -1724,7 +1862,7 @@ func isAutoStartEnabled() bool {
 	}
 	defer key.Close()
 
-	_, _, err = key.GetStringValue(appTitle)
+	_, _, err = key.GetStringValue(appID)
 	return err == nil
 }
 
@@ -1747,9 +1885,9 @@ func setAutoStart(enable bool) error {
 		}
 		// Add quotes around path and --startup flag to start minimized to tray
 		value := fmt.Sprintf(`"%s" --startup`, exePath)
-		return key.SetStringValue(appTitle, value)
+		return key.SetStringValue(appID, value)
 	} else {
-		return key.DeleteValue(appTitle)
+		return key.DeleteValue(appID)
 	}
 }
 
@@ -1768,11 +1906,16 @@ type propertyKey struct {
 	pid   uint32
 }
 
-// generateAppUserModelID creates a unique AUMID for the app
-func generateAppUserModelID(title string) string {
+// generateAppUserModelID creates a unique AUMID for the app. The app ID is
+// already a valid AUMID; apps without one use their title.
+func generateAppUserModelID(cfg *config.AppConfig) string {
+	if cfg.AppID != "" {
+		return cfg.AppID
+	}
+
 	// Create a valid AUMID: CompanyName.AppName
 	// Use sanitized title with "W2App" prefix
-	sanitized := sanitizeFileName(title)
+	sanitized := sanitizeFileName(cfg.Title)
 	sanitized = strings.ReplaceAll(sanitized, " ", "")
 	return fmt.Sprintf("W2App.%s", sanitized)
 }
@@ -1830,6 +1973,27 @@ func registerProtocol(protocol, exePath string) error {
 	return nil
 }
 
+// startMenuShortcutPath returns the path of the Start Menu shortcut of an app
+func startMenuShortcutPath(title string) (string, error) {
+	// Get Start Menu Programs folder path
+	var pathPtr *uint16
+	ret, _, _ := procSHGetKnownFolderPath.Call(
+		uintptr(unsafe.Pointer(&FOLDERID_Programs)),
+		0,
+		0,
+		uintptr(unsafe.Pointer(&pathPtr)),
+	)
+	if ret != 0 {
+		return "", fmt.Errorf("failed to get Start Menu path: %x", ret)
+	}
+	programsPath := syscall.UTF16ToString((*[260]uint16)(unsafe.Pointer(pathPtr))[:])
+
+	// Free the path memory
+	syscall.NewLazyDLL("ole32.dll").NewProc("CoTaskMemFree").Call(uintptr(unsafe.Pointer(pathPtr)))
+
+	return filepath.Join(programsPath, sanitizeFileName(title)+".lnk"), nil
+}
+
 // ensureStartMenuShortcut creates Start Menu shortcut with AppUserModelID for toast notifications
 func ensureStartMenuShortcut() error {
 	if runtime.GOOS != "windows" {
@@ -1845,25 +2009,11 @@ func ensureStartMenuShortcut() error {
 	}
 	debugLog("ensureStartMenuShortcut: exePath=%s", exePath)
 
-	// Get Start Menu Programs folder path
-	var pathPtr *uint16
-	ret, _, lastErr := procSHGetKnownFolderPath.Call(
-		uintptr(unsafe.Pointer(&FOLDERID_Programs)),
-		0,
-		0,
-		uintptr(unsafe.Pointer(&pathPtr)),
-	)
-	if ret != 0 {
-		debugLog("ensureStartMenuShortcut: SHGetKnownFolderPath failed: ret=%x, err=%v", ret, lastErr)
-		return fmt.Errorf("failed to get Start Menu path: %x", ret)
+	shortcutPath, err := startMenuShortcutPath(appTitle)
+	if err != nil {
+		debugLog("ensureStartMenuShortcut: %v", err)
+		return err
 	}
-	programsPath := syscall.UTF16ToString((*[260]uint16)(unsafe.Pointer(pathPtr))[:])
-	debugLog("ensureStartMenuShortcut: programsPath=%s", programsPath)
-
-	// Free the path memory
-	syscall.NewLazyDLL("ole32.dll").NewProc("CoTaskMemFree").Call(uintptr(unsafe.Pointer(pathPtr)))
-
-	shortcutPath := filepath.Join(programsPath, sanitizeFileName(appTitle)+".lnk")
 	debugLog("ensureStartMenuShortcut: shortcutPath=%s", shortcutPath)
 
 	// Check if shortcut exists and is recent (within 7 days)
@@ -1879,7 +2029,7 @@ func ensureStartMenuShortcut() error {
 	}
 
 	// Initialize COM
-	ret, _, lastErr = procCoInitializeEx.Call(0, 0)
+	ret, _, lastErr := procCoInitializeEx.Call(0, 0)
 	debugLog("ensureStartMenuShortcut: CoInitializeEx ret=%x, err=%v", ret, lastErr)
 	defer procCoUninitialize.Call()
 
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"github.com/jchv/go-webview2/pkg/edge"
+	"github.com/user/w2app/internal/netfilter"
+)
+
+var netFilter *netfilter.Filter
+
+// resourceTypes maps the WebView2 resource contexts to the resource types of
+// filter lists. Frames are reported as documents.
+var resourceTypes = map[edge.COREWEBVIEW2_WEB_RESOURCE_CONTEXT]string{
+	edge.COREWEBVIEW2_WEB_RESOURCE_CONTEXT_DOCUMENT:         netfilter.TypeDocument,
+	edge.COREWEBVIEW2_WEB_RESOURCE_CONTEXT_STYLESHEET:       netfilter.TypeStylesheet,
+	edge.COREWEBVIEW2_WEB_RESOURCE_CONTEXT_IMAGE:            netfilter.TypeImage,
+	edge.COREWEBVIEW2_WEB_RESOURCE_CONTEXT_MEDIA:            netfilter.TypeMedia,
+	edge.COREWEBVIEW2_WEB_RESOURCE_CONTEXT_FONT:             netfilter.TypeFont,
+	edge.COREWEBVIEW2_WEB_RESOURCE_CONTEXT_SCRIPT:           netfilter.TypeScript,
+	edge.COREWEBVIEW2_WEB_RESOURCE_CONTEXT_XML_HTTP_REQUEST: netfilter.TypeXMLHTTPRequest,
+	edge.COREWEBVIEW2_WEB_RESOURCE_CONTEXT_FETCH:            netfilter.TypeXMLHTTPRequest,
+	edge.COREWEBVIEW2_WEB_RESOURCE_CONTEXT_EVENT_SOURCE:     netfilter.TypeXMLHTTPRequest,
+	edge.COREWEBVIEW2_WEB_RESOURCE_CONTEXT_WEBSOCKET:        netfilter.TypeWebSocket,
+	edge.COREWEBVIEW2_WEB_RESOURCE_CONTEXT_PING:             netfilter.TypePing,
+}
+
+// watchNetwork intercepts the requests of a webview when the network config
+// has rules. Without rules requests are not intercepted at all.
+func watchNetwork(chromium *edge.Chromium) {
+	if !netFilter.Active() {
+		return
+	}
+	chromium.WebResourceRequestedCallback = func(request *edge.ICoreWebView2WebResourceRequest, args *edge.ICoreWebView2WebResourceRequestedEventArgs) {
+		onWebResourceRequested(chromium, request, args)
+	}
+	chromium.AddWebResourceRequestedFilter("*", edge.COREWEBVIEW2_WEB_RESOURCE_CONTEXT_ALL)
+}
+
+// onWebResourceRequested blocks a request or adds the configured headers
+func onWebResourceRequested(chromium *edge.Chromium, request *edge.ICoreWebView2WebResourceRequest, args *edge.ICoreWebView2WebResourceRequestedEventArgs) {
+	uri, err := request.GetUri()
+	if err != nil {
+		return
+	}
+	typ := netfilter.TypeOther
+	if context, err := args.GetResourceContext(); err == nil {
+		if t, ok := resourceTypes[context]; ok {
+			typ = t
+		}
+	}
+	page, _ := chromium.GetSource()
+
+	if netFilter.Block(netfilter.Request{URL: uri, Page: page, Type: typ}) {
+		debugLog("Request to %s blocked", uri)
+		response, err := chromium.Environment().CreateWebResourceResponse(nil, 403, "Blocked", "")
+		if err == nil {
+			args.PutResponse(response)
+		}
+		return
+	}
+
+	headers := netFilter.Headers(uri)
+	if len(headers) == 0 {
+		return
+	}
+	requestHeaders, err := request.GetHeaders()
+	if err != nil {
+		return
+	}
+	defer requestHeaders.Release()
+	for _, h := range headers {
+		requestHeaders.SetHeader(h.Name, h.Value)
+	}
+}
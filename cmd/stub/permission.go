@@ -0,0 +1,132 @@
+package main
+
+import (
+	"net/url"
+	"unsafe"
+
+	"github.com/jchv/go-webview2/pkg/edge"
+	"github.com/user/w2app/internal/permission"
+	"golang.org/x/sys/windows"
+)
+
+const (
+	MB_YESNO        = 0x00000004
+	MB_ICONQUESTION = 0x00000020
+	IDYES           = 6
+)
+
+var procMessageBoxW = user32.NewProc("MessageBoxW")
+
+var (
+	permissionPolicy  *permission.Policy
+	permissionAnswers *permission.Answers
+)
+
+// permissionKinds maps the WebView2 permission kinds to the kinds in the
+// permissions config. Other kinds are left to WebView2.
+var permissionKinds = map[edge.CoreWebView2PermissionKind]string{
+	edge.CoreWebView2PermissionKindCamera:                     permission.Camera,
+	edge.CoreWebView2PermissionKindMicrophone:                 permission.Microphone,
+	edge.CoreWebView2PermissionKindGeolocation:                permission.Geolocation,
+	edge.CoreWebView2PermissionKindClipboardRead:              permission.Clipboard,
+	edge.CoreWebView2PermissionKindNotifications:              permission.Notifications,
+	edge.CoreWebView2PermissionKindMultipleAutomaticDownloads: permission.MultipleDownloads,
+}
+
+// permissionLabels describe the kinds in the prompt
+var permissionLabels = map[string]string{
+	permission.Camera:            "use your camera",
+	permission.Microphone:        "use your microphone",
+	permission.Geolocation:       "know your location",
+	permission.Clipboard:         "read your clipboard",
+	permission.Notifications:     "show notifications",
+	permission.MultipleDownloads: "download multiple files",
+}
+
+// loadPermissionAnswers reads the prompt answers of the current profile
+func loadPermissionAnswers() {
+	var err error
+	permissionAnswers, err = permission.LoadAnswers(dataDir)
+	if err != nil {
+		debugLog("Permission answers: %v", err)
+	}
+}
+
+// onPermissionRequested applies the permissions config to a request from a
+// page, asking the user when the permission is set to prompt
+func onPermissionRequested(sender *edge.ICoreWebView2, args *edge.ICoreWebView2PermissionRequestedEventArgs) {
+	k, err := args.GetPermissionKind()
+	if err != nil {
+		return
+	}
+	kind, ok := permissionKinds[k]
+	if !ok {
+		return
+	}
+	uri, _ := args.GetUri()
+	origin := permission.Origin(uri)
+
+	switch permissionPolicy.Decide(kind, uri) {
+	case permission.Allow:
+		args.PutState(edge.CoreWebView2PermissionStateAllow)
+		return
+	case permission.Deny:
+		debugLog("Permission %s denied for %s", kind, origin)
+		args.PutState(edge.CoreWebView2PermissionStateDeny)
+		return
+	}
+
+	if allowed, ok := permissionAnswers.Get(origin, kind); ok {
+		args.PutState(permissionState(allowed))
+		return
+	}
+	deferral, err := args.GetDeferral()
+	if err != nil {
+		return
+	}
+	// The prompt is modal, so it is not shown inside the event handler
+	args.AddRef()
+	mainWindow.Dispatch(func() {
+		defer args.Release()
+		defer deferral.Release()
+		defer deferral.Complete()
+		// Dispatch only runs from the message loop of the main window;
+		// messages posted while the prompt was open were swallowed
+		defer mainWindow.Dispatch(func() {})
+
+		// Answered while this request was waiting
+		allowed, ok := permissionAnswers.Get(origin, kind)
+		if !ok {
+			allowed = askPermission(origin, kind)
+			if err := permissionAnswers.Set(origin, kind, allowed); err != nil {
+				debugLog("Permission answers: %v", err)
+			}
+		}
+		debugLog("Permission %s for %s: allowed %v", kind, origin, allowed)
+		args.PutState(permissionState(allowed))
+	})
+}
+
+func permissionState(allowed bool) edge.CoreWebView2PermissionState {
+	if allowed {
+		return edge.CoreWebView2PermissionStateAllow
+	}
+	return edge.CoreWebView2PermissionStateDeny
+}
+
+// askPermission asks the user whether origin may use kind. Must run on the
+// UI thread.
+func askPermission(origin, kind string) bool {
+	site := origin
+	if u, err := url.Parse(origin); err == nil && u.Host != "" {
+		site = u.Host
+	}
+	text := site + " wants to " + permissionLabels[kind] + ".\n\nAllow? Your answer is remembered for this site."
+	ret, _, _ := procMessageBoxW.Call(
+		mainHwnd,
+		uintptr(unsafe.Pointer(windows.StringToUTF16Ptr(text))),
+		uintptr(unsafe.Pointer(windows.StringToUTF16Ptr(windowTitle()))),
+		MB_YESNO|MB_ICONQUESTION,
+	)
+	return ret == IDYES
+}
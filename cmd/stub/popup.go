@@ -0,0 +1,198 @@
+package main
+
+import (
+	"net/url"
+	"syscall"
+
+	"github.com/jchv/go-webview2"
+	"github.com/jchv/go-webview2/pkg/edge"
+	"github.com/user/w2app/internal/navpolicy"
+)
+
+// Size of secondary windows. Login pages are laid out for small popups.
+const (
+	popupWidth  = 600
+	popupHeight = 700
+)
+
+var procDestroyWindow = user32.NewProc("DestroyWindow")
+
+// popup is a secondary window opened by window.open or a target=_blank link.
+// It uses the data directory of the main window, so logins and cookies are
+// shared.
+type popup struct {
+	w    webview2.WebView
+	hwnd uintptr
+
+	// auth is set when the popup was opened on an auth domain. Once it
+	// leaves the auth domains for a URL the app allows (the redirect URI),
+	// the popup closes and the main window opens that URL.
+	auth bool
+}
+
+var (
+	// popups by window handle. Only touched on the UI thread.
+	popups = map[uintptr]*popup{}
+
+	// popupWndProc is the original window procedure of popup windows. All
+	// webview windows share one window class, so it is the same for all.
+	popupWndProc         uintptr
+	popupWndProcCallback = syscall.NewCallback(popupWindowProc)
+)
+
+// onNewWindowRequested decides what happens when a page opens a new window.
+// WebView2 never opens the window itself.
+func onNewWindowRequested(sender *edge.ICoreWebView2, args *edge.ICoreWebView2NewWindowRequestedEventArgs) {
+	uri, err := args.GetUri()
+	if err != nil {
+		return
+	}
+	args.PutHandled(true)
+	userInitiated, _ := args.GetIsUserInitiated()
+	from, _ := sender.GetSource()
+
+	switch navPolicy.Popup(navpolicy.Request{URL: uri, From: from, UserInitiated: userInitiated}) {
+	case navpolicy.PopupSame:
+		debugLog("New window to %s opened in the main window", uri)
+		mainWindow.Navigate(uri)
+	case navpolicy.PopupWindow:
+		// Creating a webview inside a WebView2 event handler is not safe
+		mainWindow.Dispatch(func() {
+			openPopup(uri)
+		})
+	case navpolicy.PopupBrowser:
+		debugLog("New window to %s opened in browser", uri)
+		go openBrowser(uri)
+	default:
+		debugLog("New window to %s blocked", uri)
+	}
+}
+
+// openPopup opens uri in a secondary window. Must run on the UI thread.
+func openPopup(uri string) {
+	cfg := appConfig
+	title := windowTitle()
+	if u, err := url.Parse(uri); err == nil && u.Host != "" {
+		title += " - " + u.Host
+	}
+
+	w := webview2.NewWithOptions(webview2.WebViewOptions{
+		Debug:     !cfg.DisableDevTools,
+		DataPath:  dataDir,
+		AutoFocus: true,
+		WindowOptions: webview2.WindowOptions{
+			Title:  title,
+			Width:  popupWidth,
+			Height: popupHeight,
+			Center: true,
+		},
+	})
+	// Dispatch only runs from the message loop of the main window; messages
+	// posted while the popup was being created were swallowed, so post again
+	defer mainWindow.Dispatch(func() {})
+	if w == nil {
+		debugLog("Failed to open popup for %s", uri)
+		return
+	}
+
+	p := &popup{w: w, hwnd: uintptr(w.Window()), auth: navPolicy.IsAuth(uri)}
+	popups[p.hwnd] = p
+	debugLog("Popup opened for %s (auth: %v)", uri, p.auth)
+
+	// Owned by the main window: stays above it and closes with it
+	const gwlpHwndParent = ^uintptr(7) // -8 as uintptr
+	procSetWindowLongPtrW.Call(p.hwnd, gwlpHwndParent, mainHwnd)
+	setWindowIcon(p.hwnd)
+	if cfg.TitleBarColor != "" {
+		setTitleBarColor(p.hwnd, cfg.TitleBarColor)
+	}
+
+	const gwlpWndProc = ^uintptr(3) // -4 as uintptr
+	popupWndProc, _, _ = procGetWindowLongPtrW.Call(p.hwnd, gwlpWndProc)
+	procSetWindowLongPtrW.Call(p.hwnd, gwlpWndProc, popupWndProcCallback)
+
+	if chromium := chromiumOf(w); chromium != nil {
+		chromium.NavigationStartingCallback = p.onNavigationStarting
+		chromium.NewWindowRequestedCallback = onNewWindowRequested
+		chromium.PermissionRequestedCallback = onPermissionRequested
+		watchDownloads(chromium)
+		watchNetwork(chromium)
+		chromium.WindowCloseRequestedCallback = func(*edge.ICoreWebView2) {
+			mainWindow.Dispatch(func() {
+				closePopup(p)
+			})
+		}
+	}
+	w.Navigate(uri)
+}
+
+// onNavigationStarting applies the navigation policy in a popup and returns
+// a finished login to the main window
+func (p *popup) onNavigationStarting(sender *edge.ICoreWebView2, args *edge.ICoreWebView2NavigationStartingEventArgs) {
+	uri, err := args.GetUri()
+	if err != nil {
+		return
+	}
+	if navPolicy.IsAuth(uri) {
+		return
+	}
+
+	if p.auth {
+		u, err := url.Parse(uri)
+		web := err == nil && (u.Scheme == "http" || u.Scheme == "https")
+		userInitiated, _ := args.GetIsUserInitiated()
+		redirect, _ := args.GetIsRedirected()
+		from, _ := sender.GetSource()
+		req := navpolicy.Request{URL: uri, From: from, UserInitiated: userInitiated, Redirect: redirect}
+		if web && navPolicy.Decide(req) == navpolicy.Allow {
+			debugLog("Login popup reached %s, returning to the main window", uri)
+			args.PutCancel(true)
+			mainWindow.Dispatch(func() {
+				closePopup(p)
+				mainWindow.Navigate(uri)
+			})
+			go showMainWindow()
+			return
+		}
+	}
+
+	onNavigationStarting(sender, args)
+}
+
+// closePopup closes a popup window and its browser. Must run on the UI thread.
+func closePopup(p *popup) {
+	if popups[p.hwnd] != p {
+		return
+	}
+	delete(popups, p.hwnd)
+	if chromium := chromiumOf(p.w); chromium != nil {
+		if controller := chromium.GetController(); controller != nil {
+			controller.Close()
+		}
+	}
+	procDestroyWindow.Call(p.hwnd)
+}
+
+// closePopups closes all popups, before the data directory is cleared on exit
+func closePopups() {
+	for _, p := range popups {
+		closePopup(p)
+	}
+}
+
+// popupWindowProc closes popups itself: the webview window procedure ends
+// the message loop of the whole app when any webview window is destroyed.
+func popupWindowProc(hwnd, msg, wParam, lParam uintptr) uintptr {
+	switch msg {
+	case WM_CLOSE:
+		if p, ok := popups[hwnd]; ok {
+			closePopup(p)
+		}
+		return 0
+	case WM_DESTROY:
+		ret, _, _ := procDefWindowProcW.Call(hwnd, msg, wParam, lParam)
+		return ret
+	}
+	ret, _, _ := procCallWindowProcW.Call(popupWndProc, hwnd, msg, wParam, lParam)
+	return ret
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+
+	"github.com/energye/systray"
+	"github.com/user/w2app/internal/appdata"
+	"github.com/user/w2app/internal/config"
+	"github.com/user/w2app/internal/ipc"
+)
+
+// defaultProfileLabel is how the default profile is shown in the tray menu
+const defaultProfileLabel = "Default"
+
+// profilesEnabled reports whether the tray shows the profile menu
+func profilesEnabled(cfg *config.AppConfig) bool {
+	return cfg.EnableProfiles || len(cfg.Profiles) > 0
+}
+
+// windowTitle is the title of the window and the tray icon. Named profiles
+// are shown in it so two accounts can be told apart.
+func windowTitle() string {
+	if profile == "" {
+		return appTitle
+	}
+	return appTitle + " (" + profile + ")"
+}
+
+// profileNames returns the default profile ("") followed by the profiles from
+// the config and the ones created from the tray. Names differing only in
+// case are the same profile, as they share a directory on Windows.
+func profileNames() []string {
+	created, err := appdata.Profiles(dataRoot)
+	if err != nil {
+		debugLog("Profiles: %v", err)
+	}
+
+	names := []string{""}
+	seen := map[string]bool{}
+	for _, name := range append(append([]string{}, appConfig.Profiles...), created...) {
+		if !seen[strings.ToLower(name)] {
+			seen[strings.ToLower(name)] = true
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// addProfileMenu adds the profile submenu to the tray. Picking another
+// profile opens it in its own instance, so both accounts can stay open.
+func addProfileMenu() {
+	mProfiles := systray.AddMenuItem("Profile", "Switch between accounts")
+	for _, name := range profileNames() {
+		addProfileItem(mProfiles, name)
+	}
+	mAdd := mProfiles.AddSubMenuItem("Add profile…", "Create a new profile")
+	mAdd.Click(func() {
+		go addProfile(mProfiles)
+	})
+}
+
+func addProfileItem(parent *systray.MenuItem, name string) {
+	label := name
+	if label == "" {
+		label = defaultProfileLabel
+	}
+	item := parent.AddSubMenuItemCheckbox(label, "Open this profile", strings.EqualFold(name, profile))
+	item.Click(func() {
+		if strings.EqualFold(name, profile) {
+			showMainWindow()
+			return
+		}
+		openProfile(name)
+	})
+}
+
+// openProfile brings the window of a profile to the front, starting a new
+// instance for it if it is not running
+func openProfile(name string) {
+	procAllowSetForegroundWindow.Call(ASFW_ANY)
+	err := ipc.Send(instanceKey(appID, name), 0, ipc.Message{Type: ipc.TypeShow})
+	if err == nil {
+		return
+	}
+	if !errors.Is(err, ipc.ErrNotRunning) {
+		debugLog("Open profile %q: %v", name, err)
+	}
+	if hwnd := findAppWindow(appConfig, name); hwnd != 0 {
+		procSendMessageW.Call(hwnd, WM_APP_SHOW, 0, 0)
+		procSetForegroundWindow.Call(hwnd)
+		return
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		debugLog("Open profile: %v", err)
+		return
+	}
+	var args []string
+	if name != "" {
+		args = append(args, "--profile", name)
+	}
+	if err := exec.Command(exe, args...).Start(); err != nil {
+		debugLog("Open profile %q: %v", name, err)
+	}
+}
+
+// addProfile asks for a profile name, creates the profile and opens it
+func addProfile(parent *systray.MenuItem) {
+	name, ok := promptText("Add profile", "Profile name:")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return
+	}
+	if err := appdata.CheckProfileName(name); err != nil {
+		showError(err.Error())
+		return
+	}
+
+	for _, existing := range profileNames() {
+		if strings.EqualFold(existing, name) {
+			openProfile(existing)
+			return
+		}
+	}
+	if err := os.MkdirAll(appdata.ProfileDir(dataRoot, name), 0755); err != nil {
+		showError("Gagal membuat profil: " + err.Error())
+		return
+	}
+	addProfileItem(parent, name)
+	openProfile(name)
+}
+
+// promptText shows an input box and returns the text entered. ok is false if
+// the box was cancelled.
+func promptText(title, prompt string) (text string, ok bool) {
+	quote := func(s string) string { return "'" + strings.ReplaceAll(s, "'", "''") + "'" }
+	script := fmt.Sprintf(`[Console]::OutputEncoding = [Text.Encoding]::UTF8
+Add-Type -AssemblyName Microsoft.VisualBasic
+$text = [Microsoft.VisualBasic.Interaction]::InputBox(%s, %s)
+if ($text -eq '') { exit 1 }
+$text`, quote(prompt), quote(title))
+
+	cmd := exec.Command("powershell", "-NoProfile", "-Command", script)
+	cmd.SysProcAttr = &syscall.SysProcAttr{HideWindow: true}
+	out, err := cmd.Output()
+	if err != nil {
+		return "", false
+	}
+	return strings.TrimRight(string(out), "\r\n"), true
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/user/w2app/internal/config"
+	"github.com/user/w2app/internal/protocol"
+	"golang.org/x/sys/windows/registry"
+)
+
+// appScheme is the URL scheme toast notifications of this app activate
+func appScheme() string {
+	return protocol.AppScheme(appID)
+}
+
+// parseNotificationURL recognizes the URL a clicked toast launches the app
+// with: <app scheme>://notification?id=123. Toasts shown before schemes were
+// per app use w2app://.
+func parseNotificationURL(arg string) (*url.URL, bool) {
+	u, err := url.Parse(arg)
+	if err != nil || u.Host != "notification" || !protocol.IsAppScheme(u.Scheme) {
+		return nil, false
+	}
+	return u, true
+}
+
+// appSchemes returns the schemes this app registers: its own scheme for
+// notifications and the schemes claimed in the config
+func appSchemes(cfg *config.AppConfig) []string {
+	var schemes []string
+	if cfg.EnableNotification {
+		schemes = append(schemes, appScheme())
+	}
+	for _, p := range cfg.Protocols {
+		schemes = append(schemes, protocol.Normalize(p.Scheme))
+	}
+	return schemes
+}
+
+// registerProtocols points the schemes of this app to this exe. The shared
+// w2app:// scheme is released if an older build of this app registered it.
+func registerProtocols(cfg *config.AppConfig) {
+	exePath, err := os.Executable()
+	if err != nil {
+		return
+	}
+	for _, scheme := range appSchemes(cfg) {
+		if err := registerProtocol(scheme, exePath); err != nil {
+			debugLog("Warning: failed to register protocol %s: %v", scheme, err)
+		}
+	}
+	unregisterProtocol(protocol.LegacyScheme, exePath)
+}
+
+// unregisterProtocol removes the registration of a scheme if it launches
+// this exe. Schemes taken over by another app are left alone.
+func unregisterProtocol(scheme, exePath string) error {
+	path := `Software\Classes\` + scheme
+	key, err := registry.OpenKey(registry.CURRENT_USER, path+`\shell\open\command`, registry.QUERY_VALUE)
+	if errors.Is(err, registry.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	command, _, err := key.GetStringValue("")
+	key.Close()
+	if err != nil || !strings.Contains(strings.ToLower(command), strings.ToLower(exePath)) {
+		return nil
+	}
+
+	debugLog("Removing protocol %s://", scheme)
+	return deleteRegistryTree(registry.CURRENT_USER, path)
+}
+
+// deleteRegistryTree deletes a registry key and all its subkeys
+func deleteRegistryTree(root registry.Key, path string) error {
+	key, err := registry.OpenKey(root, path, registry.ENUMERATE_SUB_KEYS)
+	if errors.Is(err, registry.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	names, err := key.ReadSubKeyNames(-1)
+	key.Close()
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if err := deleteRegistryTree(root, path+`\`+name); err != nil {
+			return err
+		}
+	}
+	return registry.DeleteKey(root, path)
+}
+
+// uninstall removes what the app registered on this PC: its protocols, the
+// auto-start entry and the Start Menu shortcut. The data directory is kept.
+func uninstall(cfg *config.AppConfig) error {
+	exePath, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	schemes := append(appSchemes(cfg), appScheme(), protocol.LegacyScheme)
+	for _, scheme := range schemes {
+		if err := unregisterProtocol(scheme, exePath); err != nil {
+			errs = append(errs, fmt.Errorf("protocol %s: %w", scheme, err))
+		}
+	}
+
+	if isAutoStartEnabled() {
+		if err := setAutoStart(false); err != nil {
+			errs = append(errs, fmt.Errorf("auto-start: %w", err))
+		}
+	}
+
+	title := cfg.Title
+	if title == "" {
+		title = "Web App"
+	}
+	if shortcut, err := startMenuShortcutPath(title); err == nil {
+		if err := os.Remove(shortcut); err != nil && !errors.Is(err, os.ErrNotExist) {
+			errs = append(errs, fmt.Errorf("shortcut: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,219 @@
+package main
+
+import (
+	"fmt"
+	"unsafe"
+
+	"github.com/user/w2app/internal/config"
+	"github.com/user/w2app/internal/winframe"
+)
+
+// resizeBorder is the width in CSS pixels of the edge that resizes a
+// frameless window
+const resizeBorder = 6
+
+// alwaysOnTop is the current topmost state. It starts from the config and is
+// toggled from the tray menu.
+var alwaysOnTop bool
+
+// wantsFullscreen reports whether the app runs fullscreen. Choosing a
+// fullscreen monitor implies fullscreen.
+func wantsFullscreen(cfg *config.AppConfig) bool {
+	return cfg.Fullscreen || cfg.FullscreenMonitor != ""
+}
+
+// hasPlacement reports whether the config asks for a monitor or position.
+// It overrides the position saved from the last launch.
+func hasPlacement(cfg *config.AppConfig) bool {
+	return cfg.Monitor != "" || cfg.Position != "" || cfg.X != nil || cfg.Y != nil
+}
+
+// applyWindowOptions applies the frameless, resizable and always-on-top
+// options to the window
+func applyWindowOptions(hwnd uintptr, cfg *config.AppConfig) {
+	const gwlStyle = ^uintptr(15) // -16 as uintptr
+	style, _, _ := procGetWindowLongPtrW.Call(hwnd, gwlStyle)
+	style = uintptr(winframe.Style(uint32(style), cfg.Frameless, cfg.Resizable))
+	procSetWindowLongPtrW.Call(hwnd, gwlStyle, style)
+	procSetWindowPos.Call(hwnd, 0, 0, 0, 0, 0, SWP_NOMOVE|SWP_NOSIZE|SWP_NOZORDER|SWP_NOACTIVATE|SWP_FRAMECHANGED)
+
+	if alwaysOnTop {
+		setAlwaysOnTop(true)
+	}
+}
+
+// setAlwaysOnTop keeps the main window above other windows
+func setAlwaysOnTop(on bool) {
+	alwaysOnTop = on
+	if mainHwnd == 0 {
+		return
+	}
+	insertAfter := HWND_NOTOPMOST
+	if on {
+		insertAfter = HWND_TOPMOST
+	}
+	procSetWindowPos.Call(mainHwnd, insertAfter, 0, 0, 0, 0, SWP_NOMOVE|SWP_NOSIZE|SWP_NOACTIVATE)
+}
+
+// startResize resizes the window from the edge under the point (x, y) of a
+// page of width x height CSS pixels. Maximized windows are not resized.
+func startResize(x, y, width, height float64) {
+	if isMaximized, _, _ := procIsZoomed.Call(mainHwnd); isMaximized != 0 {
+		return
+	}
+	hit := winframe.HitTest(int32(x), int32(y), int32(width), int32(height), resizeBorder)
+	if hit.Resize() {
+		startWindowMove(hit)
+	}
+}
+
+// startWindowMove lets Windows move or resize the window as if the mouse
+// went down on the given non-client area. The webview has mouse capture,
+// so it is released first.
+func startWindowMove(hit winframe.Hit) {
+	var pt struct{ X, Y int32 }
+	procGetCursorPos.Call(uintptr(unsafe.Pointer(&pt)))
+	procReleaseCapture.Call()
+	procPostMessageW.Call(mainHwnd, WM_NCLBUTTONDOWN, uintptr(hit), uintptr(uint16(pt.X))|uintptr(uint16(pt.Y))<<16)
+}
+
+// toggleMaximize maximizes the window or restores it if already maximized
+func toggleMaximize() {
+	if isMaximized, _, _ := procIsZoomed.Call(mainHwnd); isMaximized != 0 {
+		procShowWindow.Call(mainHwnd, SW_RESTORE)
+	} else {
+		procShowWindow.Call(mainHwnd, SW_MAXIMIZE)
+	}
+}
+
+// framelessWndProc handles the messages that hide the frame of a frameless
+// window. It returns false for messages that should go to the original
+// window procedure.
+func framelessWndProc(hwnd, msg, wParam, lParam uintptr) (uintptr, bool) {
+	switch msg {
+	case WM_NCCALCSIZE:
+		if wParam == 0 {
+			return 0, false
+		}
+		// The client area takes the whole window. A maximized window sticks
+		// out of the monitor by its frame, so pull the client back in.
+		if isMaximized, _, _ := procIsZoomed.Call(hwnd); isMaximized != 0 {
+			fx, fy := frameSize()
+			rect := (*RECT)(unsafe.Pointer(lParam))
+			rect.Left += fx
+			rect.Top += fy
+			rect.Right -= fx
+			rect.Bottom -= fy
+		}
+		return 0, true
+
+	case WM_GETMINMAXINFO:
+		// Without a caption, maximize would cover the taskbar: limit it to
+		// the work area (plus the frame that WM_NCCALCSIZE takes off)
+		ret, _, _ := procCallWindowProcW.Call(originalWndProc, hwnd, msg, wParam, lParam)
+		hMonitor, _, _ := procMonitorFromWindow.Call(hwnd, MONITOR_DEFAULTTONEAREST)
+		var mi MONITORINFO
+		mi.CbSize = uint32(unsafe.Sizeof(mi))
+		if ok, _, _ := procGetMonitorInfoW.Call(hMonitor, uintptr(unsafe.Pointer(&mi))); ok != 0 {
+			fx, fy := frameSize()
+			info := (*minMaxInfo)(unsafe.Pointer(lParam))
+			info.MaxPosition.X = mi.RcWork.Left - mi.RcMonitor.Left - fx
+			info.MaxPosition.Y = mi.RcWork.Top - mi.RcMonitor.Top - fy
+			info.MaxSize.X = mi.RcWork.Right - mi.RcWork.Left + 2*fx
+			info.MaxSize.Y = mi.RcWork.Bottom - mi.RcWork.Top + 2*fy
+		}
+		return ret, true
+	}
+	return 0, false
+}
+
+// frameSize returns the width of the sizing frame Windows adds around a
+// maximized window
+func frameSize() (int32, int32) {
+	padding, _, _ := procGetSystemMetrics.Call(SM_CXPADDEDBORDER)
+	cx, _, _ := procGetSystemMetrics.Call(SM_CXFRAME)
+	cy, _, _ := procGetSystemMetrics.Call(SM_CYFRAME)
+	return int32(cx + padding), int32(cy + padding)
+}
+
+// minMaxInfo is the MINMAXINFO structure
+type minMaxInfo struct {
+	Reserved, MaxSize, MaxPosition, MinTrackSize, MaxTrackSize struct{ X, Y int32 }
+}
+
+// getFramelessScript returns the script that lets the page move and resize a
+// frameless window. Elements marked with data-w2app-drag or the CSS
+// app-region: drag property move the window; data-w2app-no-drag or
+// app-region: no-drag opt a child back out. Form controls and links never
+// start a drag.
+func getFramelessScript(resizable bool) string {
+	return fmt.Sprintf(`
+		(function() {
+			var BORDER = %d;
+			var resizable = %t;
+			var cursors = {
+				'top': 'ns-resize', 'bottom': 'ns-resize',
+				'left': 'ew-resize', 'right': 'ew-resize',
+				'top-left': 'nwse-resize', 'bottom-right': 'nwse-resize',
+				'top-right': 'nesw-resize', 'bottom-left': 'nesw-resize'
+			};
+
+			function isDragRegion(el) {
+				if (el.closest && el.closest('a, button, input, select, textarea, label, [contenteditable]:not([contenteditable="false"])')) {
+					return false;
+				}
+				for (; el && el.nodeType === 1; el = el.parentElement) {
+					if (el.hasAttribute('data-w2app-no-drag')) return false;
+					if (el.hasAttribute('data-w2app-drag')) return true;
+					var style = getComputedStyle(el);
+					var region = style.getPropertyValue('app-region') || style.getPropertyValue('-webkit-app-region');
+					if (region === 'no-drag') return false;
+					if (region === 'drag') return true;
+				}
+				return false;
+			}
+
+			// The edge is only used for the cursor; the window picks the
+			// edge to resize from the same position
+			function edgeAt(e) {
+				if (!resizable) return '';
+				// Maximized windows cannot be resized
+				if (window.outerWidth >= screen.availWidth && window.outerHeight >= screen.availHeight) return '';
+				var x = e.clientX, y = e.clientY;
+				var v = y < BORDER ? 'top' : (y >= window.innerHeight - BORDER ? 'bottom' : '');
+				var h = x < BORDER ? 'left' : (x >= window.innerWidth - BORDER ? 'right' : '');
+				return v && h ? v + '-' + h : (v || h);
+			}
+
+			var cursorSet = false;
+			document.addEventListener('mousemove', function(e) {
+				var edge = edgeAt(e);
+				if (edge) {
+					document.documentElement.style.cursor = cursors[edge];
+					cursorSet = true;
+				} else if (cursorSet) {
+					document.documentElement.style.cursor = '';
+					cursorSet = false;
+				}
+			}, true);
+
+			document.addEventListener('mousedown', function(e) {
+				if (e.button !== 0) return;
+				var edge = edgeAt(e);
+				if (edge) {
+					e.preventDefault();
+					e.stopPropagation();
+					w2appStartResize(e.clientX, e.clientY, window.innerWidth, window.innerHeight);
+					return;
+				}
+				if (!isDragRegion(e.target)) return;
+				e.preventDefault();
+				if (e.detail === 2) {
+					if (resizable) w2appToggleMaximize();
+				} else {
+					w2appStartDrag();
+				}
+			}, true);
+		})();
+	`, resizeBorder, resizable)
+}
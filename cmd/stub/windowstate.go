@@ -0,0 +1,189 @@
+package main
+
+import (
+	"path/filepath"
+	"sync"
+	"syscall"
+	"unsafe"
+
+	"github.com/user/w2app/internal/winstate"
+)
+
+// MONITORINFOEX structure, used to recognize a monitor across launches
+type MONITORINFOEX struct {
+	MONITORINFO
+	SzDevice [32]uint16
+}
+
+var (
+	windowStatePath string
+	lastNormalRect  winstate.Rect // Last position and size while not maximized, minimized or fullscreen
+	lastMaximized   bool
+	geometryKnown   bool // The window has been placed on screen at least once
+)
+
+// monitorInfo returns the bounds, work area and device name of a monitor
+func monitorInfo(hMonitor uintptr) (winstate.Monitor, bool) {
+	var mi MONITORINFOEX
+	mi.CbSize = uint32(unsafe.Sizeof(mi))
+	if ret, _, _ := procGetMonitorInfoW.Call(hMonitor, uintptr(unsafe.Pointer(&mi))); ret == 0 {
+		return winstate.Monitor{}, false
+	}
+	return winstate.Monitor{
+		ID:      syscall.UTF16ToString(mi.SzDevice[:]),
+		Bounds:  winstate.Rect(mi.RcMonitor),
+		Work:    winstate.Rect(mi.RcWork),
+		Primary: mi.DwFlags&MONITORINFOF_PRIMARY != 0,
+	}, true
+}
+
+var (
+	// enumMonitorsMu guards enumMonitorsResult: the window is also shown
+	// from the tray thread
+	enumMonitorsMu       sync.Mutex
+	enumMonitorsResult   []winstate.Monitor
+	enumMonitorsCallback = syscall.NewCallback(func(hMonitor, hdc, rect, data uintptr) uintptr {
+		if m, ok := monitorInfo(hMonitor); ok {
+			enumMonitorsResult = append(enumMonitorsResult, m)
+		}
+		return 1 // Continue enumeration
+	})
+)
+
+// enumMonitors lists the monitors that are currently attached
+func enumMonitors() []winstate.Monitor {
+	enumMonitorsMu.Lock()
+	defer enumMonitorsMu.Unlock()
+	enumMonitorsResult = nil
+	procEnumDisplayMonitors.Call(0, 0, enumMonitorsCallback, 0)
+	return enumMonitorsResult
+}
+
+func windowRect(hwnd uintptr) winstate.Rect {
+	var rect RECT
+	procGetWindowRect.Call(hwnd, uintptr(unsafe.Pointer(&rect)))
+	return winstate.Rect(rect)
+}
+
+func setWindowRect(hwnd uintptr, r winstate.Rect) {
+	procSetWindowPos.Call(hwnd, 0,
+		uintptr(r.Left), uintptr(r.Top), uintptr(r.Width()), uintptr(r.Height()),
+		SWP_NOZORDER|SWP_NOACTIVATE)
+}
+
+// windowMonitor returns the monitor the window is on, or the one selected by
+// spec if it is set and attached
+func windowMonitor(hwnd uintptr, spec string, fallback uintptr) (winstate.Monitor, bool) {
+	if spec != "" {
+		if m, found := winstate.SelectMonitor(spec, enumMonitors()); found {
+			return m, true
+		}
+		debugLog("Monitor %q not found, using the current one", spec)
+	}
+	hMonitor, _, _ := procMonitorFromWindow.Call(hwnd, fallback)
+	return monitorInfo(hMonitor)
+}
+
+// placeWindow moves the window to the configured monitor and position. Without
+// options it centers the window on its monitor (the primary monitor if the
+// window is off-screen). The size is kept unless it does not fit.
+func placeWindow(hwnd uintptr) {
+	cfg := appConfig
+	m, ok := windowMonitor(hwnd, cfg.Monitor, MONITOR_DEFAULTTOPRIMARY)
+	if !ok {
+		return
+	}
+	r := windowRect(hwnd)
+	p := winstate.Placement{Position: cfg.Position, X: cfg.X, Y: cfg.Y}
+	setWindowRect(hwnd, p.Rect(r.Width(), r.Height(), m.Work))
+}
+
+// fullscreenMonitor returns the monitor a fullscreen window covers: the
+// configured fullscreen monitor, or else the one the window is on
+func fullscreenMonitor(hwnd uintptr) winstate.Monitor {
+	m, _ := windowMonitor(hwnd, appConfig.FullscreenMonitor, MONITOR_DEFAULTTONEAREST)
+	return m
+}
+
+// restoreWindowState moves the window to where it was when the app last
+// closed. It returns nil if there is no saved state.
+func restoreWindowState(hwnd uintptr) *winstate.State {
+	if dataDir == "" {
+		return nil
+	}
+	windowStatePath = filepath.Join(dataDir, winstate.FileName)
+
+	st, err := winstate.Load(windowStatePath)
+	if err != nil {
+		debugLog("Window state: %v", err)
+	}
+	if st == nil {
+		return nil
+	}
+
+	bounds, _, ok := winstate.Place(st, enumMonitors())
+	if !ok {
+		return nil
+	}
+	setWindowRect(hwnd, bounds)
+	lastNormalRect = bounds
+	lastMaximized = st.Maximized
+	wasMaximized = st.Maximized
+	geometryKnown = true
+	return st
+}
+
+// trackWindowGeometry remembers the normal position and size of the window
+// as it is moved, resized, maximized and restored
+func trackWindowGeometry(hwnd, msg, wParam uintptr) {
+	if isFullscreenMode {
+		return
+	}
+	if msg == WM_SIZE {
+		switch wParam {
+		case SIZE_MAXIMIZED:
+			lastMaximized = true
+			return
+		case SIZE_MINIMIZED:
+			return
+		case SIZE_RESTORED:
+			lastMaximized = false
+		}
+	}
+	rememberWindowGeometry(hwnd)
+}
+
+// rememberWindowGeometry records the current window rect if the window is
+// visible in its normal state
+func rememberWindowGeometry(hwnd uintptr) {
+	if isFullscreenMode {
+		return
+	}
+	visible, _, _ := procIsWindowVisible.Call(hwnd)
+	zoomed, _, _ := procIsZoomed.Call(hwnd)
+	iconic, _, _ := procIsIconic.Call(hwnd)
+	if visible == 0 || zoomed != 0 || iconic != 0 {
+		return
+	}
+	lastNormalRect = windowRect(hwnd)
+	geometryKnown = true
+}
+
+// saveWindowState writes the window geometry for the next launch
+func saveWindowState(hwnd uintptr) {
+	if windowStatePath == "" || !geometryKnown {
+		return
+	}
+	st := &winstate.State{
+		Bounds:     lastNormalRect,
+		Maximized:  lastMaximized,
+		Fullscreen: isFullscreenMode,
+	}
+	hMonitor, _, _ := procMonitorFromWindow.Call(hwnd, MONITOR_DEFAULTTONEAREST)
+	if m, ok := monitorInfo(hMonitor); ok {
+		st.Monitor = m.ID
+	}
+	if err := winstate.Save(windowStatePath, st); err != nil {
+		debugLog("Window state: %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"text/tabwriter"
+
+	"github.com/user/w2app/internal/generator"
+	"github.com/user/w2app/internal/manifest"
+)
+
+// buildResult adalah hasil build satu app untuk tabel ringkasan
+type buildResult struct {
+	app    *manifest.App
+	result *generator.Result
+	err    error
+}
+
+func buildCmd(args []string) {
+	fs := flag.NewFlagSet("build", flag.ExitOnError)
+	var files stringList
+	fs.Var(&files, "f", "Path ke file manifest (bisa diulang)")
+	all := fs.Bool("all", false, "Build semua manifest di direktori ini dan subdirektorinya")
+	jobs := fs.Int("j", 1, "Jumlah app yang di-build bersamaan")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: w2app build [-f w2app.json]... [--all] [-j N] [nama app...]")
+		fmt.Println("\nBuild semua app di manifest, atau hanya app dengan nama yang disebut.")
+		fmt.Println("\nOptions:")
+		fmt.Println("    -f                 Path ke file manifest, bisa diulang (default: " + strings.Join(manifest.Names, ", ") + ")")
+		fmt.Println("    --all              Build semua manifest di direktori ini dan subdirektorinya")
+		fmt.Println("    -j                 Jumlah app yang di-build bersamaan (default: 1)")
+		fmt.Println("\nKey app sama dengan flag 'w2app create' (tanpa --), kecuali nav-rules, deep-links, dan protocols:")
+		fmt.Println("    " + strings.Join(manifest.Keys(), ", "))
+		fmt.Println("\nExamples:")
+		fmt.Println("  w2app build")
+		fmt.Println("  w2app build -f apps/w2app.yaml WhatsApp")
+		fmt.Println("  w2app build --all -j 8")
+	}
+
+	names := parseInterspersed(fs, args)
+	if *jobs < 1 {
+		fmt.Println("Error: -j minimal 1")
+		os.Exit(1)
+	}
+
+	paths := []string(files)
+	switch {
+	case *all && len(paths) > 0:
+		fmt.Println("Error: --all tidak bisa digabung dengan -f")
+		os.Exit(1)
+	case *all:
+		found, err := manifest.Find(".")
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		if len(found) == 0 {
+			fmt.Printf("Error: tidak ada manifest (%s) di direktori ini\n", strings.Join(manifest.Names, ", "))
+			os.Exit(1)
+		}
+		paths = found
+	case len(paths) == 0:
+		for _, candidate := range manifest.Names {
+			if _, err := os.Stat(candidate); err == nil {
+				paths = []string{candidate}
+				break
+			}
+		}
+		if len(paths) == 0 {
+			fmt.Printf("Error: manifest tidak ditemukan (%s), gunakan -f atau --all\n", strings.Join(manifest.Names, ", "))
+			os.Exit(1)
+		}
+	}
+
+	loaded, err := manifest.LoadAll(paths)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	apps, err := selectApps(loaded, names)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	results := buildApps(ctx, apps, *jobs)
+	if printBuildSummary(results) > 0 {
+		os.Exit(1)
+	}
+}
+
+// buildApps menjalankan Generate untuk setiap app dengan maksimal jobs app
+// bersamaan. Error satu app tidak menghentikan app lain, tapi pembatalan ctx
+// menghentikan semuanya.
+func buildApps(ctx context.Context, apps []*manifest.App, jobs int) []buildResult {
+	cache := generator.NewIconCache()
+	defer cache.Close()
+
+	results := make([]buildResult, len(apps))
+	var (
+		wg    sync.WaitGroup
+		outMu sync.Mutex
+		sem   = make(chan struct{}, jobs)
+	)
+	for i, app := range apps {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			header := fmt.Sprintf("[%d/%d] %s (%s)\n", i+1, len(apps), app.Name, app.Source())
+
+			// Build paralel: log setiap app ditampung lalu dicetak utuh supaya
+			// output beberapa app tidak bercampur
+			var (
+				buf bytes.Buffer
+				out io.Writer = os.Stdout
+			)
+			if jobs > 1 {
+				out = &buf
+			} else {
+				fmt.Print(header)
+			}
+			opts, err := app.Options()
+			opts.IconCache = cache
+			opts.Logger = newCLILogger(out)
+
+			var res *generator.Result
+			if err == nil {
+				res, err = generator.Generate(ctx, opts)
+			}
+			if err == nil {
+				printGenerateResult(out, res)
+			}
+			results[i] = buildResult{app: app, result: res, err: err}
+
+			outMu.Lock()
+			defer outMu.Unlock()
+			if jobs > 1 {
+				fmt.Print(header)
+				os.Stdout.Write(buf.Bytes())
+			}
+			if err != nil {
+				fmt.Printf("Error: %v\n\n", err)
+			}
+		}()
+	}
+	wg.Wait()
+	return results
+}
+
+// printBuildSummary mencetak tabel hasil build dan mengembalikan jumlah app yang gagal
+func printBuildSummary(results []buildResult) int {
+	failed := 0
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tSTATUS\tOUTPUT\tSIZE\tSHA-256\tWARNINGS")
+	for _, r := range results {
+		if r.err != nil {
+			failed++
+			fmt.Fprintf(tw, "%s\tGAGAL\t-\t-\t-\t%s\n", r.app.Name, r.err)
+			continue
+		}
+		warnings := "-"
+		if len(r.result.Warnings) > 0 {
+			warnings = strings.Join(r.result.Warnings, "; ")
+		}
+		fmt.Fprintf(tw, "%s\tOK\t%s\t%s\t%s\t%s\n", r.app.Name, r.result.Path, generator.FormatBytes(r.result.Size), r.result.SHA256, warnings)
+	}
+	tw.Flush()
+
+	fmt.Printf("\n%d app berhasil, %d gagal\n", len(results)-failed, failed)
+	return failed
+}
+
+// selectApps memilih app berdasarkan nama (tidak case-sensitive).
+// Tanpa nama, semua app dipilih.
+func selectApps(all []*manifest.App, names []string) ([]*manifest.App, error) {
+	if len(names) == 0 {
+		return all, nil
+	}
+
+	var apps []*manifest.App
+	for _, name := range names {
+		found := false
+		for _, app := range all {
+			if strings.EqualFold(app.Name, name) {
+				apps = append(apps, app)
+				found = true
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("app %q tidak ada di manifest", name)
+		}
+	}
+	return apps, nil
+}
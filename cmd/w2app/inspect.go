@@ -0,0 +1,154 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/user/w2app/internal/config"
+	"github.com/user/w2app/internal/generator"
+)
+
+func inspectCmd(args []string) {
+	fs := flag.NewFlagSet("inspect", flag.ExitOnError)
+	jsonOut := fs.Bool("json", false, "Output dalam format JSON")
+	fs.Usage = func() {
+		fmt.Println("Usage: w2app inspect [--json] <app.exe>")
+		fmt.Println("\nOptions:")
+		fmt.Println("    --json             Output dalam format JSON (untuk script)")
+	}
+
+	rest := parseInterspersed(fs, args)
+	if len(rest) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	info, err := generator.Inspect(rest[0])
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(info); err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	printAppInfo(info)
+}
+
+func printAppInfo(info *generator.AppInfo) {
+	fmt.Printf("File      : %s (%s)\n", info.Path, generator.FormatBytes(info.FileSize))
+	fmt.Printf("Stub      : %s\n", generator.FormatBytes(info.StubSize))
+	switch info.Storage {
+	case config.StorageResource:
+		fmt.Printf("Config    : resource RCDATA %q\n", config.ResourceName)
+	default:
+		fmt.Printf("Config    : trailer V%d\n", info.TrailerVersion)
+	}
+
+	switch sig := info.Signature; {
+	case sig == nil:
+		fmt.Println("Signature : tidak ada")
+	case !sig.Valid:
+		fmt.Printf("Signature : TIDAK VALID (%s)\n", sig.Error)
+	case sig.Timestamp != nil:
+		fmt.Printf("Signature : %s (timestamp %s)\n", sig.Signer, sig.Timestamp.UTC().Format("2006-01-02 15:04:05 MST"))
+	default:
+		fmt.Printf("Signature : %s\n", sig.Signer)
+	}
+
+	if len(info.Icons) == 0 {
+		fmt.Println("Icon      : tidak ada")
+	}
+	for _, icon := range info.Icons {
+		fmt.Printf("Icon      : %s (lang 0x%04x) %s\n", icon.ID, icon.Lang, strings.Join(icon.Sizes, ", "))
+	}
+
+	if len(info.VersionInfo) > 0 {
+		fmt.Println("\nVersion info:")
+		keys := make([]string, 0, len(info.VersionInfo))
+		for k := range info.VersionInfo {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("  %-20s %s\n", k, info.VersionInfo[k])
+		}
+	}
+
+	fmt.Println("\nConfig:")
+	v := reflect.ValueOf(info.Config).Elem()
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		key := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		fmt.Printf("  %-20s %s\n", key, formatConfigValue(v.Field(i)))
+	}
+}
+
+// formatConfigValue menampilkan nilai config dalam satu baris
+func formatConfigValue(v reflect.Value) string {
+	switch v.Kind() {
+	case reflect.String:
+		s := v.String()
+		if s == "" {
+			return "-"
+		}
+		if line, _, multi := strings.Cut(s, "\n"); multi || len(s) > 60 {
+			if len(line) > 60 {
+				line = line[:60]
+			}
+			return fmt.Sprintf("%s… (%d bytes)", line, len(s))
+		}
+		return s
+	case reflect.Slice:
+		if v.Len() == 0 {
+			return "-"
+		}
+	case reflect.Pointer:
+		if v.IsNil() {
+			return "-"
+		}
+		return formatConfigValue(v.Elem())
+	}
+	data, err := json.Marshal(v.Interface())
+	if err != nil {
+		return fmt.Sprint(v.Interface())
+	}
+	// Object besar, mis. network dengan filter list, dipotong; isi lengkapnya
+	// ada di --json
+	if len(data) > 200 {
+		return fmt.Sprintf("%s… (%d bytes)", data[:200], len(data))
+	}
+	return string(data)
+}
+
+// parseInterspersed mem-parse flag yang boleh muncul sebelum atau sesudah
+// argumen posisi (misal "app.exe --json") dan mengembalikan argumen posisi
+func parseInterspersed(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			os.Exit(1)
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			return positional
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/user/w2app/internal/generator"
+)
+
+// cliHandler menampilkan log generator dalam format baris singkat untuk
+// terminal: "  pesan key=value". Warning diberi prefix "Warning: " dan
+// atribut err ditulis setelah pesan, mirip output w2app sebelumnya.
+type cliHandler struct {
+	mu    *sync.Mutex
+	w     io.Writer
+	attrs []slog.Attr
+}
+
+func newCLILogger(w io.Writer) *slog.Logger {
+	return slog.New(&cliHandler{mu: &sync.Mutex{}, w: w})
+}
+
+func (h *cliHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return level >= slog.LevelInfo
+}
+
+func (h *cliHandler) Handle(_ context.Context, r slog.Record) error {
+	var b strings.Builder
+	b.WriteString("  ")
+	if r.Level >= slog.LevelWarn {
+		b.WriteString("Warning: ")
+	}
+	b.WriteString(r.Message)
+
+	var errText string
+	write := func(a slog.Attr) bool {
+		if a.Key == "err" {
+			errText = a.Value.String()
+			return true
+		}
+		fmt.Fprintf(&b, " %s=%v", a.Key, a.Value)
+		return true
+	}
+	for _, a := range h.attrs {
+		write(a)
+	}
+	r.Attrs(write)
+	if errText != "" {
+		b.WriteString(": " + errText)
+	}
+	b.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := io.WriteString(h.w, b.String())
+	return err
+}
+
+func (h *cliHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &cliHandler{mu: h.mu, w: h.w, attrs: append(h.attrs[:len(h.attrs):len(h.attrs)], attrs...)}
+}
+
+// WithGroup tidak dipakai generator, group diabaikan
+func (h *cliHandler) WithGroup(string) slog.Handler {
+	return h
+}
+
+// printGenerateResult mencetak ringkasan app yang berhasil dibuat
+func printGenerateResult(w io.Writer, res *generator.Result) {
+	cfg := res.Config
+	fmt.Fprintf(w, "\n✓ Berhasil membuat aplikasi: %s\n", res.Path)
+	fmt.Fprintf(w, "  URL       : %s\n", cfg.URL)
+	fmt.Fprintf(w, "  App ID    : %s\n", cfg.AppID)
+	fmt.Fprintf(w, "  Ukuran    : %s\n", generator.FormatBytes(res.Size))
+	fmt.Fprintf(w, "  Window    : %dx%d", cfg.Width, cfg.Height)
+	if cfg.Resizable {
+		fmt.Fprint(w, " (resizable)")
+	}
+	if cfg.Fullscreen {
+		fmt.Fprint(w, " (fullscreen)")
+	}
+	fmt.Fprintln(w)
+
+	if cfg.SingleInstance {
+		fmt.Fprintln(w, "  Mode      : Single instance")
+	}
+	if res.IconSource != "" {
+		fmt.Fprintf(w, "  Icon      : %s (embedded)\n", res.IconSource)
+	}
+	if res.Signer != "" {
+		fmt.Fprintf(w, "  Signed    : %s", res.Signer)
+		if res.Timestamped {
+			fmt.Fprint(w, " (timestamped)")
+		}
+		fmt.Fprintln(w)
+	}
+	if cfg.InjectCSS != "" {
+		fmt.Fprintf(w, "  CSS       : %d bytes injected\n", len(cfg.InjectCSS))
+	}
+	if cfg.InjectJS != "" {
+		fmt.Fprintf(w, "  JS        : %d bytes injected\n", len(cfg.InjectJS))
+	}
+	fmt.Fprintf(w, "  SHA-256   : %s\n", res.SHA256)
+	fmt.Fprintln(w)
+}
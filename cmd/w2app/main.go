@@ -1,16 +1,23 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"os"
+	"os/signal"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/user/w2app/internal/authenticode"
+	"github.com/user/w2app/internal/config"
 	"github.com/user/w2app/internal/generator"
+	"github.com/user/w2app/internal/winstate"
 )
 
 var (
-	version = "1.2.0"
+	version = "1.3.0"
 )
 
 func main() {
@@ -22,8 +29,18 @@ func main() {
 	switch os.Args[1] {
 	case "create":
 		createCmd(os.Args[2:])
+	case "build":
+		buildCmd(os.Args[2:])
+	case "inspect":
+		inspectCmd(os.Args[2:])
+	case "reconfigure":
+		reconfigureCmd(os.Args[2:])
+	case "upgrade":
+		upgradeCmd(os.Args[2:])
+	case "verify":
+		verifyCmd(os.Args[2:])
 	case "platforms":
-		listPlatforms()
+		platformsCmd(os.Args[2:])
 	case "version", "-v", "--version":
 		fmt.Println("w2app version", version)
 	case "help", "-h", "--help":
@@ -41,10 +58,14 @@ func createCmd(args []string) {
 	urlShort := fs.String("u", "", "URL target - shorthand")
 	name := fs.String("name", "", "Nama aplikasi (wajib)")
 	nameShort := fs.String("n", "", "Nama aplikasi - shorthand")
+	appID := fs.String("app-id", "", "Identitas tetap app, mis. com.example.chat (default: dibuat otomatis)")
 	output := fs.String("out", ".", "Direktori output")
 	outputShort := fs.String("o", "", "Direktori output - shorthand")
 	platform := fs.String("platform", "windows", "Platform target")
 	platformShort := fs.String("p", "", "Platform target - shorthand")
+	arch := fs.String("arch", generator.DefaultArch, "Arsitektur target (amd64, arm64, 386)")
+	var stubDirs stringList
+	fs.Var(&stubDirs, "stub-dir", "Direktori stub tambahan (bisa diulang)")
 	icon := fs.String("icon", "", "Path/URL ke icon file (.ico, .png, .jpg)")
 	iconShort := fs.String("i", "", "Path/URL ke icon file - shorthand")
 	autoIcon := fs.Bool("auto-icon", false, "Auto-fetch favicon dari URL target")
@@ -58,13 +79,33 @@ func createCmd(args []string) {
 	alwaysOnTop := fs.Bool("always-on-top", false, "Window selalu di atas")
 	maximized := fs.Bool("maximized", false, "Start dalam kondisi maximized")
 	titleBarColor := fs.String("titlebar-color", "", "Warna titlebar (hex: #1a1a2e atau dark/light)")
+	monitor := fs.String("monitor", "", "Monitor window: nomor (mulai 1), primary, atau largest")
+	var x, y optionalInt
+	fs.Var(&x, "x", "Posisi X window dari kiri area kerja monitor")
+	fs.Var(&y, "y", "Posisi Y window dari atas area kerja monitor")
+	position := fs.String("position", "", "Posisi window di monitor: center, top-left, top-right, ...")
+	fullscreenMonitor := fs.String("fullscreen-monitor", "", "Fullscreen di monitor ini: nomor (mulai 1), primary, atau largest")
 
 	// Behavior
 	singleInstance := fs.Bool("single-instance", false, "Hanya boleh 1 instance berjalan")
 	userAgent := fs.String("user-agent", "", "Custom User-Agent string")
 	clearCache := fs.Bool("clear-cache", false, "Hapus cache saat exit")
+	clearOnExit := fs.String("clear-on-exit", "", "Data yang dihapus saat exit: cache, cookies, storage (comma-separated)")
+	dataDir := fs.String("data-dir", "", "Direktori data app (default: %LOCALAPPDATA%\\<app-id>)")
+	portable := fs.Bool("portable", false, "Simpan data di sebelah exe")
+	enableProfiles := fs.Bool("enable-profiles", false, "Tampilkan menu profil di tray")
+	profiles := fs.String("profiles", "", "Profil yang tersedia di menu tray (comma-separated)")
 	enableNotification := fs.Bool("enable-notification", false, "Enable push notifications")
 
+	// Downloads
+	downloadDir := fs.String("download-dir", "", "Folder tujuan unduhan (default: folder Downloads user)")
+	downloadMode := fs.String("download-mode", "", "auto: langsung simpan, ask: selalu tanya lokasi")
+	downloadTypes := fs.String("download-types", "", "Ekstensi file yang boleh diunduh (comma-separated)")
+
+	// Permissions
+	var permissions stringList
+	fs.Var(&permissions, "permission", "Izin halaman jenis[@pola]=allow|deny|prompt (bisa diulang)")
+
 	// System Tray
 	enableTray := fs.Bool("tray", false, "Enable system tray icon")
 	minimizeToTray := fs.Bool("minimize-to-tray", false, "Minimize to tray instead of taskbar")
@@ -77,25 +118,50 @@ func createCmd(args []string) {
 	injectJS := fs.String("inject-js", "", "JavaScript string untuk di-inject")
 	injectCSSFile := fs.String("css-file", "", "Path ke file CSS untuk di-inject")
 	injectJSFile := fs.String("js-file", "", "Path ke file JS untuk di-inject")
+	userScriptDir := fs.String("userscripts", "", "Direktori berisi userscript .user.js")
 
 	// Navigation
 	whitelist := fs.String("whitelist", "", "Domain whitelist (comma-separated)")
 	blockExternal := fs.Bool("block-external", false, "Block navigasi ke external URL")
+	var navRules stringList
+	fs.Var(&navRules, "nav-rule", "Aturan navigasi pola=allow|external|block (bisa diulang)")
+	popupPolicy := fs.String("popup", "", "Window baru (window.open, target=_blank): same, window, browser, block")
+	authDomains := fs.String("auth-domains", "", "Domain login OAuth yang dibuka di window kedua (comma-separated)")
+	var deepLinks stringList
+	fs.Var(&deepLinks, "deep-link", "Deep link pola=target URL di dalam app (bisa diulang)")
+	var protocols stringList
+	fs.Var(&protocols, "protocol", "Scheme URL yang diklaim app, scheme=target (bisa diulang)")
+
+	// Network
+	var headers stringList
+	fs.Var(&headers, "header", "Header tambahan pola=Nama: nilai (bisa diulang)")
+	var blockURLs stringList
+	fs.Var(&blockURLs, "block-url", "Pola URL yang diblokir (bisa diulang)")
+	var filterLists stringList
+	fs.Var(&filterLists, "filter-list", "File filter list Adblock yang di-embed (bisa diulang)")
 
 	// Advanced
 	disableContextMenu := fs.Bool("no-context-menu", false, "Disable klik kanan")
 	disableDevTools := fs.Bool("no-devtools", false, "Disable DevTools (F12)")
 
+	// Code signing
+	signCert := fs.String("sign-cert", "", "Path ke file PFX untuk Authenticode signing")
+	signPassEnv := fs.String("sign-pass-env", "", "Nama environment variable berisi password PFX")
+	timestampURL := fs.String("timestamp-url", "", "URL server timestamp RFC 3161")
+
 	fs.Usage = func() {
 		fmt.Println("Usage: w2app create [options]")
 		fmt.Println("\nOptions:")
 		fmt.Println("\n  BASIC:")
 		fmt.Println("    --url, -u          URL target (wajib)")
 		fmt.Println("    --name, -n         Nama aplikasi (wajib)")
+		fmt.Println("    --app-id           Identitas tetap app, mis. com.example.chat (default: dibuat otomatis)")
 		fmt.Println("    --icon, -i         Path/URL ke icon file (.ico, .png, .jpg)")
 		fmt.Println("    --auto-icon        Auto-fetch favicon dari URL target")
 		fmt.Println("    --out, -o          Direktori output (default: .)")
 		fmt.Println("    --platform, -p     Platform target (default: windows)")
+		fmt.Println("    --arch             Arsitektur target: " + strings.Join(generator.Archs, ", ") + " (default: " + generator.DefaultArch + ")")
+		fmt.Println("    --stub-dir         Direktori stub tambahan, dicari sebelum " + generator.StubPathEnv + " (bisa diulang)")
 		fmt.Println("\n  WINDOW:")
 		fmt.Println("    --width            Lebar window (default: 1024)")
 		fmt.Println("    --height           Tinggi window (default: 768)")
@@ -105,11 +171,28 @@ func createCmd(args []string) {
 		fmt.Println("    --always-on-top    Window selalu di atas")
 		fmt.Println("    --maximized        Start dalam kondisi maximized")
 		fmt.Println("    --titlebar-color   Warna titlebar (hex: #1a1a2e atau dark/light)")
+		fmt.Println("    --monitor          Monitor tempat window dibuka: nomor (mulai 1, kiri ke kanan), primary, atau largest")
+		fmt.Println("    --x, --y           Posisi window (pixel) dari pojok kiri atas area kerja monitor")
+		fmt.Println("    --position         Posisi window: " + strings.Join(winstate.Positions, ", "))
+		fmt.Println("    --fullscreen-monitor Fullscreen di monitor ini (format sama dengan --monitor)")
 		fmt.Println("\n  BEHAVIOR:")
 		fmt.Println("    --single-instance    Hanya boleh 1 instance berjalan")
 		fmt.Println("    --user-agent         Custom User-Agent string")
 		fmt.Println("    --clear-cache        Hapus cache saat exit")
+		fmt.Println("    --clear-on-exit      Data yang dihapus saat exit: cache, cookies, storage (comma-separated)")
+		fmt.Println("    --data-dir           Direktori data app, boleh pakai %VAR% (default: %LOCALAPPDATA%\\<app-id>)")
+		fmt.Println("    --portable           Simpan data di folder <nama>-data di sebelah exe")
+		fmt.Println("    --enable-profiles    Menu profil di tray untuk beberapa akun (butuh --tray)")
+		fmt.Println("    --profiles           Profil yang langsung tersedia di menu (comma-separated)")
 		fmt.Println("    --enable-notification Enable push notifications (Windows toast)")
+		fmt.Println("\n  DOWNLOADS:")
+		fmt.Println("    --download-dir       Folder tujuan unduhan, boleh pakai %VAR% (default: folder Downloads user)")
+		fmt.Println("    --download-mode      auto (langsung simpan) atau ask (selalu tanya lokasi)")
+		fmt.Println("    --download-types     Ekstensi file yang boleh diunduh, e.g. .pdf,.csv (default: semua)")
+		fmt.Println("\n  PERMISSIONS:")
+		fmt.Println("    --permission         Izin halaman jenis[@pola]=allow|deny|prompt (bisa diulang)")
+		fmt.Println("                         Jenis: camera, microphone, geolocation, clipboard, notifications, multiple_downloads")
+		fmt.Println("                         e.g. camera=prompt, microphone@meet.example.com=allow")
 		fmt.Println("\n  SYSTEM TRAY:")
 		fmt.Println("    --tray               Enable system tray icon")
 		fmt.Println("    --minimize-to-tray   Minimize to tray instead of taskbar")
@@ -121,12 +204,29 @@ func createCmd(args []string) {
 		fmt.Println("    --inject-js        JavaScript string untuk di-inject")
 		fmt.Println("    --css-file         Path ke file CSS untuk di-inject")
 		fmt.Println("    --js-file          Path ke file JS untuk di-inject")
+		fmt.Println("    --userscripts      Direktori berisi userscript .user.js (@match, @exclude, @run-at)")
 		fmt.Println("\n  NAVIGATION:")
 		fmt.Println("    --whitelist        Domain whitelist (comma-separated)")
 		fmt.Println("    --block-external   Block navigasi ke external URL")
+		fmt.Println("    --nav-rule         Aturan navigasi pola=allow|external|block (bisa diulang)")
+		fmt.Println("    --popup            Window baru (window.open, target=_blank): same (default), window, browser, block")
+		fmt.Println("    --auth-domains     Domain login OAuth yang dibuka di window kedua dan kembali ke app (comma-separated)")
+		fmt.Println("    --deep-link        URL dari argumen/custom scheme yang dibuka di app: pola=target (bisa diulang)")
+		fmt.Println("                       Target boleh memakai {url} {scheme} {host} {path} {query} {fragment}")
+		fmt.Println("    --protocol         Scheme URL yang didaftarkan untuk app, scheme=target (bisa diulang)")
+		fmt.Println("                       e.g. web+ourtool=https://app.example.com/open?uri={url}")
+		fmt.Println("\n  NETWORK:")
+		fmt.Println("    --header           Header tambahan untuk request yang cocok: pola=Nama: nilai (bisa diulang)")
+		fmt.Println("                       e.g. api.example.com=X-Tenant: acme")
+		fmt.Println("    --block-url        Pola URL yang diblokir, format seperti --nav-rule (bisa diulang)")
+		fmt.Println("    --filter-list      File filter list Adblock (EasyList, ...) yang di-embed saat build (bisa diulang)")
 		fmt.Println("\n  ADVANCED:")
 		fmt.Println("    --no-context-menu  Disable klik kanan")
 		fmt.Println("    --no-devtools      Disable DevTools (F12)")
+		fmt.Println("\n  CODE SIGNING (Windows):")
+		fmt.Println("    --sign-cert        Path ke file PFX untuk Authenticode signing")
+		fmt.Println("    --sign-pass-env    Nama environment variable berisi password PFX")
+		fmt.Println("    --timestamp-url    URL server timestamp RFC 3161 (mis. http://timestamp.digicert.com)")
 		fmt.Println("\nKeyboard Shortcuts (dalam app):")
 		fmt.Println("    F11                Toggle fullscreen")
 		fmt.Println("    F5 / Ctrl+R        Refresh")
@@ -139,6 +239,8 @@ func createCmd(args []string) {
 		fmt.Println("  w2app --url https://app.slack.com --name Slack --icon slack.png")
 		fmt.Println("  w2app -u https://web.whatsapp.com -n WhatsApp --single-instance --auto-icon")
 		fmt.Println("  w2app -u https://web.whatsapp.com -n WhatsApp --tray --close-to-tray --auto-icon")
+		fmt.Println("  w2app -u https://github.com -n GitHub --sign-cert cert.pfx --sign-pass-env PFX_PASS")
+		fmt.Println("  w2app -u https://kiosk.example.com -n Kiosk --fullscreen-monitor 2")
 	}
 
 	if err := fs.Parse(args); err != nil {
@@ -186,23 +288,95 @@ func createCmd(args []string) {
 		os.Exit(1)
 	}
 
-	// Parse whitelist
-	var whitelistDomains []string
-	if *whitelist != "" {
-		for _, domain := range strings.Split(*whitelist, ",") {
-			domain = strings.TrimSpace(domain)
-			if domain != "" {
-				whitelistDomains = append(whitelistDomains, domain)
-			}
+	// Parse nav rules (pola=action)
+	var rules []config.NavRule
+	for _, r := range navRules {
+		idx := strings.LastIndex(r, "=")
+		if idx <= 0 {
+			fmt.Printf("Error: --nav-rule %q harus berformat pola=action\n", r)
+			os.Exit(1)
 		}
+		rules = append(rules, config.NavRule{
+			Pattern: strings.TrimSpace(r[:idx]),
+			Action:  strings.TrimSpace(r[idx+1:]),
+		})
+	}
+
+	// Parse permissions (jenis[@pola]=action)
+	var perms map[string]string
+	for _, p := range permissions {
+		idx := strings.LastIndex(p, "=")
+		if idx <= 0 {
+			fmt.Printf("Error: --permission %q harus berformat jenis[@pola]=action\n", p)
+			os.Exit(1)
+		}
+		if perms == nil {
+			perms = map[string]string{}
+		}
+		perms[strings.TrimSpace(p[:idx])] = strings.TrimSpace(p[idx+1:])
+	}
+
+	// Parse headers (pola=Nama: nilai); header dengan pola yang sama digabung
+	var headerRules []config.HeaderRule
+	for _, h := range headers {
+		pattern, header, _ := strings.Cut(h, "=")
+		name, value, found := strings.Cut(header, ":")
+		if !found || strings.TrimSpace(pattern) == "" || strings.TrimSpace(name) == "" {
+			fmt.Printf("Error: --header %q harus berformat pola=Nama: nilai\n", h)
+			os.Exit(1)
+		}
+		pattern = strings.TrimSpace(pattern)
+		idx := slices.IndexFunc(headerRules, func(r config.HeaderRule) bool { return r.Pattern == pattern })
+		if idx < 0 {
+			headerRules = append(headerRules, config.HeaderRule{Pattern: pattern, Headers: map[string]string{}})
+			idx = len(headerRules) - 1
+		}
+		headerRules[idx].Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	// Parse deep links (pola=target, target boleh kosong)
+	var links []config.DeepLink
+	for _, l := range deepLinks {
+		pattern, target, found := strings.Cut(l, "=")
+		if !found || strings.TrimSpace(pattern) == "" {
+			fmt.Printf("Error: --deep-link %q harus berformat pola=target\n", l)
+			os.Exit(1)
+		}
+		links = append(links, config.DeepLink{
+			Pattern: strings.TrimSpace(pattern),
+			Target:  strings.TrimSpace(target),
+		})
+	}
+
+	// Parse protocols (scheme=target)
+	var schemes []config.Protocol
+	for _, p := range protocols {
+		scheme, target, found := strings.Cut(p, "=")
+		if !found || strings.TrimSpace(scheme) == "" || strings.TrimSpace(target) == "" {
+			fmt.Printf("Error: --protocol %q harus berformat scheme=target\n", p)
+			os.Exit(1)
+		}
+		schemes = append(schemes, config.Protocol{
+			Scheme: strings.TrimSpace(scheme),
+			Target: strings.TrimSpace(target),
+		})
+	}
+
+	signPassword, err := signPassword(*signCert, *signPassEnv)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
 	}
 
 	// Generate aplikasi
 	opts := generator.Options{
 		URL:                finalURL,
 		Name:               finalName,
+		AppID:              *appID,
 		Output:             finalOutput,
 		Platform:           strings.ToLower(finalPlatform),
+		Arch:               strings.ToLower(*arch),
+		StubDirs:           stubDirs,
 		Icon:               finalIcon,
 		AutoIcon:           *autoIcon,
 		Width:              *width,
@@ -213,10 +387,24 @@ func createCmd(args []string) {
 		AlwaysOnTop:        *alwaysOnTop,
 		StartMaximized:     *maximized,
 		TitleBarColor:      *titleBarColor,
+		Monitor:            *monitor,
+		X:                  x.value,
+		Y:                  y.value,
+		Position:           *position,
+		FullscreenMonitor:  *fullscreenMonitor,
 		SingleInstance:     *singleInstance,
 		UserAgent:          *userAgent,
 		ClearCacheOnExit:   *clearCache,
+		DataDir:            *dataDir,
+		Portable:           *portable,
+		ClearOnExit:        splitList(*clearOnExit),
+		EnableProfiles:     *enableProfiles,
+		Profiles:           splitList(*profiles),
 		EnableNotification: *enableNotification,
+		DownloadFolder:     *downloadDir,
+		DownloadMode:       *downloadMode,
+		DownloadTypes:      splitList(*downloadTypes),
+		Permissions:        perms,
 		EnableTray:         *enableTray,
 		MinimizeToTray:     *minimizeToTray,
 		CloseToTray:        *closeToTray,
@@ -226,28 +414,128 @@ func createCmd(args []string) {
 		InjectJS:           *injectJS,
 		InjectCSSFile:      *injectCSSFile,
 		InjectJSFile:       *injectJSFile,
-		Whitelist:          whitelistDomains,
+		UserScriptDir:      *userScriptDir,
+		Whitelist:          splitList(*whitelist),
 		BlockExternalNav:   *blockExternal,
+		NavRules:           rules,
+		PopupPolicy:        *popupPolicy,
+		AuthDomains:        splitList(*authDomains),
+		DeepLinks:          links,
+		Protocols:          schemes,
+		Headers:            headerRules,
+		BlockURLs:          blockURLs,
+		FilterListFiles:    filterLists,
 		DisableContextMenu: *disableContextMenu,
 		DisableDevTools:    *disableDevTools,
+		SignCert:           *signCert,
+		SignPassword:       signPassword,
+		TimestampURL:       *timestampURL,
+		Logger:             newCLILogger(os.Stdout),
 	}
 
-	if err := generator.Generate(opts); err != nil {
+	// Ctrl+C membatalkan download dan build tanpa meninggalkan exe setengah jadi
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	res, err := generator.Generate(ctx, opts)
+	if err != nil {
 		fmt.Printf("Error: %v\n", err)
 		os.Exit(1)
 	}
+	printGenerateResult(os.Stdout, res)
+}
+
+// signPassword membaca password PFX dari environment variable. Password
+// sengaja tidak diterima lewat argumen supaya tidak muncul di history shell
+// atau log CI.
+func signPassword(signCert, envName string) (string, error) {
+	if envName == "" {
+		return "", nil
+	}
+	if signCert == "" {
+		return "", fmt.Errorf("--sign-pass-env membutuhkan --sign-cert")
+	}
+	pass, ok := os.LookupEnv(envName)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s tidak di-set", envName)
+	}
+	return pass, nil
 }
 
-func listPlatforms() {
-	platforms := generator.ListPlatforms()
-	if len(platforms) == 0 {
-		fmt.Println("Tidak ada platform yang tersedia.")
-		return
+// stringList adalah flag yang bisa diulang beberapa kali
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// splitList memecah daftar yang dipisah koma dan membuang item kosong
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
 	}
+	return items
+}
 
-	fmt.Println("Platform yang tersedia:")
-	for _, p := range platforms {
-		fmt.Printf("  - %s\n", p)
+// optionalInt adalah flag angka yang membedakan "tidak diisi" dari 0
+type optionalInt struct {
+	value *int
+}
+
+func (o *optionalInt) String() string {
+	if o.value == nil {
+		return ""
+	}
+	return strconv.Itoa(*o.value)
+}
+
+func (o *optionalInt) Set(value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("%q bukan angka", value)
+	}
+	o.value = &n
+	return nil
+}
+
+func verifyCmd(args []string) {
+	if len(args) != 1 || strings.HasPrefix(args[0], "-") {
+		fmt.Println("Usage: w2app verify <app.exe>")
+		os.Exit(1)
+	}
+	path := args[0]
+
+	sig, err := authenticode.VerifyFile(path)
+	if err != nil {
+		fmt.Printf("✗ Signature tidak valid: %s\n", path)
+		fmt.Printf("  %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✓ Signature valid: %s\n", path)
+	fmt.Printf("  Signer    : %s\n", sig.Signer.Subject)
+	fmt.Printf("  Issuer    : %s\n", sig.Signer.Issuer)
+	fmt.Printf("  Serial    : %X\n", sig.Signer.SerialNumber)
+	fmt.Printf("  Berlaku   : %s s/d %s\n",
+		sig.Signer.NotBefore.Format("2006-01-02"), sig.Signer.NotAfter.Format("2006-01-02"))
+	fmt.Printf("  Digest    : %s\n", sig.DigestAlgorithm)
+	if sig.Timestamp.IsZero() {
+		fmt.Println("  Timestamp : tidak ada")
+	} else {
+		fmt.Printf("  Timestamp : %s (%s)\n", sig.Timestamp.UTC().Format("2006-01-02 15:04:05 MST"), sig.TimestampSigner)
+	}
+	if sig.ChainErr != nil {
+		fmt.Printf("  Trust     : tidak dipercaya sistem (%v)\n", sig.ChainErr)
+	} else {
+		fmt.Println("  Trust     : dipercaya sistem")
 	}
 }
 
@@ -259,10 +547,15 @@ func printUsage() {
 	fmt.Println("Usage: w2app <command> [options]")
 	fmt.Println()
 	fmt.Println("Commands:")
-	fmt.Println("  create     Buat aplikasi desktop dari URL")
-	fmt.Println("  platforms  Tampilkan daftar platform yang tersedia")
-	fmt.Println("  version    Tampilkan versi aplikasi")
-	fmt.Println("  help       Tampilkan bantuan")
+	fmt.Println("  create      Buat aplikasi desktop dari URL")
+	fmt.Println("  build       Build semua aplikasi dari file manifest (w2app.json)")
+	fmt.Println("  inspect     Tampilkan config dan metadata aplikasi yang sudah dibuat")
+	fmt.Println("  reconfigure Ubah config aplikasi yang sudah dibuat tanpa build ulang")
+	fmt.Println("  upgrade     Pindahkan aplikasi yang sudah dibuat ke stub terbaru")
+	fmt.Println("  verify      Cek signature Authenticode aplikasi")
+	fmt.Println("  platforms   Tampilkan daftar platform yang tersedia")
+	fmt.Println("  version     Tampilkan versi aplikasi")
+	fmt.Println("  help        Tampilkan bantuan")
 	fmt.Println()
 	fmt.Println("Quick Usage:")
 	fmt.Println("  w2app --url <URL> --name <AppName>")
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/user/w2app/internal/generator"
+)
+
+func platformsCmd(args []string) {
+	fs := flag.NewFlagSet("platforms", flag.ExitOnError)
+	var stubDirs stringList
+	fs.Var(&stubDirs, "stub-dir", "Direktori stub tambahan (bisa diulang)")
+	writeManifest := fs.String("write-manifest", "", "Buat manifest.json untuk stub di direktori ini")
+	stubVersion := fs.String("stub-version", "", "Versi stub untuk --write-manifest")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: w2app platforms [--stub-dir <dir>]...")
+		fmt.Println("       w2app platforms --write-manifest <dir> [--stub-version <versi>]")
+		fmt.Println("\nTampilkan stub yang dipakai untuk setiap platform dan arsitektur.")
+		fmt.Println("Stub dicari di --stub-dir, lalu " + generator.StubPathEnv + ", lalu stub bawaan w2app.")
+		fmt.Println("\nOptions:")
+		fmt.Println("    --stub-dir         Direktori stub tambahan (bisa diulang)")
+		fmt.Println("    --write-manifest   Buat " + generator.StubManifestName + " untuk file stub-<platform>-<arch> di direktori")
+		fmt.Println("    --stub-version     Versi stub untuk --write-manifest (default: " + generator.StubVersion + ")")
+		fmt.Println("\nExamples:")
+		fmt.Println("  w2app platforms")
+		fmt.Println("  w2app platforms --write-manifest ./stubs --stub-version 1.2.1")
+		fmt.Println("  w2app create --url https://example.com --name Demo --stub-dir ./stubs --arch arm64")
+	}
+
+	if rest := parseInterspersed(fs, args); len(rest) > 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	if *writeManifest != "" {
+		m, err := generator.WriteStubManifest(*writeManifest, *stubVersion)
+		if err != nil {
+			fmt.Printf("Error: %v\n", err)
+			os.Exit(1)
+		}
+		fmt.Printf("✓ Manifest ditulis: %d stub\n", len(m.Stubs))
+		return
+	}
+
+	stubs, err := generator.ListStubs(stubDirs)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+	if len(stubs) == 0 {
+		fmt.Println("Tidak ada platform yang tersedia.")
+		return
+	}
+
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PLATFORM\tARCH\tVERSION\tSCHEMA\tSHA-256\tSTATUS\tSOURCE")
+	failed := false
+	for _, s := range stubs {
+		status := "OK"
+		switch {
+		case s.Err != nil:
+			status = "GAGAL"
+			failed = true
+		case !s.Verified:
+			status = "tanpa manifest"
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%d\t%s\t%s\t%s\n", s.Platform, s.Arch, s.Version, s.Schema, s.SHA256, status, s.Source)
+	}
+	tw.Flush()
+
+	for _, s := range stubs {
+		if s.Err != nil {
+			fmt.Printf("\nError %s/%s: %v", s.Platform, s.Arch, s.Err)
+		}
+	}
+	if failed {
+		fmt.Println()
+		os.Exit(1)
+	}
+}
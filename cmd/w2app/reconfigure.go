@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/user/w2app/internal/config"
+	"github.com/user/w2app/internal/generator"
+)
+
+func reconfigureCmd(args []string) {
+	fs := flag.NewFlagSet("reconfigure", flag.ExitOnError)
+	var sets, unsets stringList
+	fs.Var(&sets, "set", "Ubah field config: key=value (bisa diulang)")
+	fs.Var(&unsets, "unset", "Kosongkan field config (bisa diulang)")
+	signCert := fs.String("sign-cert", "", "Sign ulang dengan file PFX")
+	signPassEnv := fs.String("sign-pass-env", "", "Nama environment variable berisi password PFX")
+	timestampURL := fs.String("timestamp-url", "", "URL server timestamp RFC 3161")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: w2app reconfigure <app.exe> --set key=value [--unset key]")
+		fmt.Println("\nOptions:")
+		fmt.Println("    --set              Ubah field config: key=value (bisa diulang)")
+		fmt.Println("    --unset            Kosongkan field config (bisa diulang)")
+		fmt.Println("    --sign-cert        Sign ulang dengan file PFX")
+		fmt.Println("    --sign-pass-env    Nama environment variable berisi password PFX")
+		fmt.Println("    --timestamp-url    URL server timestamp RFC 3161")
+		fmt.Println("\nKey memakai nama field JSON (lihat 'w2app inspect'):")
+		fmt.Println("    " + strings.Join(config.Keys(), ", "))
+		fmt.Println("\nExamples:")
+		fmt.Println("  w2app reconfigure WhatsApp.exe --set url=https://web.whatsapp.com --set enable_tray=true")
+		fmt.Println("  w2app reconfigure App.exe --set whitelist=a.com,b.com --unset user_agent")
+	}
+
+	rest := parseInterspersed(fs, args)
+	if len(rest) != 1 || len(sets)+len(unsets) == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	path := rest[0]
+
+	var changes []config.Change
+	for _, s := range sets {
+		key, value, ok := strings.Cut(s, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			fmt.Printf("Error: --set %q harus berformat key=value\n", s)
+			os.Exit(1)
+		}
+		changes = append(changes, config.Change{Key: strings.TrimSpace(key), Value: value})
+	}
+	for _, key := range unsets {
+		changes = append(changes, config.Change{Key: strings.TrimSpace(key), Unset: true})
+	}
+
+	signPassword, err := signPassword(*signCert, *signPassEnv)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	result, err := generator.Reconfigure(path, generator.ReconfigureOptions{
+		Changes:      changes,
+		SignCert:     *signCert,
+		SignPassword: signPassword,
+		TimestampURL: *timestampURL,
+	})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("✓ Config diperbarui: %s\n", path)
+	printConfigDiff(result.Old, result.New)
+	if result.Signed {
+		fmt.Println("  Signature : di-sign ulang")
+	}
+	if result.SignatureRemoved {
+		fmt.Println("  Warning: signature lama dihapus karena tidak valid lagi, sign ulang dengan --sign-cert")
+	}
+}
+
+// printConfigDiff menampilkan field yang nilainya berubah
+func printConfigDiff(old, new *config.AppConfig) {
+	before := configFields(old)
+	after := configFields(new)
+	changed := false
+	for _, key := range config.Keys() {
+		if before[key] == after[key] {
+			continue
+		}
+		changed = true
+		fmt.Printf("  %-20s %s -> %s\n", key, before[key], after[key])
+	}
+	if !changed {
+		fmt.Println("  (tidak ada perubahan)")
+	}
+}
+
+func configFields(cfg *config.AppConfig) map[string]string {
+	data, _ := json.Marshal(cfg)
+	raw := map[string]json.RawMessage{}
+	json.Unmarshal(data, &raw)
+
+	fields := map[string]string{}
+	for _, key := range config.Keys() {
+		value := string(raw[key])
+		if value == "" {
+			value = "-"
+		}
+		fields[key] = value
+	}
+	return fields
+}
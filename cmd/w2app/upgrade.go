@@ -0,0 +1,86 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/user/w2app/internal/generator"
+)
+
+func upgradeCmd(args []string) {
+	fs := flag.NewFlagSet("upgrade", flag.ExitOnError)
+	output := fs.String("out", "", "File atau direktori hasil upgrade (default: timpa app asli)")
+	outputShort := fs.String("o", "", "File atau direktori hasil upgrade - shorthand")
+	signCert := fs.String("sign-cert", "", "Sign hasil upgrade dengan file PFX")
+	signPassEnv := fs.String("sign-pass-env", "", "Nama environment variable berisi password PFX")
+	timestampURL := fs.String("timestamp-url", "", "URL server timestamp RFC 3161")
+	var stubDirs stringList
+	fs.Var(&stubDirs, "stub-dir", "Direktori stub tambahan (bisa diulang)")
+
+	fs.Usage = func() {
+		fmt.Println("Usage: w2app upgrade <app.exe> [--out <path>]")
+		fmt.Println("\nBuat ulang app dengan stub terbaru. Config, icon, dan version info dipertahankan.")
+		fmt.Println("\nOptions:")
+		fmt.Println("    -o, --out          File atau direktori hasil upgrade (default: timpa app asli)")
+		fmt.Println("    --sign-cert        Sign hasil upgrade dengan file PFX")
+		fmt.Println("    --sign-pass-env    Nama environment variable berisi password PFX")
+		fmt.Println("    --timestamp-url    URL server timestamp RFC 3161")
+		fmt.Println("    --stub-dir         Direktori stub tambahan, dicari sebelum " + generator.StubPathEnv + " (bisa diulang)")
+		fmt.Println("\nExamples:")
+		fmt.Println("  w2app upgrade WhatsApp.exe")
+		fmt.Println("  w2app upgrade WhatsApp.exe --out dist/")
+	}
+
+	rest := parseInterspersed(fs, args)
+	if len(rest) != 1 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	path := rest[0]
+
+	if *outputShort != "" {
+		*output = *outputShort
+	}
+
+	signPassword, err := signPassword(*signCert, *signPassEnv)
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	result, err := generator.Upgrade(path, generator.UpgradeOptions{
+		Output:       *output,
+		StubDirs:     stubDirs,
+		SignCert:     *signCert,
+		SignPassword: signPassword,
+		TimestampURL: *timestampURL,
+	})
+	if err != nil {
+		fmt.Printf("Error: %v\n", err)
+		os.Exit(1)
+	}
+
+	oldStub := result.OldStubVersion
+	if oldStub == "" {
+		oldStub = "tidak diketahui"
+	}
+
+	fmt.Printf("✓ Berhasil upgrade: %s\n", result.Output)
+	fmt.Printf("  Stub      : %s -> %s (%s/%s)\n", oldStub, result.NewStubVersion, result.Platform, result.Arch)
+	if result.OldSchema != result.NewSchema {
+		fmt.Printf("  Config    : skema %d -> %d\n", result.OldSchema, result.NewSchema)
+	}
+	if result.OldStorage != result.NewStorage {
+		fmt.Printf("  Storage   : %s -> %s\n", result.OldStorage, result.NewStorage)
+	}
+	if result.IconKept {
+		fmt.Println("  Icon      : dipertahankan")
+	}
+	if result.Signed {
+		fmt.Println("  Signature : di-sign ulang")
+	}
+	if result.SignatureRemoved {
+		fmt.Println("  Warning: app lama di-sign, hasil upgrade belum di-sign. Sign ulang dengan --sign-cert")
+	}
+}
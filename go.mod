@@ -5,6 +5,7 @@ go 1.25.5
 require (
 	github.com/jchv/go-webview2 v0.0.0-20250406165304-0bcfea011047
 	github.com/tc-hib/winres v0.3.1
+	go.yaml.in/yaml/v3 v3.0.4
 )
 
 require (
@@ -0,0 +1,257 @@
+// Package appdata menentukan direktori data app (profil WebView2 dan state
+// window) dan menghapus bagian data yang diminta saat app ditutup. Semua path
+// dihitung dari Env, jadi logikanya tidak bergantung pada Windows.
+package appdata
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// Env adalah lingkungan tempat app dijalankan
+type Env struct {
+	Exe          string              // Path lengkap exe app
+	LocalAppData string              // %LOCALAPPDATA%
+	AppData      string              // %APPDATA%, lokasi data app lama tanpa app ID
+	Getenv       func(string) string // Untuk %VAR% di path dari config (nil = os.Getenv)
+}
+
+// PortableSuffix ditambahkan ke nama exe (tanpa .exe) untuk direktori data
+// mode portable, mis. "WhatsApp-data" di sebelah WhatsApp.exe
+const PortableSuffix = "-data"
+
+// Dir menentukan direktori data app, berurutan:
+//   - dataDir jika diisi. %VAR% diganti nilai environment variable, dan path
+//     relatif dihitung dari direktori exe.
+//   - portable: direktori <nama exe>-data di sebelah exe.
+//   - %LOCALAPPDATA%\<appID>.
+//   - App lama tanpa app ID tetap memakai default WebView2, %APPDATA%\<nama exe>,
+//     supaya login dan cookie tidak hilang setelah upgrade.
+func Dir(dataDir string, portable bool, appID string, env Env) (string, error) {
+	exeDir := filepath.Dir(env.Exe)
+
+	switch {
+	case dataDir != "":
+		dir, err := ResolvePath(dataDir, env)
+		if err != nil {
+			return "", fmt.Errorf("data_dir: %w", err)
+		}
+		return dir, nil
+
+	case portable:
+		name := strings.TrimSuffix(filepath.Base(env.Exe), filepath.Ext(env.Exe))
+		return filepath.Join(exeDir, name+PortableSuffix), nil
+
+	case appID != "":
+		if env.LocalAppData == "" {
+			return "", fmt.Errorf("LOCALAPPDATA tidak di-set")
+		}
+		return filepath.Join(env.LocalAppData, appID), nil
+	}
+
+	if env.AppData == "" {
+		return "", fmt.Errorf("APPDATA tidak di-set")
+	}
+	return filepath.Join(env.AppData, filepath.Base(env.Exe)), nil
+}
+
+// ResolvePath mengubah path dari config menjadi path absolut: %VAR% diganti
+// nilai environment variable, dan path relatif dihitung dari direktori exe
+func ResolvePath(path string, env Env) (string, error) {
+	path, err := expand(path, env.Getenv)
+	if err != nil {
+		return "", err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(env.Exe), path)
+	}
+	return filepath.Clean(path), nil
+}
+
+// expand mengganti %VAR% dengan nilai environment variable. Variabel yang
+// tidak ada adalah error, supaya data tidak diam-diam ditulis ke path yang salah.
+func expand(path string, getenv func(string) string) (string, error) {
+	if getenv == nil {
+		getenv = os.Getenv
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(path, '%')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(path[start+1:], '%')
+		if end < 0 {
+			break
+		}
+		name := path[start+1 : start+1+end]
+		value := getenv(name)
+		if name == "" || value == "" {
+			return "", fmt.Errorf("environment variable %%%s%% tidak ada", name)
+		}
+		b.WriteString(path[:start])
+		b.WriteString(value)
+		path = path[start+end+2:]
+	}
+	b.WriteString(path)
+	return b.String(), nil
+}
+
+// profilesDir berisi direktori data profil bernama. Profil default memakai
+// direktori data app itu sendiri, jadi app yang mulai memakai profil tidak
+// kehilangan data lamanya.
+const profilesDir = "profiles"
+
+// MaxProfileNameLength adalah panjang maksimal nama profil
+const MaxProfileNameLength = 64
+
+// CheckProfileName mengecek nama profil. Nama dipakai sebagai nama direktori,
+// jadi hanya huruf, angka, spasi, titik, strip, dan underscore yang boleh.
+func CheckProfileName(name string) error {
+	if strings.TrimSpace(name) == "" {
+		return fmt.Errorf("nama profil tidak boleh kosong")
+	}
+	if len(name) > MaxProfileNameLength {
+		return fmt.Errorf("nama profil maksimal %d karakter", MaxProfileNameLength)
+	}
+	if name != strings.TrimSpace(name) || strings.Trim(name, ".") == "" || strings.HasSuffix(name, ".") {
+		return fmt.Errorf("nama profil %q tidak valid", name)
+	}
+	for _, r := range name {
+		alnum := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+		if !alnum && !strings.ContainsRune(" .-_", r) {
+			return fmt.Errorf("nama profil %q hanya boleh berisi huruf, angka, spasi, titik, strip, dan underscore", name)
+		}
+	}
+	return nil
+}
+
+// ProfileDir mengembalikan direktori data profil di direktori data app dir.
+// Profil kosong adalah profil default.
+func ProfileDir(dir, profile string) string {
+	if profile == "" {
+		return dir
+	}
+	return filepath.Join(dir, profilesDir, profile)
+}
+
+// Profiles mengembalikan profil bernama yang sudah punya direktori data,
+// terurut tanpa membedakan huruf besar/kecil
+func Profiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(dir, profilesDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if e.IsDir() && CheckProfileName(e.Name()) == nil {
+			names = append(names, e.Name())
+		}
+	}
+	slices.SortFunc(names, func(a, b string) int {
+		return strings.Compare(strings.ToLower(a), strings.ToLower(b))
+	})
+	return names, nil
+}
+
+// Clear targets yang bisa dipilih untuk clear_on_exit
+const (
+	ClearCache   = "cache"
+	ClearCookies = "cookies"
+	ClearStorage = "storage"
+)
+
+// ClearTargets adalah semua nilai clear_on_exit yang didukung
+var ClearTargets = []string{ClearCache, ClearCookies, ClearStorage}
+
+// CheckClearTargets mengecek nilai clear_on_exit
+func CheckClearTargets(targets []string) error {
+	for _, t := range targets {
+		if !slices.Contains(ClearTargets, t) {
+			return fmt.Errorf("clear_on_exit %q tidak dikenal (pilih: %s)", t, strings.Join(ClearTargets, ", "))
+		}
+	}
+	return nil
+}
+
+// profileDir adalah profil WebView2 di dalam direktori data
+var profileDir = filepath.Join("EBWebView", "Default")
+
+// clearPaths adalah isi profil WebView2 untuk setiap target, relatif terhadap
+// profileDir
+var clearPaths = map[string][]string{
+	ClearCache: {
+		"Cache", "Code Cache", "GPUCache", "DawnCache", "DawnGraphiteCache",
+		"../GrShaderCache", "../ShaderCache", "../GraphiteDawnCache",
+	},
+	ClearCookies: {
+		"Cookies", "Cookies-journal",
+		"Network/Cookies", "Network/Cookies-journal",
+	},
+	ClearStorage: {
+		"Local Storage", "Session Storage", "IndexedDB", "databases",
+		"Service Worker", "File System", "blob_storage", "WebStorage",
+	},
+}
+
+// ClearPaths mengembalikan file dan direktori di direktori data dir yang
+// dihapus untuk targets
+func ClearPaths(dir string, targets []string) []string {
+	base := filepath.Join(dir, profileDir)
+
+	var paths []string
+	for _, t := range ClearTargets {
+		if !slices.Contains(targets, t) {
+			continue
+		}
+		for _, p := range clearPaths[t] {
+			paths = append(paths, filepath.Join(base, filepath.FromSlash(p)))
+		}
+	}
+	return paths
+}
+
+// Clear menghapus data targets. File yang masih dikunci (browser WebView2
+// belum selesai menutup) dilaporkan sebagai error; data lain tetap dihapus.
+func Clear(dir string, targets []string) error {
+	var errs []error
+	for _, p := range ClearPaths(dir, targets) {
+		if err := os.RemoveAll(p); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// pendingFile mencatat target yang belum berhasil dihapus saat app ditutup
+const pendingFile = "clear-pending"
+
+// MarkPending mencatat targets supaya dihapus oleh ClearPending saat app
+// dijalankan lagi, sebelum WebView2 membuka profilnya
+func MarkPending(dir string, targets []string) error {
+	return os.WriteFile(filepath.Join(dir, pendingFile), []byte(strings.Join(targets, ",")), 0644)
+}
+
+// ClearPending menghapus data yang dicatat MarkPending
+func ClearPending(dir string) error {
+	path := filepath.Join(dir, pendingFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := Clear(dir, strings.Split(strings.TrimSpace(string(data)), ",")); err != nil {
+		return err
+	}
+	return os.Remove(path)
+}
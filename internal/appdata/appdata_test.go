@@ -0,0 +1,209 @@
+package appdata
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func testEnv() Env {
+	vars := map[string]string{
+		"USERPROFILE": "/home/user",
+		"DATA":        "/srv/data",
+	}
+	return Env{
+		Exe:          "/opt/apps/WhatsApp.exe",
+		LocalAppData: "/home/user/local",
+		AppData:      "/home/user/roaming",
+		Getenv:       func(name string) string { return vars[name] },
+	}
+}
+
+func TestDir(t *testing.T) {
+	tests := []struct {
+		name     string
+		dataDir  string
+		portable bool
+		appID    string
+		env      func(*Env)
+		want     string
+	}{
+		{name: "data_dir wins", dataDir: "/mnt/profile", portable: true, appID: "com.example.chat", want: "/mnt/profile"},
+		{name: "data_dir variable", dataDir: "%USERPROFILE%/chat", appID: "com.example.chat", want: "/home/user/chat"},
+		{name: "data_dir relative", dataDir: "data", want: "/opt/apps/data"},
+		{name: "data_dir parent", dataDir: "../shared/./data", want: "/opt/shared/data"},
+		{name: "portable wins over app ID", portable: true, appID: "com.example.chat", want: "/opt/apps/WhatsApp-data"},
+		{name: "portable without app ID", portable: true, want: "/opt/apps/WhatsApp-data"},
+		{name: "app ID", appID: "com.example.chat", want: "/home/user/local/com.example.chat"},
+		{name: "legacy", want: "/home/user/roaming/WhatsApp.exe"},
+		{name: "portable without LOCALAPPDATA", portable: true, appID: "com.example.chat",
+			env: func(e *Env) { e.LocalAppData = "" }, want: "/opt/apps/WhatsApp-data"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := testEnv()
+			if tt.env != nil {
+				tt.env(&env)
+			}
+			got, err := Dir(tt.dataDir, tt.portable, tt.appID, env)
+			if err != nil {
+				t.Fatalf("Dir: %v", err)
+			}
+			if got != filepath.FromSlash(tt.want) {
+				t.Errorf("Dir = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDirError(t *testing.T) {
+	env := testEnv()
+	if _, err := Dir("%MISSING%/data", false, "com.example.chat", env); err == nil || !strings.Contains(err.Error(), "data_dir") {
+		t.Errorf("variabel tidak ada: error = %v", err)
+	}
+
+	env.LocalAppData = ""
+	if _, err := Dir("", false, "com.example.chat", env); err == nil {
+		t.Error("LOCALAPPDATA kosong: error nil")
+	}
+	env.AppData = ""
+	if _, err := Dir("", false, "", env); err == nil {
+		t.Error("APPDATA kosong: error nil")
+	}
+}
+
+func TestResolvePath(t *testing.T) {
+	tests := []struct {
+		path    string
+		want    string
+		wantErr bool
+	}{
+		{path: "/abs/path", want: "/abs/path"},
+		{path: "rel", want: "/opt/apps/rel"},
+		{path: "./a/../b", want: "/opt/apps/b"},
+		{path: "%DATA%", want: "/srv/data"},
+		{path: "%DATA%/x/%USERPROFILE%", want: "/srv/data/x/home/user"},
+		{path: "%DATA%%DATA%", want: "/srv/data/srv/data"},
+		{path: "pre%DATA%", want: "/opt/apps/pre/srv/data"},
+		{path: "100%", want: "/opt/apps/100%"},
+		{path: "%MISSING%", wantErr: true},
+		{path: "%DATA%/%MISSING%/x", wantErr: true},
+		{path: "%%", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := ResolvePath(tt.path, testEnv())
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("ResolvePath(%q) = %q, want error", tt.path, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("ResolvePath(%q): %v", tt.path, err)
+			continue
+		}
+		if got != filepath.FromSlash(tt.want) {
+			t.Errorf("ResolvePath(%q) = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestExpandOSEnv(t *testing.T) {
+	t.Setenv("W2APP_TEST_DIR", "/tmp/w2app")
+	got, err := expand("%W2APP_TEST_DIR%/data", nil)
+	if err != nil || got != "/tmp/w2app/data" {
+		t.Errorf("expand = %q, %v", got, err)
+	}
+}
+
+func TestClearPaths(t *testing.T) {
+	dir := filepath.FromSlash("/data/app")
+	webview := filepath.Join(dir, "EBWebView")
+
+	all := ClearPaths(dir, ClearTargets)
+	if len(all) == 0 {
+		t.Fatal("ClearPaths kosong")
+	}
+	for _, p := range all {
+		rel, err := filepath.Rel(webview, p)
+		if err != nil || rel == "." || strings.HasPrefix(rel, "..") {
+			t.Errorf("%s berada di luar %s", p, webview)
+		}
+	}
+
+	cookies := ClearPaths(dir, []string{ClearCookies})
+	if !slices.Contains(cookies, filepath.Join(webview, "Default", "Network", "Cookies")) {
+		t.Errorf("ClearPaths(cookies) = %v", cookies)
+	}
+	for _, p := range cookies {
+		if !strings.Contains(filepath.Base(p), "Cookies") {
+			t.Errorf("ClearPaths(cookies) berisi %s", p)
+		}
+	}
+	if got := ClearPaths(dir, nil); len(got) != 0 {
+		t.Errorf("ClearPaths(nil) = %v", got)
+	}
+	if got := ClearPaths(dir, []string{"everything"}); len(got) != 0 {
+		t.Errorf("ClearPaths(target tidak dikenal) = %v", got)
+	}
+}
+
+func TestClearPending(t *testing.T) {
+	dir := t.TempDir()
+	profile := filepath.Join(dir, "EBWebView", "Default")
+	files := map[string]string{
+		"Cache/data_0":          "x",
+		"Network/Cookies":       "x",
+		"Local Storage/leveldb": "x",
+		"Preferences":           "{}",
+	}
+	for name, data := range files {
+		path := filepath.Join(profile, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	other := filepath.Join(dir, "window-state.json")
+	if err := os.WriteFile(other, []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// Tanpa file pending tidak ada yang dihapus
+	if err := ClearPending(dir); err != nil {
+		t.Fatalf("ClearPending tanpa pending: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(profile, "Cache")); err != nil {
+		t.Fatalf("Cache terhapus tanpa pending: %v", err)
+	}
+
+	if err := MarkPending(dir, []string{ClearCache, ClearCookies}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ClearPending(dir); err != nil {
+		t.Fatalf("ClearPending: %v", err)
+	}
+
+	for name, wantExist := range map[string]bool{
+		"Cache":                 false,
+		"Network/Cookies":       false,
+		"Network":               true,
+		"Local Storage/leveldb": true,
+		"Preferences":           true,
+	} {
+		_, err := os.Stat(filepath.Join(profile, filepath.FromSlash(name)))
+		if exists := err == nil; exists != wantExist {
+			t.Errorf("%s ada = %v, want %v", name, exists, wantExist)
+		}
+	}
+	if _, err := os.Stat(other); err != nil {
+		t.Errorf("file di luar EBWebView terhapus: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, pendingFile)); !os.IsNotExist(err) {
+		t.Errorf("file pending masih ada: %v", err)
+	}
+}
@@ -0,0 +1,198 @@
+// Package authenticode menandatangani dan memverifikasi executable Windows
+// dengan Authenticode tanpa signtool, sehingga app bisa di-sign dari Linux.
+//
+// Signature (PKCS#7 SignedData) disimpan di tabel sertifikat PE, di akhir
+// file. Karena hash Authenticode mencakup seluruh isi file sebelum tabel
+// tersebut, semua data app (termasuk config) harus sudah ada di dalam PE
+// image sebelum di-sign dan tidak boleh ditambahkan setelahnya.
+package authenticode
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SignOptions adalah opsi tambahan untuk SignFile
+type SignOptions struct {
+	TimestampURL string // Server timestamp RFC 3161 (kosong = tanpa timestamp)
+}
+
+// Signature adalah hasil verifikasi signature executable
+type Signature struct {
+	Signer          *x509.Certificate
+	Certificates    []*x509.Certificate // Semua sertifikat di signature
+	DigestAlgorithm string
+	Timestamp       time.Time // Nol jika signature tidak di-timestamp
+	TimestampSigner string
+	ChainErr        error // nil jika rantai sertifikat dipercaya sistem
+}
+
+// Sign mengembalikan salinan executable PE yang sudah di-sign.
+// Signature lama (jika ada) diganti.
+func Sign(data []byte, cert *Certificate, opts SignOptions) ([]byte, error) {
+	return SignContext(context.Background(), data, cert, opts)
+}
+
+// SignContext sama dengan Sign, tapi request ke server timestamp bisa
+// dibatalkan lewat ctx
+func SignContext(ctx context.Context, data []byte, cert *Certificate, opts SignOptions) ([]byte, error) {
+	img, err := parsePE(data)
+	if err != nil {
+		return nil, err
+	}
+	img, err = img.unsigned()
+	if err != nil {
+		return nil, err
+	}
+
+	indirect := buildIndirectData(img.digest(sha256.New()))
+
+	var timestamp func([]byte) ([]byte, error)
+	if opts.TimestampURL != "" {
+		timestamp = func(sig []byte) ([]byte, error) {
+			return requestTimestamp(ctx, opts.TimestampURL, sig)
+		}
+	}
+
+	blob, err := signIndirectData(indirect, cert, timestamp)
+	if err != nil {
+		return nil, err
+	}
+	return img.appendSignature(blob), nil
+}
+
+// SignFile menandatangani executable di path. File ditulis ulang lewat file
+// temporary supaya tidak rusak jika signing gagal di tengah jalan.
+func SignFile(path string, cert *Certificate, opts SignOptions) error {
+	return SignFileContext(context.Background(), path, cert, opts)
+}
+
+// SignFileContext sama dengan SignFile dengan ctx untuk request timestamp
+func SignFileContext(ctx context.Context, path string, cert *Certificate, opts SignOptions) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	signed, err := SignContext(ctx, data, cert, opts)
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), "w2app-sign-*.exe")
+	if err != nil {
+		return fmt.Errorf("gagal buat temp file: %w", err)
+	}
+	tmpPath := tmp.Name()
+	_, err = tmp.Write(signed)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, info.Mode().Perm())
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("gagal menulis file yang sudah di-sign: %w", err)
+	}
+	return nil
+}
+
+// Verify memverifikasi signature Authenticode executable: digest PE,
+// messageDigest, dan signature penanda tangan. Error dikembalikan jika
+// signature tidak ada atau tidak valid. Kepercayaan rantai sertifikat tidak
+// membuat Verify gagal, melainkan dilaporkan di Signature.ChainErr.
+func Verify(data []byte) (*Signature, error) {
+	img, err := parsePE(data)
+	if err != nil {
+		return nil, err
+	}
+	blob, err := img.signatureBlob()
+	if err != nil {
+		return nil, err
+	}
+
+	sd, certs, err := parseSignedData(blob)
+	if err != nil {
+		return nil, err
+	}
+	if !sd.ContentInfo.ContentType.Equal(oidSpcIndirectData) {
+		return nil, fmt.Errorf("signature bukan Authenticode (content type %v)", sd.ContentInfo.ContentType)
+	}
+
+	var indirect spcIndirectDataContent
+	if _, err := asn1.Unmarshal(sd.ContentInfo.Content.Bytes, &indirect); err != nil {
+		return nil, fmt.Errorf("SpcIndirectDataContent tidak valid: %w", err)
+	}
+	hashAlg, err := digestHash(indirect.MessageDigest.Algorithm.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(img.digest(hashAlg.New()), indirect.MessageDigest.Digest) {
+		return nil, fmt.Errorf("digest file tidak cocok, executable sudah diubah setelah di-sign")
+	}
+
+	si := &sd.SignerInfos[0]
+	signer, err := verifySigner(si, certs, contentOf(sd.ContentInfo.Content.Bytes))
+	if err != nil {
+		return nil, err
+	}
+
+	sig := &Signature{
+		Signer:          signer,
+		Certificates:    certs,
+		DigestAlgorithm: hashAlg.String(),
+	}
+
+	if len(si.UnauthenticatedAttributes.Bytes) > 0 {
+		if token, err := findAttribute(si.UnauthenticatedAttributes.Bytes, oidRFC3161Countersignature); err == nil {
+			ts, err := parseTimestamp(token, si.EncryptedDigest)
+			if err != nil {
+				return nil, err
+			}
+			sig.Timestamp = ts.time
+			sig.TimestampSigner = ts.signer
+		}
+	}
+
+	// Dengan timestamp, sertifikat cukup valid pada saat di-sign
+	verifyTime := time.Now()
+	if !sig.Timestamp.IsZero() {
+		verifyTime = sig.Timestamp
+	}
+	intermediates := x509.NewCertPool()
+	for _, c := range certs {
+		if c != signer {
+			intermediates.AddCert(c)
+		}
+	}
+	_, sig.ChainErr = signer.Verify(x509.VerifyOptions{
+		Intermediates: intermediates,
+		CurrentTime:   verifyTime,
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	})
+
+	return sig, nil
+}
+
+// VerifyFile memverifikasi signature executable di path
+func VerifyFile(path string) (*Signature, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Verify(data)
+}
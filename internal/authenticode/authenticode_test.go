@@ -0,0 +1,234 @@
+package authenticode
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// testdata/*.pfx berisi sertifikat code signing self-signed yang dibuat
+// dengan OpenSSL 3, password "test":
+//
+//	modern.pfx  openssl pkcs12 -export (PBES2 AES-256-CBC, MAC SHA-256)
+//	legacy.pfx  openssl pkcs12 -export -legacy (RC2-40 dan 3DES, MAC SHA-1)
+var testPFX = []string{"modern.pfx", "legacy.pfx"}
+
+const testPassword = "test"
+
+// makePE membuat executable PE32+ minimal tanpa section, diisi data
+// berpola sampai size byte
+func makePE(size int) []byte {
+	data := make([]byte, size)
+	for i := range data {
+		data[i] = byte(i*7 + i/251)
+	}
+	copy(data, "MZ")
+	binary.LittleEndian.PutUint32(data[0x3c:], 0x40)
+
+	copy(data[0x40:], "PE\x00\x00")
+	coff := 0x44
+	clear(data[coff : coff+20])
+	binary.LittleEndian.PutUint16(data[coff:], 0x8664)
+	binary.LittleEndian.PutUint16(data[coff+16:], 240)
+
+	opt := coff + 20
+	clear(data[opt : opt+240])
+	binary.LittleEndian.PutUint16(data[opt:], 0x20b)
+	binary.LittleEndian.PutUint32(data[opt+108:], 16)
+	return data
+}
+
+func loadTestPFX(t *testing.T, name string) *Certificate {
+	t.Helper()
+	cert, err := LoadPFX(filepath.Join("testdata", name), testPassword)
+	if err != nil {
+		t.Fatalf("LoadPFX(%s): %v", name, err)
+	}
+	return cert
+}
+
+func TestDecodePFX(t *testing.T) {
+	for _, name := range testPFX {
+		t.Run(name, func(t *testing.T) {
+			cert := loadTestPFX(t, name)
+			if cert.Leaf.Subject.CommonName != "w2app test" {
+				t.Errorf("CommonName = %q", cert.Leaf.Subject.CommonName)
+			}
+			if len(cert.Chain) != 0 {
+				t.Errorf("Chain = %d sertifikat, want 0", len(cert.Chain))
+			}
+			if _, err := LoadPFX(filepath.Join("testdata", name), "salah"); err == nil {
+				t.Error("password salah: error nil")
+			}
+		})
+	}
+	if _, err := DecodePFX([]byte("bukan pfx"), testPassword); err == nil {
+		t.Error("DecodePFX(bukan pfx): error nil")
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	unsigned := makePE(4099)
+	for _, name := range testPFX {
+		t.Run(name, func(t *testing.T) {
+			cert := loadTestPFX(t, name)
+			signed, err := Sign(unsigned, cert, SignOptions{})
+			if err != nil {
+				t.Fatalf("Sign: %v", err)
+			}
+			// Selain security directory dan CheckSum, isi file tidak berubah
+			img, err := parsePE(signed)
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range unsigned {
+				inDir := i >= img.certDirOff && i < img.certDirOff+8
+				inSum := i >= img.checksumOff && i < img.checksumOff+4
+				if signed[i] != unsigned[i] && !inDir && !inSum {
+					t.Fatalf("byte %d berubah", i)
+				}
+			}
+
+			sig, err := Verify(signed)
+			if err != nil {
+				t.Fatalf("Verify: %v", err)
+			}
+			if !sig.Signer.Equal(cert.Leaf) {
+				t.Errorf("Signer = %s, want %s", sig.Signer.Subject, cert.Leaf.Subject)
+			}
+			if sig.DigestAlgorithm != "SHA-256" {
+				t.Errorf("DigestAlgorithm = %q", sig.DigestAlgorithm)
+			}
+			if !sig.Timestamp.IsZero() {
+				t.Errorf("Timestamp = %v, want nol", sig.Timestamp)
+			}
+			if sig.ChainErr == nil {
+				t.Error("ChainErr nil untuk sertifikat self-signed")
+			}
+
+			if img.certOff%8 != 0 || img.certSize%8 != 0 || img.certOff+img.certSize != len(signed) {
+				t.Errorf("tabel sertifikat di %d (%d byte), file %d byte", img.certOff, img.certSize, len(signed))
+			}
+			if got, want := binary.LittleEndian.Uint32(signed[img.checksumOff:]), peChecksum(signed, img.checksumOff); got != want {
+				t.Errorf("CheckSum = 0x%x, want 0x%x", got, want)
+			}
+		})
+	}
+}
+
+func TestResign(t *testing.T) {
+	modern := loadTestPFX(t, "modern.pfx")
+	legacy := loadTestPFX(t, "legacy.pfx")
+
+	signed, err := Sign(makePE(2048), modern, SignOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resigned, err := Sign(signed, legacy, SignOptions{})
+	if err != nil {
+		t.Fatalf("Sign ulang: %v", err)
+	}
+
+	sig, err := Verify(resigned)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if !sig.Signer.Equal(legacy.Leaf) {
+		t.Errorf("Signer bukan sertifikat terakhir")
+	}
+
+	// Signature lama diganti, bukan ditambah
+	a, _ := parsePE(signed)
+	b, _ := parsePE(resigned)
+	if a.certOff != b.certOff {
+		t.Errorf("tabel sertifikat pindah dari %d ke %d", a.certOff, b.certOff)
+	}
+}
+
+func TestVerifyTampered(t *testing.T) {
+	signed, err := Sign(makePE(4096), loadTestPFX(t, "modern.pfx"), SignOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := bytes.Clone(signed)
+	tampered[0x800] ^= 1
+	if _, err := Verify(tampered); err == nil || !strings.Contains(err.Error(), "digest") {
+		t.Errorf("Verify(1 byte diubah) = %v, want error digest", err)
+	}
+
+	// Mengubah CheckSum tidak mempengaruhi digest
+	img, _ := parsePE(signed)
+	tampered = bytes.Clone(signed)
+	tampered[img.checksumOff] ^= 1
+	if _, err := Verify(tampered); err != nil {
+		t.Errorf("Verify(CheckSum diubah): %v", err)
+	}
+}
+
+func TestTrailingData(t *testing.T) {
+	signed, err := Sign(makePE(4096), loadTestPFX(t, "modern.pfx"), SignOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	trailing := append(bytes.Clone(signed), "config"...)
+	if _, err := Verify(trailing); err == nil {
+		t.Error("Verify: error nil untuk data di belakang signature")
+	}
+	if _, err := Sign(trailing, loadTestPFX(t, "modern.pfx"), SignOptions{}); err == nil {
+		t.Error("Sign: error nil untuk data di belakang signature")
+	}
+}
+
+func TestVerifyUnsigned(t *testing.T) {
+	if _, err := Verify(makePE(1024)); !errors.Is(err, ErrNotSigned) {
+		t.Errorf("Verify = %v, want ErrNotSigned", err)
+	}
+	for _, data := range [][]byte{nil, []byte("MZ"), make([]byte, 512)} {
+		if _, err := Verify(data); err == nil || errors.Is(err, ErrNotSigned) {
+			t.Errorf("Verify(%d byte) = %v, want error bukan PE", len(data), err)
+		}
+	}
+}
+
+func TestSignFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app.exe")
+	if err := os.WriteFile(path, makePE(3000), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := SignFile(path, loadTestPFX(t, "legacy.pfx"), SignOptions{}); err != nil {
+		t.Fatalf("SignFile: %v", err)
+	}
+	if _, err := VerifyFile(path); err != nil {
+		t.Fatalf("VerifyFile: %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("%d file di direktori, temp file tertinggal", len(entries))
+	}
+}
+
+func TestPEChecksum(t *testing.T) {
+	tests := []struct {
+		data        []byte
+		checksumOff int
+		want        uint32
+	}{
+		// Word 0x0001 + 0x0002, field CheckSum dilewati, byte ganjil 0x03,
+		// ditambah ukuran file 9
+		{[]byte{0x01, 0x00, 0x02, 0x00, 0xff, 0xff, 0xff, 0xff, 0x03}, 4, 0x0f},
+		// 0xffff + 0x0002 = 0x10001, carry dilipat menjadi 0x0002
+		{[]byte{0xff, 0xff, 0x02, 0x00, 0x00, 0x00, 0x00, 0x00}, 4, 0x02 + 8},
+		{[]byte{0x34, 0x12, 0x00, 0x00, 0x00, 0x00, 0x78, 0x56}, 2, 0x1234 + 0x5678 + 8},
+		{nil, 0, 0},
+	}
+	for i, tt := range tests {
+		if got := peChecksum(tt.data, tt.checksumOff); got != tt.want {
+			t.Errorf("tests[%d]: peChecksum = 0x%x, want 0x%x", i, got, tt.want)
+		}
+	}
+}
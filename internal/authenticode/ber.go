@@ -0,0 +1,191 @@
+package authenticode
+
+import (
+	"encoding/asn1"
+	"errors"
+	"fmt"
+)
+
+// File PFX dari Windows sering memakai encoding BER (panjang indefinite dan
+// OCTET STRING yang dipecah), sedangkan encoding/asn1 hanya menerima DER.
+// berToDER menormalkan encoding tersebut sebelum di-parse.
+
+var errBERTruncated = errors.New("data ASN.1 terpotong")
+
+// unmarshalBER mem-parse data BER/DER ke v
+func unmarshalBER(data []byte, v any) error {
+	der, err := berToDER(data)
+	if err != nil {
+		return err
+	}
+	rest, err := asn1.Unmarshal(der, v)
+	if err != nil {
+		return err
+	}
+	if len(rest) > 0 {
+		return fmt.Errorf("ada %d byte sisa setelah data ASN.1", len(rest))
+	}
+	return nil
+}
+
+func berToDER(data []byte) ([]byte, error) {
+	var out []byte
+	for len(data) > 0 {
+		elem, rest, err := convertBER(data)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, elem...)
+		data = rest
+	}
+	return out, nil
+}
+
+// convertBER mengubah satu elemen BER menjadi DER dan mengembalikan sisa data
+func convertBER(data []byte) (der, rest []byte, err error) {
+	if len(data) < 2 {
+		return nil, nil, errBERTruncated
+	}
+
+	// Tag (termasuk bentuk high-tag-number)
+	tagLen := 1
+	if data[0]&0x1f == 0x1f {
+		for tagLen < len(data) && data[tagLen]&0x80 != 0 {
+			tagLen++
+		}
+		tagLen++
+	}
+	if tagLen >= len(data) {
+		return nil, nil, errBERTruncated
+	}
+	tag := data[:tagLen]
+	constructed := data[0]&0x20 != 0
+	data = data[tagLen:]
+
+	// Length
+	indefinite := false
+	length := 0
+	switch b := data[0]; {
+	case b == 0x80:
+		indefinite = true
+		data = data[1:]
+	case b&0x80 == 0:
+		length = int(b)
+		data = data[1:]
+	default:
+		n := int(b & 0x7f)
+		if n > 4 || len(data) < 1+n {
+			return nil, nil, errBERTruncated
+		}
+		for _, c := range data[1 : 1+n] {
+			length = length<<8 | int(c)
+		}
+		data = data[1+n:]
+	}
+
+	if !constructed {
+		if indefinite {
+			return nil, nil, errors.New("elemen primitive dengan panjang indefinite")
+		}
+		if length > len(data) {
+			return nil, nil, errBERTruncated
+		}
+		return appendTLV(nil, tag, data[:length]), data[length:], nil
+	}
+
+	var children [][]byte
+	if indefinite {
+		for {
+			if len(data) < 2 {
+				return nil, nil, errBERTruncated
+			}
+			if data[0] == 0 && data[1] == 0 {
+				data = data[2:]
+				break
+			}
+			child, r, err := convertBER(data)
+			if err != nil {
+				return nil, nil, err
+			}
+			children = append(children, child)
+			data = r
+		}
+		rest = data
+	} else {
+		if length > len(data) {
+			return nil, nil, errBERTruncated
+		}
+		content := data[:length]
+		rest = data[length:]
+		for len(content) > 0 {
+			child, r, err := convertBER(content)
+			if err != nil {
+				return nil, nil, err
+			}
+			children = append(children, child)
+			content = r
+		}
+	}
+
+	// OCTET STRING constructed digabung menjadi satu OCTET STRING primitive
+	if len(tag) == 1 && tag[0] == 0x24 {
+		var value []byte
+		for _, child := range children {
+			var part []byte
+			if _, err := asn1.Unmarshal(child, &part); err != nil {
+				return nil, nil, fmt.Errorf("potongan OCTET STRING tidak valid: %w", err)
+			}
+			value = append(value, part...)
+		}
+		return appendTLV(nil, []byte{0x04}, value), rest, nil
+	}
+
+	var content []byte
+	for _, child := range children {
+		content = append(content, child...)
+	}
+	return appendTLV(nil, tag, content), rest, nil
+}
+
+// appendTLV menulis tag, panjang (DER), dan isi ke dst
+func appendTLV(dst, tag, content []byte) []byte {
+	dst = append(dst, tag...)
+	n := len(content)
+	switch {
+	case n < 0x80:
+		dst = append(dst, byte(n))
+	case n < 0x100:
+		dst = append(dst, 0x81, byte(n))
+	case n < 0x10000:
+		dst = append(dst, 0x82, byte(n>>8), byte(n))
+	case n < 0x1000000:
+		dst = append(dst, 0x83, byte(n>>16), byte(n>>8), byte(n))
+	default:
+		dst = append(dst, 0x84, byte(n>>24), byte(n>>16), byte(n>>8), byte(n))
+	}
+	return append(dst, content...)
+}
+
+// octetContent mengembalikan isi OCTET STRING yang mungkin di-tag implicit
+// dan dikirim dalam bentuk constructed (potongan-potongan OCTET STRING)
+func octetContent(raw asn1.RawValue) ([]byte, error) {
+	if !raw.IsCompound {
+		return raw.Bytes, nil
+	}
+	var value []byte
+	rest := raw.Bytes
+	for len(rest) > 0 {
+		var part asn1.RawValue
+		var err error
+		rest, err = asn1.Unmarshal(rest, &part)
+		if err != nil {
+			return nil, err
+		}
+		chunk, err := octetContent(part)
+		if err != nil {
+			return nil, err
+		}
+		value = append(value, chunk...)
+	}
+	return value, nil
+}
@@ -0,0 +1,175 @@
+package authenticode
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+)
+
+// Header WIN_CERTIFICATE untuk signature Authenticode
+const (
+	winCertRevision2          = 0x0200
+	winCertTypePKCSSignedData = 0x0002
+	winCertHeaderSize         = 8
+)
+
+// ErrNotSigned dikembalikan jika executable tidak memiliki signature
+var ErrNotSigned = errors.New("executable tidak memiliki signature")
+
+// peImage menyimpan offset-offset di header PE yang relevan untuk Authenticode
+type peImage struct {
+	data        []byte
+	checksumOff int // Offset field CheckSum di optional header
+	certDirOff  int // Offset entry IMAGE_DIRECTORY_ENTRY_SECURITY
+	certOff     int // Offset tabel sertifikat (file offset, bukan RVA)
+	certSize    int
+}
+
+func parsePE(data []byte) (*peImage, error) {
+	if len(data) < 0x40 || string(data[:2]) != "MZ" {
+		return nil, fmt.Errorf("bukan file PE (header MZ tidak ditemukan)")
+	}
+	peOff := int(binary.LittleEndian.Uint32(data[0x3c:]))
+	if peOff < 0 || peOff+24 > len(data) || string(data[peOff:peOff+4]) != "PE\x00\x00" {
+		return nil, fmt.Errorf("bukan file PE (signature PE tidak ditemukan)")
+	}
+
+	coff := peOff + 4
+	optSize := int(binary.LittleEndian.Uint16(data[coff+16:]))
+	opt := coff + 20
+	if opt+optSize > len(data) || optSize < 2 {
+		return nil, fmt.Errorf("optional header PE terpotong")
+	}
+
+	var numDirsOff, dirsOff int
+	switch magic := binary.LittleEndian.Uint16(data[opt:]); magic {
+	case 0x10b: // PE32
+		numDirsOff, dirsOff = opt+92, opt+96
+	case 0x20b: // PE32+
+		numDirsOff, dirsOff = opt+108, opt+112
+	default:
+		return nil, fmt.Errorf("magic optional header 0x%x tidak dikenal", magic)
+	}
+	if dirsOff > opt+optSize {
+		return nil, fmt.Errorf("optional header PE terpotong")
+	}
+
+	numDirs := int(binary.LittleEndian.Uint32(data[numDirsOff:]))
+	if numDirs < 5 || dirsOff+5*8 > opt+optSize {
+		return nil, fmt.Errorf("PE tidak memiliki security directory")
+	}
+
+	img := &peImage{
+		data:        data,
+		checksumOff: opt + 64,
+		certDirOff:  dirsOff + 4*8,
+	}
+	img.certOff = int(binary.LittleEndian.Uint32(data[img.certDirOff:]))
+	img.certSize = int(binary.LittleEndian.Uint32(data[img.certDirOff+4:]))
+
+	if img.certSize > 0 {
+		if img.certOff < opt+optSize || img.certOff+img.certSize > len(data) {
+			return nil, fmt.Errorf("tabel sertifikat di luar batas file")
+		}
+	}
+	return img, nil
+}
+
+// digest menghitung hash Authenticode: seluruh file kecuali field CheckSum,
+// entry security directory, dan tabel sertifikat itu sendiri
+func (p *peImage) digest(h hash.Hash) []byte {
+	end := len(p.data)
+	if p.certSize > 0 {
+		end = p.certOff
+	}
+	h.Write(p.data[:p.checksumOff])
+	h.Write(p.data[p.checksumOff+4 : p.certDirOff])
+	h.Write(p.data[p.certDirOff+8 : end])
+	return h.Sum(nil)
+}
+
+// signatureBlob mengembalikan isi WIN_CERTIFICATE pertama (PKCS#7 DER)
+func (p *peImage) signatureBlob() ([]byte, error) {
+	if p.certSize == 0 {
+		return nil, ErrNotSigned
+	}
+	if p.certOff+p.certSize != len(p.data) {
+		return nil, fmt.Errorf("ada %d byte data di belakang signature", len(p.data)-p.certOff-p.certSize)
+	}
+
+	table := p.data[p.certOff : p.certOff+p.certSize]
+	if len(table) < winCertHeaderSize {
+		return nil, fmt.Errorf("tabel sertifikat terpotong")
+	}
+	length := int(binary.LittleEndian.Uint32(table[0:]))
+	revision := binary.LittleEndian.Uint16(table[4:])
+	certType := binary.LittleEndian.Uint16(table[6:])
+	if length < winCertHeaderSize || length > len(table) {
+		return nil, fmt.Errorf("panjang WIN_CERTIFICATE tidak valid")
+	}
+	if revision != winCertRevision2 || certType != winCertTypePKCSSignedData {
+		return nil, fmt.Errorf("tipe sertifikat 0x%x (revisi 0x%x) tidak didukung", certType, revision)
+	}
+	return table[winCertHeaderSize:length], nil
+}
+
+// unsigned mengembalikan salinan file tanpa signature, sudah di-padding ke
+// kelipatan 8 byte sehingga siap di-hash dan ditambah tabel sertifikat
+func (p *peImage) unsigned() (*peImage, error) {
+	data := p.data
+	if p.certSize > 0 {
+		if p.certOff+p.certSize != len(p.data) {
+			return nil, fmt.Errorf("ada data di belakang signature lama, file tidak bisa di-sign ulang")
+		}
+		data = data[:p.certOff]
+	}
+
+	out := make([]byte, len(data), len(data)+7)
+	copy(out, data)
+	for len(out)%8 != 0 {
+		out = append(out, 0)
+	}
+	binary.LittleEndian.PutUint32(out[p.certDirOff:], 0)
+	binary.LittleEndian.PutUint32(out[p.certDirOff+4:], 0)
+
+	return &peImage{data: out, checksumOff: p.checksumOff, certDirOff: p.certDirOff}, nil
+}
+
+// appendSignature menambahkan WIN_CERTIFICATE berisi blob PKCS#7, mengisi
+// security directory, dan menghitung ulang CheckSum PE
+func (p *peImage) appendSignature(blob []byte) []byte {
+	length := winCertHeaderSize + len(blob)
+	padded := (length + 7) &^ 7
+
+	entry := make([]byte, padded)
+	binary.LittleEndian.PutUint32(entry[0:], uint32(padded))
+	binary.LittleEndian.PutUint16(entry[4:], winCertRevision2)
+	binary.LittleEndian.PutUint16(entry[6:], winCertTypePKCSSignedData)
+	copy(entry[winCertHeaderSize:], blob)
+
+	out := append(p.data, entry...)
+	binary.LittleEndian.PutUint32(out[p.certDirOff:], uint32(len(p.data)))
+	binary.LittleEndian.PutUint32(out[p.certDirOff+4:], uint32(padded))
+	binary.LittleEndian.PutUint32(out[p.checksumOff:], peChecksum(out, p.checksumOff))
+	return out
+}
+
+// peChecksum menghitung CheckSum PE (algoritma yang sama dengan
+// CheckSumMappedFile): jumlah word 16-bit dengan carry, ditambah ukuran file
+func peChecksum(data []byte, checksumOff int) uint32 {
+	var sum uint32
+	for i := 0; i+1 < len(data); i += 2 {
+		if i == checksumOff || i == checksumOff+2 {
+			continue
+		}
+		sum += uint32(binary.LittleEndian.Uint16(data[i:]))
+		sum = (sum & 0xffff) + (sum >> 16)
+	}
+	if len(data)%2 == 1 {
+		sum += uint32(data[len(data)-1])
+		sum = (sum & 0xffff) + (sum >> 16)
+	}
+	sum = (sum & 0xffff) + (sum >> 16)
+	return sum + uint32(len(data))
+}
@@ -0,0 +1,496 @@
+package authenticode
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/des"
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"unicode/utf16"
+)
+
+// ErrIncorrectPassword dikembalikan jika password PFX salah
+var ErrIncorrectPassword = errors.New("password PFX salah")
+
+var (
+	oidDataContent          = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 1}
+	oidEncryptedDataContent = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 6}
+
+	oidKeyBag              = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 1}
+	oidPKCS8ShroudedKeyBag = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 2}
+	oidCertBag             = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 10, 1, 3}
+	oidX509Certificate     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 22, 1}
+
+	oidPBEWithSHAAnd3KeyTripleDESCBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 3}
+	oidPBEWithSHAAnd128BitRC2CBC     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 5}
+	oidPBEWithSHAAnd40BitRC2CBC      = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 12, 1, 6}
+	oidPBES2                         = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 13}
+	oidPBKDF2                        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 5, 12}
+
+	oidHMACWithSHA1   = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 7}
+	oidHMACWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 9}
+	oidHMACWithSHA384 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 10}
+	oidHMACWithSHA512 = asn1.ObjectIdentifier{1, 2, 840, 113549, 2, 11}
+
+	oidAES128CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 2}
+	oidAES192CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 22}
+	oidAES256CBC  = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 1, 42}
+	oidDESEDE3CBC = asn1.ObjectIdentifier{1, 2, 840, 113549, 3, 7}
+)
+
+type pfxPDU struct {
+	Version  int
+	AuthSafe contentInfo
+	MacData  macData `asn1:"optional"`
+}
+
+type contentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"tag:0,explicit,optional"`
+}
+
+type macData struct {
+	Mac        digestInfo
+	MacSalt    []byte
+	Iterations int `asn1:"optional,default:1"`
+}
+
+type digestInfo struct {
+	Algorithm pkix.AlgorithmIdentifier
+	Digest    []byte
+}
+
+type encryptedData struct {
+	Version              int
+	EncryptedContentInfo encryptedContentInfo
+}
+
+type encryptedContentInfo struct {
+	ContentType                asn1.ObjectIdentifier
+	ContentEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedContent           asn1.RawValue `asn1:"tag:0,optional"`
+}
+
+type safeBag struct {
+	ID         asn1.ObjectIdentifier
+	Value      asn1.RawValue `asn1:"tag:0,explicit"`
+	Attributes asn1.RawValue `asn1:"optional"`
+}
+
+type certBag struct {
+	ID   asn1.ObjectIdentifier
+	Data []byte `asn1:"tag:0,explicit"`
+}
+
+type encryptedPrivateKeyInfo struct {
+	Algorithm     pkix.AlgorithmIdentifier
+	EncryptedData []byte
+}
+
+type pbeParams struct {
+	Salt       []byte
+	Iterations int
+}
+
+type pbes2Params struct {
+	KDF    pkix.AlgorithmIdentifier
+	Scheme pkix.AlgorithmIdentifier
+}
+
+type pbkdf2Params struct {
+	Salt       []byte
+	Iterations int
+	KeyLength  int                      `asn1:"optional"`
+	PRF        pkix.AlgorithmIdentifier `asn1:"optional"`
+}
+
+// Certificate adalah key dan sertifikat code signing dari file PFX
+type Certificate struct {
+	Signer crypto.Signer
+	Leaf   *x509.Certificate   // Sertifikat milik Signer
+	Chain  []*x509.Certificate // Sertifikat lain di PFX (intermediate CA)
+}
+
+// LoadPFX membaca file PFX/PKCS#12 berisi private key dan sertifikat
+func LoadPFX(path, password string) (*Certificate, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca file PFX: %w", err)
+	}
+	return DecodePFX(data, password)
+}
+
+// DecodePFX mem-parse PFX/PKCS#12. Enkripsi yang didukung: PBES2 (AES,
+// 3DES) dari OpenSSL 3 dan Windows modern, serta PBE SHA1 3DES/RC2 dari
+// PFX lama.
+func DecodePFX(data []byte, password string) (*Certificate, error) {
+	var pfx pfxPDU
+	if err := unmarshalBER(data, &pfx); err != nil {
+		return nil, fmt.Errorf("file bukan PFX yang valid: %w", err)
+	}
+	if pfx.Version != 3 {
+		return nil, fmt.Errorf("versi PFX %d tidak didukung", pfx.Version)
+	}
+	if !pfx.AuthSafe.ContentType.Equal(oidDataContent) {
+		return nil, fmt.Errorf("PFX dengan mode integritas public-key tidak didukung")
+	}
+
+	authSafe, err := unwrapOctets(pfx.AuthSafe.Content)
+	if err != nil {
+		return nil, fmt.Errorf("authSafe tidak valid: %w", err)
+	}
+
+	if len(pfx.MacData.Mac.Algorithm.Algorithm) > 0 {
+		if err := verifyMAC(&pfx.MacData, authSafe, password); err != nil {
+			return nil, err
+		}
+	}
+
+	var contents []contentInfo
+	if err := unmarshalBER(authSafe, &contents); err != nil {
+		return nil, fmt.Errorf("authSafe tidak valid: %w", err)
+	}
+
+	var keys []crypto.Signer
+	var certs []*x509.Certificate
+	for _, ci := range contents {
+		var safeContents []byte
+		switch {
+		case ci.ContentType.Equal(oidDataContent):
+			safeContents, err = unwrapOctets(ci.Content)
+		case ci.ContentType.Equal(oidEncryptedDataContent):
+			safeContents, err = decryptContent(ci.Content, password)
+		default:
+			err = fmt.Errorf("content type %v tidak didukung", ci.ContentType)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var bags []safeBag
+		if err := unmarshalBER(safeContents, &bags); err != nil {
+			return nil, fmt.Errorf("safe contents tidak valid: %w", err)
+		}
+
+		for _, bag := range bags {
+			switch {
+			case bag.ID.Equal(oidCertBag):
+				var cb certBag
+				if err := unmarshalBER(bag.Value.Bytes, &cb); err != nil {
+					return nil, fmt.Errorf("cert bag tidak valid: %w", err)
+				}
+				if !cb.ID.Equal(oidX509Certificate) {
+					continue
+				}
+				cert, err := x509.ParseCertificate(cb.Data)
+				if err != nil {
+					return nil, fmt.Errorf("gagal parse sertifikat: %w", err)
+				}
+				certs = append(certs, cert)
+
+			case bag.ID.Equal(oidKeyBag):
+				key, err := parsePrivateKey(bag.Value.Bytes)
+				if err != nil {
+					return nil, err
+				}
+				keys = append(keys, key)
+
+			case bag.ID.Equal(oidPKCS8ShroudedKeyBag):
+				var epki encryptedPrivateKeyInfo
+				if err := unmarshalBER(bag.Value.Bytes, &epki); err != nil {
+					return nil, fmt.Errorf("key bag tidak valid: %w", err)
+				}
+				plain, err := pbeDecrypt(epki.Algorithm, password, epki.EncryptedData)
+				if err != nil {
+					return nil, err
+				}
+				key, err := parsePrivateKey(plain)
+				if err != nil {
+					return nil, err
+				}
+				keys = append(keys, key)
+			}
+		}
+	}
+
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("PFX tidak berisi private key")
+	}
+	if len(keys) > 1 {
+		return nil, fmt.Errorf("PFX berisi %d private key, hanya 1 yang didukung", len(keys))
+	}
+
+	result := &Certificate{Signer: keys[0]}
+	pub, ok := keys[0].Public().(interface{ Equal(crypto.PublicKey) bool })
+	for _, cert := range certs {
+		if result.Leaf == nil && ok && pub.Equal(cert.PublicKey) {
+			result.Leaf = cert
+			continue
+		}
+		result.Chain = append(result.Chain, cert)
+	}
+	if result.Leaf == nil {
+		return nil, fmt.Errorf("PFX tidak berisi sertifikat untuk private key")
+	}
+	return result, nil
+}
+
+func parsePrivateKey(der []byte) (crypto.Signer, error) {
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, fmt.Errorf("gagal parse private key: %w", err)
+	}
+	signer, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("tipe private key %T tidak didukung", key)
+	}
+	return signer, nil
+}
+
+// unwrapOctets mengambil isi OCTET STRING dari content [0] EXPLICIT
+func unwrapOctets(raw asn1.RawValue) ([]byte, error) {
+	var octets []byte
+	if _, err := asn1.Unmarshal(raw.Bytes, &octets); err != nil {
+		return nil, err
+	}
+	return octets, nil
+}
+
+func decryptContent(raw asn1.RawValue, password string) ([]byte, error) {
+	var ed encryptedData
+	if err := unmarshalBER(raw.Bytes, &ed); err != nil {
+		return nil, fmt.Errorf("encrypted data tidak valid: %w", err)
+	}
+	ciphertext, err := octetContent(ed.EncryptedContentInfo.EncryptedContent)
+	if err != nil {
+		return nil, fmt.Errorf("encrypted data tidak valid: %w", err)
+	}
+	return pbeDecrypt(ed.EncryptedContentInfo.ContentEncryptionAlgorithm, password, ciphertext)
+}
+
+// verifyMAC mengecek integritas PFX sekaligus memvalidasi password
+func verifyMAC(md *macData, content []byte, password string) error {
+	hashAlg, err := digestHash(md.Mac.Algorithm.Algorithm)
+	if err != nil {
+		return fmt.Errorf("algoritma MAC PFX: %w", err)
+	}
+	newHash := hashAlg.New
+	blockSize := newHash().BlockSize()
+
+	// Password kosong bisa di-encode sebagai BMPString kosong atau tanpa
+	// terminator tergantung aplikasi yang membuat PFX
+	candidates := [][]byte{bmpString(password)}
+	if password == "" {
+		candidates = append(candidates, nil)
+	}
+	for _, pw := range candidates {
+		key := pkcs12KDF(newHash, blockSize, md.MacSalt, pw, md.Iterations, 3, hashAlg.Size())
+		mac := hmac.New(newHash, key)
+		mac.Write(content)
+		if hmac.Equal(mac.Sum(nil), md.Mac.Digest) {
+			return nil
+		}
+	}
+	return ErrIncorrectPassword
+}
+
+// pbeDecrypt mendekripsi data sesuai AlgorithmIdentifier PKCS#12/PKCS#5
+func pbeDecrypt(alg pkix.AlgorithmIdentifier, password string, data []byte) ([]byte, error) {
+	var block cipher.Block
+	var iv []byte
+
+	switch {
+	case alg.Algorithm.Equal(oidPBEWithSHAAnd3KeyTripleDESCBC),
+		alg.Algorithm.Equal(oidPBEWithSHAAnd128BitRC2CBC),
+		alg.Algorithm.Equal(oidPBEWithSHAAnd40BitRC2CBC):
+		var params pbeParams
+		if _, err := asn1.Unmarshal(alg.Parameters.FullBytes, &params); err != nil {
+			return nil, fmt.Errorf("parameter PBE tidak valid: %w", err)
+		}
+		pw := bmpString(password)
+		derive := func(id byte, size int) []byte {
+			return pkcs12KDF(sha1.New, 64, params.Salt, pw, params.Iterations, id, size)
+		}
+
+		var err error
+		switch {
+		case alg.Algorithm.Equal(oidPBEWithSHAAnd3KeyTripleDESCBC):
+			block, err = des.NewTripleDESCipher(derive(1, 24))
+		case alg.Algorithm.Equal(oidPBEWithSHAAnd128BitRC2CBC):
+			block = newRC2(derive(1, 16), 128)
+		default:
+			block = newRC2(derive(1, 5), 40)
+		}
+		if err != nil {
+			return nil, err
+		}
+		iv = derive(2, 8)
+
+	case alg.Algorithm.Equal(oidPBES2):
+		var err error
+		block, iv, err = pbes2Cipher(alg, password)
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		return nil, fmt.Errorf("algoritma enkripsi PFX %v tidak didukung", alg.Algorithm)
+	}
+
+	if len(data) == 0 || len(data)%block.BlockSize() != 0 {
+		return nil, fmt.Errorf("panjang data terenkripsi tidak valid")
+	}
+	plain := make([]byte, len(data))
+	cipher.NewCBCDecrypter(block, iv).CryptBlocks(plain, data)
+
+	// PKCS#7 padding; padding yang rusak hampir selalu berarti password salah
+	pad := int(plain[len(plain)-1])
+	if pad == 0 || pad > block.BlockSize() || pad > len(plain) {
+		return nil, ErrIncorrectPassword
+	}
+	if !bytes.Equal(plain[len(plain)-pad:], bytes.Repeat([]byte{byte(pad)}, pad)) {
+		return nil, ErrIncorrectPassword
+	}
+	return plain[:len(plain)-pad], nil
+}
+
+func pbes2Cipher(alg pkix.AlgorithmIdentifier, password string) (cipher.Block, []byte, error) {
+	var params pbes2Params
+	if _, err := asn1.Unmarshal(alg.Parameters.FullBytes, &params); err != nil {
+		return nil, nil, fmt.Errorf("parameter PBES2 tidak valid: %w", err)
+	}
+	if !params.KDF.Algorithm.Equal(oidPBKDF2) {
+		return nil, nil, fmt.Errorf("KDF %v tidak didukung", params.KDF.Algorithm)
+	}
+	var kdf pbkdf2Params
+	if _, err := asn1.Unmarshal(params.KDF.Parameters.FullBytes, &kdf); err != nil {
+		return nil, nil, fmt.Errorf("parameter PBKDF2 tidak valid: %w", err)
+	}
+
+	prf := sha1.New
+	switch oid := kdf.PRF.Algorithm; {
+	case len(oid) == 0, oid.Equal(oidHMACWithSHA1):
+	case oid.Equal(oidHMACWithSHA256):
+		prf = sha256.New
+	case oid.Equal(oidHMACWithSHA384):
+		prf = sha512.New384
+	case oid.Equal(oidHMACWithSHA512):
+		prf = sha512.New
+	default:
+		return nil, nil, fmt.Errorf("PRF %v tidak didukung", oid)
+	}
+
+	var keyLen int
+	scheme := params.Scheme.Algorithm
+	switch {
+	case scheme.Equal(oidAES128CBC):
+		keyLen = 16
+	case scheme.Equal(oidAES192CBC):
+		keyLen = 24
+	case scheme.Equal(oidAES256CBC):
+		keyLen = 32
+	case scheme.Equal(oidDESEDE3CBC):
+		keyLen = 24
+	default:
+		return nil, nil, fmt.Errorf("cipher %v tidak didukung", scheme)
+	}
+
+	var iv []byte
+	if _, err := asn1.Unmarshal(params.Scheme.Parameters.FullBytes, &iv); err != nil {
+		return nil, nil, fmt.Errorf("IV tidak valid: %w", err)
+	}
+
+	key, err := pbkdf2.Key(prf, password, kdf.Salt, kdf.Iterations, keyLen)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var block cipher.Block
+	if scheme.Equal(oidDESEDE3CBC) {
+		block, err = des.NewTripleDESCipher(key)
+	} else {
+		block, err = aes.NewCipher(key)
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(iv) != block.BlockSize() {
+		return nil, nil, fmt.Errorf("panjang IV %d tidak valid", len(iv))
+	}
+	return block, iv, nil
+}
+
+// bmpString meng-encode password sebagai UTF-16BE dengan terminator null,
+// sesuai yang dipakai KDF PKCS#12
+func bmpString(s string) []byte {
+	units := utf16.Encode([]rune(s))
+	out := make([]byte, 0, 2*len(units)+2)
+	for _, u := range units {
+		out = append(out, byte(u>>8), byte(u))
+	}
+	return append(out, 0, 0)
+}
+
+// pkcs12KDF adalah fungsi derivasi key PKCS#12 (RFC 7292 lampiran B.2).
+// id 1 = key enkripsi, 2 = IV, 3 = key MAC.
+func pkcs12KDF(newHash func() hash.Hash, v int, salt, password []byte, iterations int, id byte, size int) []byte {
+	fill := func(src []byte) []byte {
+		if len(src) == 0 {
+			return nil
+		}
+		n := v * ((len(src) + v - 1) / v)
+		out := make([]byte, n)
+		for i := range out {
+			out[i] = src[i%len(src)]
+		}
+		return out
+	}
+
+	d := bytes.Repeat([]byte{id}, v)
+	i := append(fill(salt), fill(password)...)
+
+	var out []byte
+	for len(out) < size {
+		h := newHash()
+		h.Write(d)
+		h.Write(i)
+		a := h.Sum(nil)
+		for n := 1; n < iterations; n++ {
+			h.Reset()
+			h.Write(a)
+			a = h.Sum(a[:0])
+		}
+		out = append(out, a...)
+
+		if len(out) >= size {
+			break
+		}
+
+		// I_j = (I_j + B + 1) mod 2^(v*8) untuk setiap blok v byte
+		b := make([]byte, v)
+		for k := range b {
+			b[k] = a[k%len(a)]
+		}
+		for j := 0; j < len(i); j += v {
+			carry := 1
+			for k := v - 1; k >= 0; k-- {
+				sum := int(i[j+k]) + int(b[k]) + carry
+				i[j+k] = byte(sum)
+				carry = sum >> 8
+			}
+		}
+	}
+	return out[:size]
+}
@@ -0,0 +1,375 @@
+package authenticode
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"math/big"
+	"sort"
+	"unicode/utf16"
+)
+
+var (
+	oidSHA1   = asn1.ObjectIdentifier{1, 3, 14, 3, 2, 26}
+	oidSHA256 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 1}
+	oidSHA384 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 2}
+	oidSHA512 = asn1.ObjectIdentifier{2, 16, 840, 1, 101, 3, 4, 2, 3}
+
+	oidRSAEncryption   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidECDSAWithSHA256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 4, 3, 2}
+
+	oidSignedData        = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 7, 2}
+	oidContentTypeAttr   = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 3}
+	oidMessageDigestAttr = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 4}
+	oidTSTInfo           = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 1, 4}
+
+	oidSpcIndirectData          = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 2, 1, 4}
+	oidSpcStatementType         = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 2, 1, 11}
+	oidSpcSpOpusInfo            = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 2, 1, 12}
+	oidSpcPEImageData           = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 2, 1, 15}
+	oidSpcIndividualCodeSigning = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 2, 1, 21}
+	oidRFC3161Countersignature  = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 311, 3, 3, 1}
+)
+
+// Struktur PKCS#7 SignedData untuk parsing (RFC 2315)
+
+type signedContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     signedData `asn1:"explicit,tag:0"`
+}
+
+type signedData struct {
+	Version          int
+	DigestAlgorithms []pkix.AlgorithmIdentifier `asn1:"set"`
+	ContentInfo      encapContentInfo
+	Certificates     asn1.RawValue `asn1:"optional,tag:0"`
+	CRLs             asn1.RawValue `asn1:"optional,tag:1"`
+	SignerInfos      []signerInfo  `asn1:"set"`
+}
+
+type encapContentInfo struct {
+	ContentType asn1.ObjectIdentifier
+	Content     asn1.RawValue `asn1:"explicit,optional,tag:0"`
+}
+
+type signerInfo struct {
+	Version                   int
+	SID                       asn1.RawValue // IssuerAndSerialNumber atau [0] SubjectKeyIdentifier
+	DigestAlgorithm           pkix.AlgorithmIdentifier
+	AuthenticatedAttributes   asn1.RawValue `asn1:"optional,tag:0"`
+	DigestEncryptionAlgorithm pkix.AlgorithmIdentifier
+	EncryptedDigest           []byte
+	UnauthenticatedAttributes asn1.RawValue `asn1:"optional,tag:1"`
+}
+
+type issuerAndSerial struct {
+	Issuer       asn1.RawValue
+	SerialNumber *big.Int
+}
+
+type attribute struct {
+	Type   asn1.ObjectIdentifier
+	Values asn1.RawValue
+}
+
+type spcIndirectDataContent struct {
+	Data          asn1.RawValue
+	MessageDigest digestInfo
+}
+
+// Builder DER sederhana untuk membuat SignedData
+
+func derSequence(parts ...[]byte) []byte {
+	return appendTLV(nil, []byte{0x30}, bytes.Join(parts, nil))
+}
+
+// derSetOf membuat SET OF dengan elemen terurut sesuai aturan DER
+func derSetOf(parts ...[]byte) []byte {
+	sorted := append([][]byte(nil), parts...)
+	sort.Slice(sorted, func(i, j int) bool { return bytes.Compare(sorted[i], sorted[j]) < 0 })
+	return appendTLV(nil, []byte{0x31}, bytes.Join(sorted, nil))
+}
+
+func derTagged(tag byte, content []byte) []byte {
+	return appendTLV(nil, []byte{tag}, content)
+}
+
+func derMarshal(v any) []byte {
+	b, err := asn1.Marshal(v)
+	if err != nil {
+		// Hanya dipanggil dengan tipe sederhana (OID, integer, octets)
+		panic(err)
+	}
+	return b
+}
+
+func derAlgorithm(oid asn1.ObjectIdentifier, withNull bool) []byte {
+	if withNull {
+		return derSequence(derMarshal(oid), asn1.NullBytes)
+	}
+	return derSequence(derMarshal(oid))
+}
+
+func derAttribute(oid asn1.ObjectIdentifier, values ...[]byte) []byte {
+	return derSequence(derMarshal(oid), derSetOf(values...))
+}
+
+// contentOf mengembalikan isi elemen DER tanpa tag dan length
+func contentOf(der []byte) []byte {
+	var raw asn1.RawValue
+	if _, err := asn1.Unmarshal(der, &raw); err != nil {
+		return nil
+	}
+	return raw.Bytes
+}
+
+// buildIndirectData membuat SpcIndirectDataContent untuk digest PE
+func buildIndirectData(digest []byte) []byte {
+	// SpcLink file berisi string "<<<Obsolete>>>" seperti yang dibuat signtool
+	var obsolete []byte
+	for _, u := range utf16.Encode([]rune("<<<Obsolete>>>")) {
+		obsolete = append(obsolete, byte(u>>8), byte(u))
+	}
+	link := derTagged(0xa2, derTagged(0x80, obsolete))
+	peImageData := derSequence(
+		[]byte{0x03, 0x01, 0x00}, // flags: BIT STRING kosong
+		derTagged(0xa0, link),
+	)
+
+	return derSequence(
+		derSequence(derMarshal(oidSpcPEImageData), peImageData),
+		derSequence(derAlgorithm(oidSHA256, true), derMarshal(digest)),
+	)
+}
+
+// signIndirectData membuat SignerInfo dan SignedData Authenticode
+func signIndirectData(indirect []byte, cert *Certificate, timestamp func(sig []byte) ([]byte, error)) ([]byte, error) {
+	md := sha256.Sum256(contentOf(indirect))
+	attrs := [][]byte{
+		derAttribute(oidContentTypeAttr, derMarshal(oidSpcIndirectData)),
+		derAttribute(oidSpcSpOpusInfo, derSequence()),
+		derAttribute(oidSpcStatementType, derSequence(derMarshal(oidSpcIndividualCodeSigning))),
+		derAttribute(oidMessageDigestAttr, derMarshal(md[:])),
+	}
+	signedAttrs := derSetOf(attrs...)
+
+	var sigAlg []byte
+	switch cert.Signer.Public().(type) {
+	case *rsa.PublicKey:
+		sigAlg = derAlgorithm(oidRSAEncryption, true)
+	case *ecdsa.PublicKey:
+		sigAlg = derAlgorithm(oidECDSAWithSHA256, false)
+	default:
+		return nil, fmt.Errorf("tipe key %T tidak didukung untuk signing", cert.Signer.Public())
+	}
+
+	h := sha256.Sum256(signedAttrs)
+	sig, err := cert.Signer.Sign(rand.Reader, h[:], crypto.SHA256)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membuat signature: %w", err)
+	}
+
+	fields := [][]byte{
+		derMarshal(1),
+		derSequence(cert.Leaf.RawIssuer, derMarshal(cert.Leaf.SerialNumber)),
+		derAlgorithm(oidSHA256, true),
+		// [0] IMPLICIT: isi SET yang sama dengan yang di-sign
+		derTagged(0xa0, contentOf(signedAttrs)),
+		sigAlg,
+		derMarshal(sig),
+	}
+
+	if timestamp != nil {
+		token, err := timestamp(sig)
+		if err != nil {
+			return nil, err
+		}
+		fields = append(fields, derTagged(0xa1, derAttribute(oidRFC3161Countersignature, token)))
+	}
+
+	var certs []byte
+	for _, c := range append([]*x509.Certificate{cert.Leaf}, cert.Chain...) {
+		certs = append(certs, c.Raw...)
+	}
+
+	signed := derSequence(
+		derMarshal(1),
+		derSetOf(derAlgorithm(oidSHA256, true)),
+		derSequence(derMarshal(oidSpcIndirectData), derTagged(0xa0, indirect)),
+		derTagged(0xa0, certs),
+		derSetOf(derSequence(fields...)),
+	)
+	return derSequence(derMarshal(oidSignedData), derTagged(0xa0, signed)), nil
+}
+
+// parseSignedData mem-parse ContentInfo berisi SignedData. Padding nol di
+// belakangnya (dari alignment WIN_CERTIFICATE) diabaikan.
+func parseSignedData(data []byte) (*signedData, []*x509.Certificate, error) {
+	der, rest, err := convertBER(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("PKCS#7 tidak valid: %w", err)
+	}
+	if len(bytes.TrimRight(rest, "\x00")) > 0 {
+		return nil, nil, fmt.Errorf("PKCS#7 tidak valid: ada data sisa setelah SignedData")
+	}
+
+	var ci signedContentInfo
+	if _, err := asn1.Unmarshal(der, &ci); err != nil {
+		return nil, nil, fmt.Errorf("PKCS#7 tidak valid: %w", err)
+	}
+	if !ci.ContentType.Equal(oidSignedData) {
+		return nil, nil, fmt.Errorf("content type %v bukan SignedData", ci.ContentType)
+	}
+	sd := &ci.Content
+	if len(sd.SignerInfos) != 1 {
+		return nil, nil, fmt.Errorf("SignedData berisi %d signer, harus tepat 1", len(sd.SignerInfos))
+	}
+
+	var certs []*x509.Certificate
+	if len(sd.Certificates.Bytes) > 0 {
+		certs, err = x509.ParseCertificates(sd.Certificates.Bytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("gagal parse sertifikat: %w", err)
+		}
+	}
+	return sd, certs, nil
+}
+
+// verifySigner mengecek attribute messageDigest terhadap content dan
+// signature SignerInfo, lalu mengembalikan sertifikat penanda tangan
+func verifySigner(si *signerInfo, certs []*x509.Certificate, content []byte) (*x509.Certificate, error) {
+	signer, err := findSigner(si, certs)
+	if err != nil {
+		return nil, err
+	}
+	if signer == nil {
+		return nil, fmt.Errorf("sertifikat penanda tangan tidak ada di signature")
+	}
+
+	hashAlg, err := digestHash(si.DigestAlgorithm.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if len(si.AuthenticatedAttributes.Bytes) == 0 {
+		return nil, fmt.Errorf("signature tidak memiliki authenticated attributes")
+	}
+
+	md, err := findAttribute(si.AuthenticatedAttributes.Bytes, oidMessageDigestAttr)
+	if err != nil {
+		return nil, err
+	}
+	var expected []byte
+	if _, err := asn1.Unmarshal(md, &expected); err != nil {
+		return nil, fmt.Errorf("attribute messageDigest tidak valid: %w", err)
+	}
+	h := hashAlg.New()
+	h.Write(content)
+	if !bytes.Equal(h.Sum(nil), expected) {
+		return nil, fmt.Errorf("messageDigest tidak cocok dengan isi yang di-sign")
+	}
+
+	algo, err := signatureAlgorithm(signer, hashAlg)
+	if err != nil {
+		return nil, err
+	}
+	signedAttrs := appendTLV(nil, []byte{0x31}, si.AuthenticatedAttributes.Bytes)
+	if err := signer.CheckSignature(algo, signedAttrs, si.EncryptedDigest); err != nil {
+		return nil, fmt.Errorf("signature tidak valid: %w", err)
+	}
+	return signer, nil
+}
+
+// findSigner mencari sertifikat yang cocok dengan SignerIdentifier
+func findSigner(si *signerInfo, certs []*x509.Certificate) (*x509.Certificate, error) {
+	if si.SID.Class == asn1.ClassContextSpecific && si.SID.Tag == 0 {
+		for _, c := range certs {
+			if bytes.Equal(c.SubjectKeyId, si.SID.Bytes) {
+				return c, nil
+			}
+		}
+		return nil, nil
+	}
+
+	var ias issuerAndSerial
+	if _, err := asn1.Unmarshal(si.SID.FullBytes, &ias); err != nil {
+		return nil, fmt.Errorf("identitas signer tidak valid: %w", err)
+	}
+	for _, c := range certs {
+		if bytes.Equal(c.RawIssuer, ias.Issuer.FullBytes) && c.SerialNumber.Cmp(ias.SerialNumber) == 0 {
+			return c, nil
+		}
+	}
+	return nil, nil
+}
+
+// findAttribute mengembalikan nilai pertama attribute dengan OID tertentu
+func findAttribute(attrs []byte, oid asn1.ObjectIdentifier) ([]byte, error) {
+	rest := attrs
+	for len(rest) > 0 {
+		var attr attribute
+		var err error
+		rest, err = asn1.Unmarshal(rest, &attr)
+		if err != nil {
+			return nil, fmt.Errorf("attribute tidak valid: %w", err)
+		}
+		if attr.Type.Equal(oid) {
+			var value asn1.RawValue
+			if _, err := asn1.Unmarshal(attr.Values.Bytes, &value); err != nil {
+				return nil, fmt.Errorf("attribute %v tidak valid: %w", oid, err)
+			}
+			return value.FullBytes, nil
+		}
+	}
+	return nil, fmt.Errorf("attribute %v tidak ditemukan", oid)
+}
+
+// digestHash mengubah OID algoritma digest menjadi crypto.Hash
+func digestHash(oid asn1.ObjectIdentifier) (crypto.Hash, error) {
+	switch {
+	case oid.Equal(oidSHA1):
+		return crypto.SHA1, nil
+	case oid.Equal(oidSHA256):
+		return crypto.SHA256, nil
+	case oid.Equal(oidSHA384):
+		return crypto.SHA384, nil
+	case oid.Equal(oidSHA512):
+		return crypto.SHA512, nil
+	}
+	return 0, fmt.Errorf("algoritma digest %v tidak didukung", oid)
+}
+
+// signatureAlgorithm menentukan algoritma x509 dari tipe key sertifikat dan hash
+func signatureAlgorithm(cert *x509.Certificate, h crypto.Hash) (x509.SignatureAlgorithm, error) {
+	switch cert.PublicKeyAlgorithm {
+	case x509.RSA:
+		switch h {
+		case crypto.SHA1:
+			return x509.SHA1WithRSA, nil
+		case crypto.SHA256:
+			return x509.SHA256WithRSA, nil
+		case crypto.SHA384:
+			return x509.SHA384WithRSA, nil
+		case crypto.SHA512:
+			return x509.SHA512WithRSA, nil
+		}
+	case x509.ECDSA:
+		switch h {
+		case crypto.SHA1:
+			return x509.ECDSAWithSHA1, nil
+		case crypto.SHA256:
+			return x509.ECDSAWithSHA256, nil
+		case crypto.SHA384:
+			return x509.ECDSAWithSHA384, nil
+		case crypto.SHA512:
+			return x509.ECDSAWithSHA512, nil
+		}
+	}
+	return 0, fmt.Errorf("kombinasi key %v dan hash %v tidak didukung", cert.PublicKeyAlgorithm, h)
+}
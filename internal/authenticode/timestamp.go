@@ -0,0 +1,164 @@
+package authenticode
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+// Timestamp RFC 3161: TSA menandatangani hash dari signature kita sehingga
+// signature tetap valid setelah sertifikat code signing kedaluwarsa.
+
+type timeStampReq struct {
+	Version        int
+	MessageImprint digestInfo
+	Nonce          *big.Int
+	CertReq        bool `asn1:"optional"`
+}
+
+type timeStampResp struct {
+	Status         pkiStatusInfo
+	TimeStampToken asn1.RawValue `asn1:"optional"`
+}
+
+type pkiStatusInfo struct {
+	Status       int
+	StatusString asn1.RawValue  `asn1:"optional"`
+	FailInfo     asn1.BitString `asn1:"optional"`
+}
+
+var tsaClient = &http.Client{
+	Timeout: 30 * time.Second,
+}
+
+// requestTimestamp meminta token timestamp RFC 3161 untuk signature
+func requestTimestamp(ctx context.Context, tsaURL string, signature []byte) ([]byte, error) {
+	imprint := sha256.Sum256(signature)
+	nonce, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 64))
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := asn1.Marshal(timeStampReq{
+		Version: 1,
+		MessageImprint: digestInfo{
+			Algorithm: pkix.AlgorithmIdentifier{Algorithm: oidSHA256, Parameters: asn1.NullRawValue},
+			Digest:    imprint[:],
+		},
+		Nonce:   nonce,
+		CertReq: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, tsaURL, bytes.NewReader(req))
+	if err != nil {
+		return nil, fmt.Errorf("URL server timestamp tidak valid: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/timestamp-query")
+
+	resp, err := tsaClient.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("gagal menghubungi server timestamp: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("server timestamp: HTTP status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca respons timestamp: %w", err)
+	}
+
+	var tsr timeStampResp
+	if err := unmarshalBER(body, &tsr); err != nil {
+		return nil, fmt.Errorf("respons timestamp tidak valid: %w", err)
+	}
+	// 0 = granted, 1 = grantedWithMods
+	if tsr.Status.Status > 1 || len(tsr.TimeStampToken.FullBytes) == 0 {
+		return nil, fmt.Errorf("server timestamp menolak permintaan (status %d)", tsr.Status.Status)
+	}
+
+	info, err := parseTimestamp(tsr.TimeStampToken.FullBytes, signature)
+	if err != nil {
+		return nil, err
+	}
+	if info.nonce == nil || info.nonce.Cmp(nonce) != 0 {
+		return nil, fmt.Errorf("nonce respons timestamp tidak cocok")
+	}
+	return tsr.TimeStampToken.FullBytes, nil
+}
+
+type timestampInfo struct {
+	time   time.Time
+	signer string
+	nonce  *big.Int
+}
+
+type tstInfo struct {
+	Version        int
+	Policy         asn1.ObjectIdentifier
+	MessageImprint digestInfo
+	SerialNumber   *big.Int
+	GenTime        time.Time     `asn1:"generalized"`
+	Accuracy       asn1.RawValue `asn1:"optional"`
+	Ordering       bool          `asn1:"optional"`
+	Nonce          *big.Int      `asn1:"optional"`
+}
+
+// parseTimestamp memverifikasi token timestamp terhadap signature yang
+// di-timestamp dan mengembalikan waktu penandatanganan
+func parseTimestamp(token, signature []byte) (*timestampInfo, error) {
+	sd, certs, err := parseSignedData(token)
+	if err != nil {
+		return nil, fmt.Errorf("token timestamp: %w", err)
+	}
+	if !sd.ContentInfo.ContentType.Equal(oidTSTInfo) {
+		return nil, fmt.Errorf("token timestamp tidak berisi TSTInfo")
+	}
+
+	var content []byte
+	if _, err := asn1.Unmarshal(sd.ContentInfo.Content.Bytes, &content); err != nil {
+		return nil, fmt.Errorf("TSTInfo tidak valid: %w", err)
+	}
+	var info tstInfo
+	if err := unmarshalBER(content, &info); err != nil {
+		return nil, fmt.Errorf("TSTInfo tidak valid: %w", err)
+	}
+
+	hashAlg, err := digestHash(info.MessageImprint.Algorithm.Algorithm)
+	if err != nil {
+		return nil, fmt.Errorf("timestamp: %w", err)
+	}
+	if !bytes.Equal(hashBytes(hashAlg, signature), info.MessageImprint.Digest) {
+		return nil, fmt.Errorf("timestamp bukan untuk signature ini")
+	}
+
+	tsa, err := verifySigner(&sd.SignerInfos[0], certs, content)
+	if err != nil {
+		return nil, fmt.Errorf("signature timestamp: %w", err)
+	}
+
+	return &timestampInfo{
+		time:   info.GenTime,
+		signer: tsa.Subject.String(),
+		nonce:  info.Nonce,
+	}, nil
+}
+
+func hashBytes(h crypto.Hash, data []byte) []byte {
+	hh := h.New()
+	hh.Write(data)
+	return hh.Sum(nil)
+}
@@ -2,10 +2,19 @@ package config
 
 // AppConfig adalah konfigurasi yang di-embed ke dalam binary hasil generate
 type AppConfig struct {
+	// Versi skema config, lihat SchemaVersion dan migrate.go
+	Schema int `json:"schema,omitempty"`
+
 	// Basic
 	URL   string `json:"url"`
 	Title string `json:"title"`
 
+	// AppID adalah identitas tetap app: mutex single instance, AUMID
+	// notifikasi, entry auto-start, dan file sementara diturunkan dari sini,
+	// jadi app bisa diganti namanya tanpa kehilangan semua itu. App lama
+	// yang belum punya AppID memakai Title.
+	AppID string `json:"app_id,omitempty"`
+
 	// Window
 	Width          int    `json:"width"`
 	Height         int    `json:"height"`
@@ -16,12 +25,40 @@ type AppConfig struct {
 	StartMaximized bool   `json:"start_maximized,omitempty"`
 	TitleBarColor  string `json:"titlebar_color,omitempty"` // Hex color e.g. "#1a1a2e" or "dark"
 
+	// Placement, lihat package winstate. Jika diisi, posisi ini menang atas
+	// posisi window yang tersimpan dari peluncuran sebelumnya.
+	Monitor           string `json:"monitor,omitempty"`            // Nomor monitor (mulai 1, kiri ke kanan), "primary", atau "largest"
+	X                 *int   `json:"x,omitempty"`                  // Posisi pixel dari kiri area kerja monitor
+	Y                 *int   `json:"y,omitempty"`                  // Posisi pixel dari atas area kerja monitor
+	Position          string `json:"position,omitempty"`           // center, top-left, top, top-right, left, right, bottom-left, bottom, bottom-right
+	FullscreenMonitor string `json:"fullscreen_monitor,omitempty"` // Fullscreen di monitor ini, format sama dengan Monitor
+
 	// Behavior
 	SingleInstance     bool   `json:"single_instance,omitempty"`
 	UserAgent          string `json:"user_agent,omitempty"`
-	ClearCacheOnExit   bool   `json:"clear_cache_on_exit,omitempty"`
+	ClearCacheOnExit   bool   `json:"clear_cache_on_exit,omitempty"` // Sama dengan "cache" di ClearOnExit
 	EnableNotification bool   `json:"enable_notification,omitempty"` // Enable push notifications
 
+	// Data, lihat package appdata
+	DataDir     string   `json:"data_dir,omitempty"`      // Direktori data app; boleh berisi %VAR%, relatif terhadap folder exe
+	Portable    bool     `json:"portable,omitempty"`      // Simpan data di folder <nama exe>-data di sebelah exe
+	ClearOnExit []string `json:"clear_on_exit,omitempty"` // Data yang dihapus saat exit: cache, cookies, storage
+
+	// Profiles: setiap profil punya direktori data sendiri, dipilih dengan
+	// "app.exe --profile <nama>" atau dari menu tray
+	EnableProfiles bool     `json:"enable_profiles,omitempty"` // Tampilkan menu profil di tray
+	Profiles       []string `json:"profiles,omitempty"`        // Profil yang langsung tersedia di menu, selain Default
+
+	// Downloads, lihat package download. Nil berarti default: langsung
+	// simpan ke folder Downloads user.
+	Downloads *Downloads `json:"downloads,omitempty"`
+
+	// Permissions: izin yang diminta halaman, lihat package permission. Key
+	// adalah jenis izin (camera, microphone, geolocation, clipboard,
+	// notifications, multiple_downloads), boleh diikuti @pola untuk origin
+	// tertentu; nilainya allow, deny, atau prompt.
+	Permissions map[string]string `json:"permissions,omitempty"`
+
 	// System Tray
 	EnableTray      bool `json:"enable_tray,omitempty"`       // Enable system tray icon
 	MinimizeToTray  bool `json:"minimize_to_tray,omitempty"`  // Minimize to tray instead of taskbar
@@ -33,14 +70,87 @@ type AppConfig struct {
 	InjectCSS string `json:"inject_css,omitempty"`
 	InjectJS  string `json:"inject_js,omitempty"`
 
+	// Userscripts: script yang hanya dijalankan di halaman yang cocok, lihat
+	// package userscript
+	UserScripts []UserScript `json:"userscripts,omitempty"`
+
 	// Navigation
-	Whitelist        []string `json:"whitelist,omitempty"`
-	BlockExternalNav bool     `json:"block_external_nav,omitempty"`
+	Whitelist        []string  `json:"whitelist,omitempty"`
+	BlockExternalNav bool      `json:"block_external_nav,omitempty"`
+	NavRules         []NavRule `json:"nav_rules,omitempty"` // Dievaluasi sebelum whitelist, aturan pertama yang cocok menang
+
+	// Popups: URL yang dibuka halaman di window baru (window.open, target=_blank)
+	PopupPolicy string   `json:"popup_policy,omitempty"` // same (default), window, browser, atau block
+	AuthDomains []string `json:"auth_domains,omitempty"` // Domain login (OAuth) yang selalu dibuka di window kedua, format seperti whitelist
+
+	// Network: request yang dibuat halaman, lihat package netfilter. Nil
+	// berarti request tidak diperiksa.
+	Network *Network `json:"network,omitempty"`
+
+	// Deep links: URL dari argumen atau custom scheme yang dibuka di dalam app
+	DeepLinks []DeepLink `json:"deep_links,omitempty"`
+
+	// Protocols: scheme URL yang diklaim app di Windows
+	Protocols []Protocol `json:"protocols,omitempty"`
 
 	// Advanced
 	DisableContextMenu bool `json:"disable_context_menu,omitempty"`
 	DisableDevTools    bool `json:"disable_devtools,omitempty"`
 }
 
-// ConfigMarker adalah marker unik untuk menemukan config di tail binary
+// NavRule adalah aturan navigasi untuk satu pola URL
+type NavRule struct {
+	Pattern string `json:"pattern"` // e.g. "*.example.com", "example.com/docs/*"
+	Action  string `json:"action"`  // "allow", "external", atau "block"
+}
+
+// DeepLink memetakan URL yang cocok dengan pola ke URL di dalam app
+type DeepLink struct {
+	Pattern string `json:"pattern"`          // Pola URL seperti nav_rules, e.g. "myapp://route/*", "app.example.com/x/*"
+	Target  string `json:"target,omitempty"` // e.g. "https://app.example.com/{host}{path}{query}"; kosong berarti URL dibuka apa adanya
+}
+
+// Protocol adalah scheme URL yang didaftarkan ke Windows untuk app ini. URL
+// dengan scheme tersebut dibuka di app lewat template Target.
+type Protocol struct {
+	Scheme string `json:"scheme"` // e.g. "web+ourtool", "mailto"
+	Target string `json:"target"` // e.g. "https://app.example.com/open?uri=%s"; placeholder sama dengan DeepLink
+}
+
+// Downloads mengatur file yang diunduh dari app
+type Downloads struct {
+	Folder       string   `json:"folder,omitempty"`        // Folder tujuan; boleh berisi %VAR%, relatif terhadap folder exe. Default: folder Downloads user
+	Mode         string   `json:"mode,omitempty"`          // auto (default): langsung simpan, ask: selalu tanya lokasi simpan
+	AllowedTypes []string `json:"allowed_types,omitempty"` // Ekstensi yang boleh diunduh, e.g. ".pdf", ".csv"; kosong berarti semua
+}
+
+// UserScript adalah satu userscript, biasanya dari file .user.js
+type UserScript struct {
+	Name     string   `json:"name,omitempty"`
+	Match    []string `json:"match,omitempty"`    // Pola URL (@match, @include); kosong berarti semua halaman
+	Exclude  []string `json:"exclude,omitempty"`  // Pola URL yang dikecualikan
+	RunAt    string   `json:"run_at,omitempty"`   // document-start, document-end (default), atau idle
+	Isolated bool     `json:"isolated,omitempty"` // Jalankan di dalam function sendiri supaya variabel tidak bocor ke window
+	Code     string   `json:"code"`
+}
+
+// Network mengatur request yang dibuat halaman
+type Network struct {
+	Headers    []HeaderRule `json:"headers,omitempty"`     // Header tambahan untuk request yang cocok, semua aturan yang cocok dipakai
+	Block      []string     `json:"block,omitempty"`       // Pola URL yang diblokir, format seperti nav_rules
+	FilterList string       `json:"filter_list,omitempty"` // Isi filter list Adblock (EasyList, ...), di-embed saat build
+}
+
+// HeaderRule menambahkan header ke request yang URL-nya cocok dengan pola
+type HeaderRule struct {
+	Pattern string            `json:"pattern"` // Pola URL seperti nav_rules, e.g. "api.example.com"
+	Headers map[string]string `json:"headers"` // e.g. {"X-Tenant": "acme"}
+}
+
+// ResourceName adalah nama resource RCDATA tempat config disimpan di app Windows.
+// App lama menyimpan config di trailer (lihat ConfigMarker dan trailer.go).
+const ResourceName = "W2APP_CONFIG"
+
+// ConfigMarker adalah marker format lama (V1) untuk menemukan config di tail binary.
+// App baru memakai ConfigMarkerV2 dengan footer, lihat trailer.go.
 const ConfigMarker = "\n---W2APP_CONFIG_V1---\n"
@@ -0,0 +1,250 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/user/w2app/internal/appdata"
+	"github.com/user/w2app/internal/download"
+	"github.com/user/w2app/internal/protocol"
+	"github.com/user/w2app/internal/winstate"
+)
+
+// Change adalah satu perubahan config berdasarkan nama field JSON-nya
+type Change struct {
+	Key   string
+	Value string // Diabaikan jika Unset
+	Unset bool   // Kembalikan field ke nilai kosong
+}
+
+// Keys mengembalikan semua nama field JSON AppConfig secara berurutan
+func Keys() []string {
+	t := reflect.TypeOf(AppConfig{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if key := jsonKey(t.Field(i)); key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+func jsonKey(f reflect.StructField) string {
+	key := strings.Split(f.Tag.Get("json"), ",")[0]
+	if key == "-" {
+		return ""
+	}
+	return key
+}
+
+func fieldByKey(key string) (reflect.StructField, bool) {
+	t := reflect.TypeOf(AppConfig{})
+	for i := 0; i < t.NumField(); i++ {
+		if jsonKey(t.Field(i)) == key {
+			return t.Field(i), true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// ApplyChanges menerapkan perubahan ke config JSON mentah dan mengembalikan
+// hasilnya. Field yang tidak disebut tetap seperti aslinya; field yang tidak
+// dikenal di config asli membuat proses gagal supaya tidak hilang diam-diam.
+//
+// Nilai untuk field string dipakai apa adanya. Field lain harus berupa JSON
+// (true, 1280, ["a","b"]); khusus whitelist juga menerima daftar dipisah koma.
+func ApplyChanges(raw []byte, changes []Change) (*AppConfig, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, fmt.Errorf("config asli tidak valid: %w", err)
+	}
+
+	seen := map[string]bool{}
+	for _, c := range changes {
+		field, ok := fieldByKey(c.Key)
+		if !ok {
+			return nil, fmt.Errorf("field %q tidak dikenal (field yang tersedia: %s)", c.Key, strings.Join(Keys(), ", "))
+		}
+		if seen[c.Key] {
+			return nil, fmt.Errorf("field %q diubah lebih dari sekali", c.Key)
+		}
+		seen[c.Key] = true
+
+		if c.Unset {
+			delete(fields, c.Key)
+			continue
+		}
+
+		value, err := encodeValue(field.Type, c.Value)
+		if err != nil {
+			return nil, fmt.Errorf("nilai %s: %w", c.Key, err)
+		}
+		fields[c.Key] = value
+	}
+
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg AppConfig
+	dec := json.NewDecoder(bytes.NewReader(merged))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, fmt.Errorf("config hasil perubahan tidak valid: %w", err)
+	}
+	return &cfg, nil
+}
+
+// encodeValue mengubah nilai dari command line menjadi JSON sesuai tipe field
+func encodeValue(t reflect.Type, value string) (json.RawMessage, error) {
+	if t.Kind() == reflect.String {
+		return json.Marshal(value)
+	}
+
+	if t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.String && !strings.HasPrefix(strings.TrimSpace(value), "[") {
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		return json.Marshal(items)
+	}
+
+	// Pastikan JSON cocok dengan tipe field sebelum digabung
+	ptr := reflect.New(t)
+	if err := json.Unmarshal([]byte(value), ptr.Interface()); err != nil {
+		return nil, fmt.Errorf("%q bukan nilai %s yang valid", value, typeName(t))
+	}
+	return json.RawMessage(value), nil
+}
+
+func typeName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean (true/false)"
+	case reflect.Int:
+		return "angka"
+	case reflect.Slice:
+		return "array JSON"
+	case reflect.Pointer:
+		return typeName(t.Elem())
+	}
+	return t.String()
+}
+
+// MaxAppIDLength adalah panjang maksimal AppID, sama dengan batas AUMID Windows
+const MaxAppIDLength = 128
+
+// CheckAppID mengecek format AppID: huruf, angka, titik, strip, dan underscore,
+// misalnya "com.example.chat" atau GUID. Kosong berarti app lama tanpa AppID.
+func CheckAppID(id string) error {
+	if id == "" {
+		return nil
+	}
+	if len(id) > MaxAppIDLength {
+		return fmt.Errorf("app_id maksimal %d karakter", MaxAppIDLength)
+	}
+	for i, r := range id {
+		alnum := r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9'
+		if !alnum && (i == 0 || !strings.ContainsRune(".-_", r)) {
+			return fmt.Errorf("app_id %q hanya boleh berisi huruf, angka, titik, strip, dan underscore, diawali huruf atau angka", id)
+		}
+	}
+	return nil
+}
+
+// Validate mengecek nilai-nilai config yang wajib dan formatnya
+func (c *AppConfig) Validate() error {
+	if c.URL == "" {
+		return fmt.Errorf("url tidak boleh kosong")
+	}
+	u, err := url.Parse(c.URL)
+	if err != nil {
+		return fmt.Errorf("url tidak valid: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("url harus diawali http:// atau https://")
+	}
+	if u.Host == "" {
+		return fmt.Errorf("url tidak memiliki host")
+	}
+
+	if strings.TrimSpace(c.Title) == "" {
+		return fmt.Errorf("title tidak boleh kosong")
+	}
+	if err := CheckAppID(c.AppID); err != nil {
+		return err
+	}
+	// 0 berarti ukuran default stub
+	if c.Width < 0 || c.Height < 0 {
+		return fmt.Errorf("ukuran window %dx%d tidak valid", c.Width, c.Height)
+	}
+
+	switch color := strings.ToLower(c.TitleBarColor); color {
+	case "", "dark", "light":
+	default:
+		hex := strings.TrimPrefix(color, "#")
+		if len(hex) != 6 || strings.Trim(hex, "0123456789abcdef") != "" {
+			return fmt.Errorf("titlebar_color %q harus #RRGGBB, dark, atau light", c.TitleBarColor)
+		}
+	}
+
+	if c.DataDir != "" && c.Portable {
+		return fmt.Errorf("data_dir dan portable tidak bisa dipakai bersamaan")
+	}
+	if err := appdata.CheckClearTargets(c.ClearOnExit); err != nil {
+		return err
+	}
+	seen := map[string]bool{}
+	for _, p := range c.Profiles {
+		if err := appdata.CheckProfileName(p); err != nil {
+			return err
+		}
+		if seen[strings.ToLower(p)] {
+			return fmt.Errorf("profil %q disebut lebih dari sekali", p)
+		}
+		seen[strings.ToLower(p)] = true
+	}
+
+	if d := c.Downloads; d != nil {
+		if err := download.CheckMode(d.Mode); err != nil {
+			return err
+		}
+		if err := download.CheckTypes(d.AllowedTypes); err != nil {
+			return err
+		}
+	}
+
+	if err := winstate.CheckMonitor(c.Monitor); err != nil {
+		return err
+	}
+	if err := winstate.CheckMonitor(c.FullscreenMonitor); err != nil {
+		return fmt.Errorf("fullscreen_monitor: %w", err)
+	}
+	if err := winstate.CheckPosition(c.Position); err != nil {
+		return err
+	}
+
+	schemes := map[string]bool{}
+	for i, p := range c.Protocols {
+		if err := protocol.Check(p.Scheme); err != nil {
+			return fmt.Errorf("protocols[%d]: %w", i, err)
+		}
+		if strings.TrimSpace(p.Target) == "" {
+			return fmt.Errorf("protocols[%d]: target wajib diisi", i)
+		}
+		scheme := protocol.Normalize(p.Scheme)
+		if schemes[scheme] {
+			return fmt.Errorf("protocol %q disebut lebih dari sekali", scheme)
+		}
+		schemes[scheme] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/tc-hib/winres"
+)
+
+// Lokasi penyimpanan config di dalam executable
+const (
+	StorageResource = "resource" // RCDATA ResourceName di section .rsrc (Windows)
+	StorageTrailer  = "trailer"  // Marker + JSON (+ footer) di akhir file
+)
+
+// Embedded adalah config yang dibaca dari executable beserta lokasinya
+type Embedded struct {
+	Config   *AppConfig
+	Raw      []byte   // Config JSON persis seperti yang tersimpan
+	Storage  string   // StorageResource atau StorageTrailer
+	Trailer  *Trailer // Lokasi trailer, nil jika Storage == StorageResource
+	FileSize int64
+	StubSize int64 // Ukuran executable tanpa trailer config
+}
+
+// Decode mem-parse config JSON yang tersimpan di executable
+func Decode(data []byte) (*AppConfig, error) {
+	var cfg AppConfig
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("gagal parse config JSON: %w", err)
+	}
+	return &cfg, nil
+}
+
+// ReadEmbedded membaca config dari executable di path. Resource RCDATA
+// dicoba lebih dulu (app Windows baru), lalu trailer V2 dan V1 (app lama
+// dan platform non-Windows).
+func ReadEmbedded(path string) (*Embedded, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	emb := &Embedded{FileSize: info.Size(), StubSize: info.Size()}
+
+	// File yang bukan PE (atau PE tanpa .rsrc) langsung lanjut ke trailer
+	if rs, err := winres.LoadFromEXESingleType(f, winres.RT_RCDATA); err == nil {
+		if data := rs.Get(winres.RT_RCDATA, winres.Name(ResourceName), winres.LCIDNeutral); data != nil {
+			cfg, err := Decode(data)
+			if err != nil {
+				return nil, err
+			}
+			emb.Config = cfg
+			emb.Raw = data
+			emb.Storage = StorageResource
+			return emb, nil
+		}
+	}
+
+	payload, trailer, err := ReadTrailerPayload(f, info.Size())
+	if err != nil {
+		if errors.Is(err, ErrNoConfig) {
+			return nil, fmt.Errorf("%s bukan app w2app: %w", path, err)
+		}
+		return nil, err
+	}
+	cfg, err := Decode(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	emb.Config = cfg
+	emb.Raw = payload
+	emb.Storage = StorageTrailer
+	emb.Trailer = trailer
+	emb.StubSize = trailer.Offset
+	return emb, nil
+}
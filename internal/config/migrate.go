@@ -0,0 +1,84 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaVersion adalah versi skema config yang ditulis generator saat ini.
+// Naikkan setiap kali arti field berubah, atau ada field baru yang wajib
+// dijalankan stub (stub lama akan diam-diam mengabaikannya), dan tambahkan
+// migrasinya di migrations. Stub dengan skema lebih rendah ditolak generator.
+const SchemaVersion = 2
+
+// migrations[i] memigrasi config dari skema i ke skema i+1
+var migrations = []func(fields map[string]json.RawMessage) error{
+	migrateV0,
+	migrateV1,
+}
+
+// Migrate mem-parse config JSON mentah dan menjalankan migrasi dari skema
+// aslinya sampai SchemaVersion. Config dari skema yang lebih baru dari
+// generator ini ditolak supaya field yang belum dikenal tidak hilang.
+func Migrate(raw []byte) (*AppConfig, int, error) {
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil, 0, fmt.Errorf("gagal parse config JSON: %w", err)
+	}
+
+	from := 0
+	if v, ok := fields["schema"]; ok {
+		if err := json.Unmarshal(v, &from); err != nil {
+			return nil, 0, fmt.Errorf("schema config tidak valid: %w", err)
+		}
+	}
+	if from > SchemaVersion {
+		return nil, from, fmt.Errorf("config memakai skema %d, w2app ini hanya mendukung sampai skema %d", from, SchemaVersion)
+	}
+
+	for v := from; v < SchemaVersion; v++ {
+		if err := migrations[v](fields); err != nil {
+			return nil, from, fmt.Errorf("migrasi config skema %d ke %d gagal: %w", v, v+1, err)
+		}
+	}
+	fields["schema"] = json.RawMessage(fmt.Sprint(SchemaVersion))
+
+	merged, err := json.Marshal(fields)
+	if err != nil {
+		return nil, from, err
+	}
+	cfg, err := Decode(merged)
+	if err != nil {
+		return nil, from, err
+	}
+	return cfg, from, nil
+}
+
+// migrateV0 menangani config sebelum ada field schema. Ukuran window 0 dulu
+// berarti "pakai default stub"; nilainya ditulis eksplisit supaya tampilan
+// app tidak ikut berubah jika default di stub baru berubah.
+func migrateV0(fields map[string]json.RawMessage) error {
+	defaults := map[string]int{"width": 1024, "height": 768}
+	for key, def := range defaults {
+		var n int
+		if v, ok := fields[key]; ok {
+			if err := json.Unmarshal(v, &n); err != nil {
+				return fmt.Errorf("%s: %w", key, err)
+			}
+		}
+		if n <= 0 {
+			fields[key] = json.RawMessage(fmt.Sprint(def))
+		}
+	}
+	return nil
+}
+
+// migrateV1 menandai config yang bisa berisi app_id, placement, data dir,
+// profil, deep link, protocol, popup, download, permissions, network, dan
+// userscripts. Semua field itu opsional dan nilai kosongnya sama dengan
+// perilaku skema 1, jadi tidak ada yang diubah; skema baru dibutuhkan supaya
+// stub skema 1, yang mengabaikan misalnya network.block dan permissions,
+// tidak dipakai lagi.
+func migrateV1(fields map[string]json.RawMessage) error {
+	return nil
+}
@@ -0,0 +1,48 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMigrate(t *testing.T) {
+	tests := []struct {
+		name      string
+		raw       string
+		from      int
+		width     int
+		height    int
+		resizable bool
+	}{
+		{"skema 0", `{"url":"https://a.com","title":"A","width":0,"resizable":true}`, 0, 1024, 768, true},
+		{"skema 0 dengan ukuran", `{"url":"https://a.com","width":800,"height":600}`, 0, 800, 600, false},
+		{"skema 1", `{"schema":1,"url":"https://a.com","width":500,"height":400}`, 1, 500, 400, false},
+		{"skema saat ini", `{"schema":2,"url":"https://a.com","width":500,"height":400}`, 2, 500, 400, false},
+	}
+	for _, tt := range tests {
+		cfg, from, err := Migrate([]byte(tt.raw))
+		if err != nil {
+			t.Errorf("%s: Migrate: %v", tt.name, err)
+			continue
+		}
+		if from != tt.from || cfg.Schema != SchemaVersion {
+			t.Errorf("%s: skema %d -> %d, want %d -> %d", tt.name, from, cfg.Schema, tt.from, SchemaVersion)
+		}
+		if cfg.Width != tt.width || cfg.Height != tt.height || cfg.Resizable != tt.resizable {
+			t.Errorf("%s: config = %+v", tt.name, cfg)
+		}
+	}
+}
+
+func TestMigrateError(t *testing.T) {
+	for raw, want := range map[string]string{
+		`{"schema":99,"url":"https://a.com"}`: "skema 99",
+		`{"schema":"1"}`:                      "schema config",
+		`{"width":"lebar"}`:                   "migrasi config skema 0",
+		`[1,2]`:                               "parse",
+	} {
+		if _, _, err := Migrate([]byte(raw)); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("Migrate(%s) = %v, want %q", raw, err, want)
+		}
+	}
+}
@@ -0,0 +1,171 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Format trailer V2:
+//
+//	[stub binary][ConfigMarkerV2][config JSON][footer]
+//
+// Footer berukuran tetap (TrailerFooterSize byte, little-endian):
+//
+//	length   uint64   panjang config JSON
+//	version  uint32   versi format trailer (TrailerVersion)
+//	reserved uint32
+//	checksum [32]byte SHA-256 dari config JSON
+//	magic    [8]byte  TrailerMagic
+//
+// Dengan footer di posisi tetap, stub cukup membaca bagian akhir file tanpa
+// harus memuat seluruh executable ke memori.
+const (
+	ConfigMarkerV2    = "\n---W2APP_CONFIG_V2---\n"
+	TrailerMagic      = "W2APPCFG"
+	TrailerVersion    = 2
+	TrailerFooterSize = 8 + 4 + 4 + sha256.Size + len(TrailerMagic)
+
+	// maxConfigSize membatasi ukuran config supaya footer yang rusak tidak
+	// membuat kita mengalokasikan memori secara berlebihan
+	maxConfigSize = 64 << 20
+)
+
+var (
+	// ErrNoConfig dikembalikan jika file tidak berisi config w2app
+	ErrNoConfig = errors.New("config tidak ditemukan")
+	// ErrCorrupt dikembalikan jika config ada tetapi rusak atau terpotong
+	ErrCorrupt = errors.New("config rusak")
+)
+
+// Trailer menjelaskan lokasi config di dalam file
+type Trailer struct {
+	Version int   // 1 = marker legacy, 2 = marker + footer
+	Offset  int64 // Offset awal marker (ukuran stub + resource)
+	Size    int64 // Total ukuran trailer (marker + JSON + footer)
+}
+
+// EncodeTrailer membuat trailer V2 lengkap untuk config JSON
+func EncodeTrailer(payload []byte) []byte {
+	buf := make([]byte, 0, len(ConfigMarkerV2)+len(payload)+TrailerFooterSize)
+	buf = append(buf, ConfigMarkerV2...)
+	buf = append(buf, payload...)
+
+	var footer [TrailerFooterSize]byte
+	binary.LittleEndian.PutUint64(footer[0:8], uint64(len(payload)))
+	binary.LittleEndian.PutUint32(footer[8:12], TrailerVersion)
+	sum := sha256.Sum256(payload)
+	copy(footer[16:16+sha256.Size], sum[:])
+	copy(footer[16+sha256.Size:], TrailerMagic)
+
+	return append(buf, footer[:]...)
+}
+
+// WriteTrailer menulis config sebagai trailer V2 ke w
+func WriteTrailer(w io.Writer, cfg *AppConfig) error {
+	payload, err := json.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("gagal serialize config: %w", err)
+	}
+	_, err = w.Write(EncodeTrailer(payload))
+	return err
+}
+
+// ReadTrailer membaca config dari akhir file berukuran size.
+// Trailer V2 dibaca langsung dari footer, app lama (V1) dicari lewat marker.
+func ReadTrailer(r io.ReaderAt, size int64) (*AppConfig, *Trailer, error) {
+	payload, trailer, err := ReadTrailerPayload(r, size)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cfg, err := Decode(payload)
+	if err != nil {
+		return nil, nil, err
+	}
+	return cfg, trailer, nil
+}
+
+// ReadTrailerPayload seperti ReadTrailer tetapi mengembalikan config JSON mentah
+func ReadTrailerPayload(r io.ReaderAt, size int64) ([]byte, *Trailer, error) {
+	if size >= int64(TrailerFooterSize) {
+		var footer [TrailerFooterSize]byte
+		if _, err := r.ReadAt(footer[:], size-int64(TrailerFooterSize)); err != nil {
+			return nil, nil, fmt.Errorf("gagal membaca footer config: %w", err)
+		}
+		if string(footer[16+sha256.Size:]) == TrailerMagic {
+			return readTrailerV2(r, size, footer[:])
+		}
+	}
+	return readTrailerV1(r, size)
+}
+
+func readTrailerV2(r io.ReaderAt, size int64, footer []byte) ([]byte, *Trailer, error) {
+	length := binary.LittleEndian.Uint64(footer[0:8])
+	version := binary.LittleEndian.Uint32(footer[8:12])
+	if version != TrailerVersion {
+		return nil, nil, fmt.Errorf("%w: versi trailer %d tidak didukung", ErrCorrupt, version)
+	}
+
+	total := int64(len(ConfigMarkerV2)) + int64(length) + int64(TrailerFooterSize)
+	if length > maxConfigSize || total > size {
+		return nil, nil, fmt.Errorf("%w: file terpotong (config %d byte, file %d byte)", ErrCorrupt, length, size)
+	}
+
+	offset := size - total
+	data := make([]byte, int64(len(ConfigMarkerV2))+int64(length))
+	if _, err := r.ReadAt(data, offset); err != nil {
+		return nil, nil, fmt.Errorf("gagal membaca config: %w", err)
+	}
+	if string(data[:len(ConfigMarkerV2)]) != ConfigMarkerV2 {
+		return nil, nil, fmt.Errorf("%w: marker tidak ditemukan di posisi yang diharapkan", ErrCorrupt)
+	}
+
+	payload := data[len(ConfigMarkerV2):]
+	sum := sha256.Sum256(payload)
+	if !bytes.Equal(sum[:], footer[16:16+sha256.Size]) {
+		return nil, nil, fmt.Errorf("%w: checksum tidak cocok", ErrCorrupt)
+	}
+
+	return payload, &Trailer{Version: TrailerVersion, Offset: offset, Size: total}, nil
+}
+
+// readTrailerV1 mencari ConfigMarker lama. Format ini tidak punya footer,
+// jadi seluruh file harus dibaca.
+func readTrailerV1(r io.ReaderAt, size int64) ([]byte, *Trailer, error) {
+	data, err := io.ReadAll(io.NewSectionReader(r, 0, size))
+	if err != nil {
+		return nil, nil, fmt.Errorf("gagal membaca executable: %w", err)
+	}
+
+	idx := bytes.LastIndex(data, []byte(ConfigMarker))
+	if idx == -1 {
+		if bytes.Contains(data, []byte(ConfigMarkerV2)) {
+			return nil, nil, fmt.Errorf("%w: footer config tidak ditemukan, file kemungkinan terpotong", ErrCorrupt)
+		}
+		return nil, nil, ErrNoConfig
+	}
+
+	payload := bytes.TrimRight(data[idx+len(ConfigMarker):], "\x00 \n\r\t")
+	return payload, &Trailer{Version: 1, Offset: int64(idx), Size: size - int64(idx)}, nil
+}
+
+// ReadFile membaca config dari trailer file di path
+func ReadFile(path string) (*AppConfig, *Trailer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, nil, err
+	}
+	return ReadTrailer(f, info.Size())
+}
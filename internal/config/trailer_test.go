@@ -0,0 +1,230 @@
+package config
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tc-hib/winres"
+)
+
+var testStub = []byte("MZ stub binary\x00\x01\x02" + strings.Repeat("x", 200))
+
+func testConfig() *AppConfig {
+	return &AppConfig{Schema: SchemaVersion, URL: "https://chat.example.com", Title: "Chat", Width: 800, Height: 600}
+}
+
+func withTrailer(t *testing.T, stub []byte, cfg *AppConfig) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	buf.Write(stub)
+	if err := WriteTrailer(&buf, cfg); err != nil {
+		t.Fatalf("WriteTrailer: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestTrailerV2RoundTrip(t *testing.T) {
+	data := withTrailer(t, testStub, testConfig())
+	cfg, trailer, err := ReadTrailer(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("ReadTrailer: %v", err)
+	}
+	if cfg.URL != "https://chat.example.com" || cfg.Title != "Chat" || cfg.Width != 800 {
+		t.Errorf("config = %+v", cfg)
+	}
+	if trailer.Version != TrailerVersion || trailer.Offset != int64(len(testStub)) || trailer.Size != int64(len(data)-len(testStub)) {
+		t.Errorf("trailer = %+v", trailer)
+	}
+
+	// Marker V1 di dalam stub tidak mengganggu trailer V2
+	stub := append([]byte(ConfigMarker+`{"url":"https://lama.example.com"}`), testStub...)
+	data = withTrailer(t, stub, testConfig())
+	if cfg, trailer, err := ReadTrailer(bytes.NewReader(data), int64(len(data))); err != nil || cfg.Title != "Chat" || trailer.Offset != int64(len(stub)) {
+		t.Errorf("ReadTrailer dengan marker V1 di stub = %+v, %+v, %v", cfg, trailer, err)
+	}
+}
+
+func TestTrailerV1(t *testing.T) {
+	// App lama: marker V1, JSON, lalu padding
+	json := `{"url":"https://old.example.com","title":"Old","width":640}`
+	data := append(append(append([]byte{}, testStub...), ConfigMarker...), json+"\n\x00\x00"...)
+	cfg, trailer, err := ReadTrailer(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatalf("ReadTrailer V1: %v", err)
+	}
+	if cfg.URL != "https://old.example.com" || cfg.Width != 640 {
+		t.Errorf("config = %+v", cfg)
+	}
+	if trailer.Version != 1 || trailer.Offset != int64(len(testStub)) || trailer.Size != int64(len(data)-len(testStub)) {
+		t.Errorf("trailer = %+v", trailer)
+	}
+
+	// Marker terakhir yang dipakai
+	data = append(data, ConfigMarker+`{"title":"Baru"}`...)
+	if cfg, _, err := ReadTrailer(bytes.NewReader(data), int64(len(data))); err != nil || cfg.Title != "Baru" {
+		t.Errorf("marker terakhir = %+v, %v", cfg, err)
+	}
+}
+
+func TestTrailerErrors(t *testing.T) {
+	valid := withTrailer(t, testStub, testConfig())
+	footer := len(valid) - TrailerFooterSize
+	payloadStart := len(testStub) + len(ConfigMarkerV2)
+
+	modify := func(f func(b []byte) []byte) []byte {
+		return f(append([]byte{}, valid...))
+	}
+	tests := []struct {
+		name string
+		data []byte
+		err  error
+		want string
+	}{
+		{"tanpa config", testStub, ErrNoConfig, "tidak ditemukan"},
+		{"file kosong", nil, ErrNoConfig, "tidak ditemukan"},
+		{"footer terpotong", valid[:len(valid)-10], ErrCorrupt, "footer config tidak ditemukan"},
+		{"config terpotong", valid[len(testStub)+len(ConfigMarkerV2)+5:], ErrCorrupt, "terpotong"},
+		{"panjang terlalu besar", modify(func(b []byte) []byte {
+			binary.LittleEndian.PutUint64(b[footer:], uint64(len(b)))
+			return b
+		}), ErrCorrupt, "terpotong"},
+		{"panjang melebihi batas", modify(func(b []byte) []byte {
+			binary.LittleEndian.PutUint64(b[footer:], 1<<62)
+			return b
+		}), ErrCorrupt, "terpotong"},
+		{"panjang terlalu kecil", modify(func(b []byte) []byte {
+			n := binary.LittleEndian.Uint64(b[footer:])
+			binary.LittleEndian.PutUint64(b[footer:], n-3)
+			return b
+		}), ErrCorrupt, "marker"},
+		{"versi", modify(func(b []byte) []byte {
+			binary.LittleEndian.PutUint32(b[footer+8:], 3)
+			return b
+		}), ErrCorrupt, "versi trailer 3"},
+		{"checksum", modify(func(b []byte) []byte {
+			b[payloadStart+2] ^= 0x20
+			return b
+		}), ErrCorrupt, "checksum"},
+		{"checksum footer", modify(func(b []byte) []byte {
+			b[footer+16] ^= 0xff
+			return b
+		}), ErrCorrupt, "checksum"},
+	}
+	for _, tt := range tests {
+		_, _, err := ReadTrailer(bytes.NewReader(tt.data), int64(len(tt.data)))
+		if !errors.Is(err, tt.err) || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want %v (%q)", tt.name, err, tt.err, tt.want)
+		}
+	}
+}
+
+func TestEncodeTrailer(t *testing.T) {
+	payload := []byte(`{"url":"https://a.com"}`)
+	data := EncodeTrailer(payload)
+	if len(data) != len(ConfigMarkerV2)+len(payload)+TrailerFooterSize {
+		t.Fatalf("panjang trailer = %d", len(data))
+	}
+	footer := data[len(data)-TrailerFooterSize:]
+	sum := sha256.Sum256(payload)
+	if binary.LittleEndian.Uint64(footer[0:8]) != uint64(len(payload)) ||
+		binary.LittleEndian.Uint32(footer[8:12]) != TrailerVersion ||
+		!bytes.Equal(footer[16:16+sha256.Size], sum[:]) ||
+		string(footer[len(footer)-len(TrailerMagic):]) != TrailerMagic {
+		t.Errorf("footer = %x", footer)
+	}
+}
+
+func TestReadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "app")
+	if err := os.WriteFile(path, withTrailer(t, testStub, testConfig()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	cfg, trailer, err := ReadFile(path)
+	if err != nil || cfg.Title != "Chat" || trailer.Offset != int64(len(testStub)) {
+		t.Errorf("ReadFile = %+v, %+v, %v", cfg, trailer, err)
+	}
+	if _, _, err := ReadFile(filepath.Join(t.TempDir(), "tidak-ada")); !os.IsNotExist(err) {
+		t.Errorf("ReadFile file tidak ada = %v", err)
+	}
+}
+
+func TestReadEmbeddedTrailer(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "app")
+	data := withTrailer(t, testStub, testConfig())
+	if err := os.WriteFile(path, data, 0755); err != nil {
+		t.Fatal(err)
+	}
+	emb, err := ReadEmbedded(path)
+	if err != nil {
+		t.Fatalf("ReadEmbedded: %v", err)
+	}
+	if emb.Storage != StorageTrailer || emb.Config.Title != "Chat" || emb.StubSize != int64(len(testStub)) ||
+		emb.FileSize != int64(len(data)) || emb.Trailer == nil || !strings.Contains(string(emb.Raw), `"title":"Chat"`) {
+		t.Errorf("ReadEmbedded = %+v", emb)
+	}
+
+	other := filepath.Join(dir, "other")
+	if err := os.WriteFile(other, testStub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadEmbedded(other); !errors.Is(err, ErrNoConfig) || !strings.Contains(err.Error(), "bukan app w2app") {
+		t.Errorf("ReadEmbedded bukan app = %v", err)
+	}
+}
+
+// Resource RCDATA dicoba lebih dulu daripada trailer
+func TestReadEmbeddedResource(t *testing.T) {
+	stub, err := os.ReadFile(filepath.Join("..", "generator", "stubs", "stub-windows-amd64.exe"))
+	if err != nil {
+		t.Skipf("stub tidak tersedia: %v", err)
+	}
+
+	var rs winres.ResourceSet
+	resource := []byte(`{"url":"https://resource.example.com","title":"Resource"}`)
+	if err := rs.Set(winres.RT_RCDATA, winres.Name(ResourceName), winres.LCIDNeutral, resource); err != nil {
+		t.Fatal(err)
+	}
+	var exe bytes.Buffer
+	if err := rs.WriteToEXE(&exe, bytes.NewReader(stub)); err != nil {
+		t.Fatalf("WriteToEXE: %v", err)
+	}
+	dir := t.TempDir()
+
+	// Resource saja
+	path := filepath.Join(dir, "resource.exe")
+	if err := os.WriteFile(path, exe.Bytes(), 0755); err != nil {
+		t.Fatal(err)
+	}
+	emb, err := ReadEmbedded(path)
+	if err != nil {
+		t.Fatalf("ReadEmbedded: %v", err)
+	}
+	if emb.Storage != StorageResource || emb.Config.Title != "Resource" || emb.Trailer != nil || !bytes.Equal(emb.Raw, resource) {
+		t.Errorf("ReadEmbedded = %+v", emb)
+	}
+
+	// Resource dan trailer: resource menang
+	both := filepath.Join(dir, "both.exe")
+	if err := os.WriteFile(both, withTrailer(t, exe.Bytes(), testConfig()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if emb, err := ReadEmbedded(both); err != nil || emb.Storage != StorageResource || emb.Config.Title != "Resource" {
+		t.Errorf("ReadEmbedded resource dan trailer = %+v, %v", emb, err)
+	}
+
+	// PE tanpa resource config memakai trailer
+	trailer := filepath.Join(dir, "trailer.exe")
+	if err := os.WriteFile(trailer, withTrailer(t, stub, testConfig()), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if emb, err := ReadEmbedded(trailer); err != nil || emb.Storage != StorageTrailer || emb.Config.Title != "Chat" || emb.StubSize != int64(len(stub)) {
+		t.Errorf("ReadEmbedded PE dengan trailer = %+v, %v", emb, err)
+	}
+}
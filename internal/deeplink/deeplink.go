@@ -0,0 +1,164 @@
+// Package deeplink memetakan URL yang diberikan ke app dari luar, lewat
+// argumen command line (App.exe https://app.example.com/x/123) atau custom
+// scheme (myapp://route/123, web+ourtool:...), ke URL yang dibuka di dalam app.
+package deeplink
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/user/w2app/internal/config"
+	"github.com/user/w2app/internal/navpolicy"
+	"github.com/user/w2app/internal/protocol"
+)
+
+// Placeholders adalah bagian URL masuk yang bisa dipakai di target. Target
+// protocols juga menerima %s seperti registerProtocolHandler, sama dengan {url}.
+// Untuk URL tanpa "//" (mailto:bob@example.com, myapp:route/1), {host} kosong
+// dan {path} berisi semua yang ada setelah "scheme:", tanpa query dan fragment.
+var Placeholders = []string{"{url}", "{scheme}", "{host}", "{path}", "{query}", "{fragment}"}
+
+var placeholderRe = regexp.MustCompile(`\{[^{}]*\}`)
+
+type rule struct {
+	pattern *navpolicy.Pattern
+	target  string
+}
+
+// Mapper adalah aturan deep_links yang sudah di-compile dari AppConfig
+type Mapper struct {
+	rules []rule
+}
+
+// New membuat Mapper dari deep_links lalu protocols di AppConfig
+func New(cfg *config.AppConfig) (*Mapper, error) {
+	m := &Mapper{}
+	for i, l := range cfg.DeepLinks {
+		pattern, err := navpolicy.ParsePattern(l.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("deep_links[%d]: %w", i, err)
+		}
+		if err := checkTarget(l.Target); err != nil {
+			return nil, fmt.Errorf("deep_links[%d]: %w", i, err)
+		}
+		m.rules = append(m.rules, rule{pattern: pattern, target: strings.TrimSpace(l.Target)})
+	}
+
+	// Scheme yang diklaim cocok dengan semua URL-nya, termasuk yang tanpa
+	// "//" seperti mailto:user@example.com
+	for i, p := range cfg.Protocols {
+		scheme := protocol.Normalize(p.Scheme)
+		pattern, err := navpolicy.ParsePattern(scheme + "://*")
+		if err != nil {
+			return nil, fmt.Errorf("protocols[%d]: %w", i, err)
+		}
+		target := strings.ReplaceAll(strings.TrimSpace(p.Target), "%s", "{url}")
+		if target == "" {
+			return nil, fmt.Errorf("protocols[%d]: target wajib diisi", i)
+		}
+		if err := checkTarget(target); err != nil {
+			return nil, fmt.Errorf("protocols[%d]: %w", i, err)
+		}
+		m.rules = append(m.rules, rule{pattern: pattern, target: target})
+	}
+	return m, nil
+}
+
+// checkTarget mengecek template target. Target kosong berarti URL masuk
+// dibuka apa adanya; selain itu target harus URL http/https, boleh diawali
+// placeholder.
+func checkTarget(target string) error {
+	target = strings.TrimSpace(target)
+	if target == "" {
+		return nil
+	}
+	for _, p := range placeholderRe.FindAllString(target, -1) {
+		if !slices.Contains(Placeholders, p) {
+			return fmt.Errorf("target %q: placeholder %s tidak dikenal (%s)", target, p, strings.Join(Placeholders, ", "))
+		}
+	}
+	if strings.HasPrefix(target, "{") {
+		return nil
+	}
+	u, err := url.Parse(placeholderRe.ReplaceAllString(target, ""))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("target %q harus URL http atau https", target)
+	}
+	return nil
+}
+
+// Map mengembalikan URL di dalam app untuk raw. Aturan pertama yang polanya
+// cocok menang. URL http/https yang tidak cocok dengan aturan mana pun
+// dikembalikan apa adanya; kebijakan navigasi yang memutuskan apakah URL itu
+// dibuka di app. ok bernilai false jika raw bukan URL yang bisa dibuka.
+func (m *Mapper) Map(raw string) (target string, ok bool) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	// Scheme satu huruf adalah drive Windows (C:\...), bukan URL
+	if err != nil || len(u.Scheme) < 2 {
+		return "", false
+	}
+
+	for _, r := range m.rules {
+		if !r.pattern.Match(u) {
+			continue
+		}
+		if r.target == "" {
+			return u.String(), isWeb(u)
+		}
+		target, err := url.Parse(expand(r.target, u))
+		if err != nil || !isWeb(target) {
+			return "", false
+		}
+		return target.String(), true
+	}
+
+	if isWeb(u) {
+		return u.String(), true
+	}
+	return "", false
+}
+
+// Args mengembalikan URL di dalam app untuk argumen pertama yang bisa
+// dipetakan. Flag (diawali - atau /) dilewati.
+func (m *Mapper) Args(args []string) (string, bool) {
+	for _, arg := range args {
+		if strings.HasPrefix(arg, "-") || strings.HasPrefix(arg, "/") {
+			continue
+		}
+		if target, ok := m.Map(arg); ok {
+			return target, true
+		}
+	}
+	return "", false
+}
+
+// expand mengisi placeholder target dengan bagian URL u
+func expand(target string, u *url.URL) string {
+	query := ""
+	if u.RawQuery != "" {
+		query = "?" + u.RawQuery
+	}
+	fragment := ""
+	if u.Fragment != "" {
+		fragment = "#" + u.EscapedFragment()
+	}
+	path := u.EscapedPath()
+	if u.Opaque != "" {
+		path = u.Opaque
+	}
+	return strings.NewReplacer(
+		"{url}", url.QueryEscape(u.String()),
+		"{scheme}", u.Scheme,
+		"{host}", u.Host,
+		"{path}", path,
+		"{query}", query,
+		"{fragment}", fragment,
+	).Replace(target)
+}
+
+func isWeb(u *url.URL) bool {
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
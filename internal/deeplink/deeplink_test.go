@@ -0,0 +1,107 @@
+package deeplink
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/user/w2app/internal/config"
+)
+
+func testMapper(t *testing.T) *Mapper {
+	t.Helper()
+	m, err := New(&config.AppConfig{
+		DeepLinks: []config.DeepLink{
+			{Pattern: "myapp://*", Target: "https://app.example.com/{host}{path}{query}{fragment}"},
+			{Pattern: "myapp://special/*", Target: "https://app.example.com/tidak-pernah"},
+			{Pattern: "old.example.com/*", Target: "https://app.example.com{path}"},
+			{Pattern: "share://*", Target: "https://app.example.com/share?u={url}&s={scheme}"},
+			{Pattern: "redirect://*", Target: "{path}"},
+			{Pattern: "passthrough.example.com/*"},
+		},
+		Protocols: []config.Protocol{
+			{Scheme: "mailto", Target: "https://mail.example.com/compose?to={path}"},
+			{Scheme: "web+ourtool:", Target: "https://app.example.com/open?uri=%s"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	return m
+}
+
+func TestMap(t *testing.T) {
+	m := testMapper(t)
+	tests := []struct {
+		raw  string
+		want string
+		ok   bool
+	}{
+		// URL hierarkis
+		{"myapp://route/123?x=1&y=a%20b#top", "https://app.example.com/route/123?x=1&y=a%20b#top", true},
+		{"MYAPP://Route/a%20b", "https://app.example.com/Route/a%20b", true},
+		{"  myapp://route  ", "https://app.example.com/route", true},
+		// Aturan pertama yang cocok menang
+		{"myapp://special/1", "https://app.example.com/special/1", true},
+		{"https://old.example.com/x/1?q=2", "https://app.example.com/x/1", true},
+		{"https://old.example.com/a%2Fb", "https://app.example.com/a%2Fb", true},
+
+		// URL opaque, tanpa "//"
+		{"myapp:route/123", "https://app.example.com/route/123", true},
+		{"myapp:route/123?x=1#top", "https://app.example.com/route/123?x=1#top", true},
+		{"mailto:bob@example.com", "https://mail.example.com/compose?to=bob@example.com", true},
+		{"mailto:bob@example.com?subject=Halo", "https://mail.example.com/compose?to=bob@example.com", true},
+		{"MailTo:bob@example.com", "https://mail.example.com/compose?to=bob@example.com", true},
+
+		// {url} dan %s di-escape utuh
+		{"share://x?a=b&c=d", "https://app.example.com/share?u=share%3A%2F%2Fx%3Fa%3Db%26c%3Dd&s=share", true},
+		{"web+ourtool:item/42?v=1", "https://app.example.com/open?uri=web%2Bourtool%3Aitem%2F42%3Fv%3D1", true},
+
+		// Target yang hasilnya bukan URL web ditolak
+		{"redirect://x/y", "", false},
+		{"redirect:https://app.example.com/a", "https://app.example.com/a", true},
+
+		// Tanpa aturan: URL web dibuka apa adanya
+		{"https://passthrough.example.com/a?b=c", "https://passthrough.example.com/a?b=c", true},
+		{"https://other.com/x", "https://other.com/x", true},
+		{"ftp://other.com/x", "", false},
+		{"unknown:thing", "", false},
+		{`C:\Users\a\file.txt`, "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, ok := m.Map(tt.raw)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("Map(%q) = %q, %v, want %q, %v", tt.raw, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestArgs(t *testing.T) {
+	m := testMapper(t)
+	got, ok := m.Args([]string{"--profile", "-x", "/min", `C:\a.txt`, "myapp:inbox", "https://other.com/"})
+	if !ok || got != "https://app.example.com/inbox" {
+		t.Errorf("Args = %q, %v", got, ok)
+	}
+	if got, ok := m.Args([]string{"--flag", "bukan-url"}); ok {
+		t.Errorf("Args tanpa URL = %q, %v", got, ok)
+	}
+}
+
+func TestNewError(t *testing.T) {
+	tests := []struct {
+		cfg  config.AppConfig
+		want string
+	}{
+		{config.AppConfig{DeepLinks: []config.DeepLink{{Pattern: ""}}}, "deep_links[0]"},
+		{config.AppConfig{DeepLinks: []config.DeepLink{{Pattern: "myapp://*", Target: "https://a.com/{id}"}}}, "placeholder {id}"},
+		{config.AppConfig{DeepLinks: []config.DeepLink{{Pattern: "myapp://*", Target: "myapp://other/{path}"}}}, "http atau https"},
+		{config.AppConfig{DeepLinks: []config.DeepLink{{Pattern: "myapp://*", Target: "https:///{path}"}}}, "http atau https"},
+		{config.AppConfig{Protocols: []config.Protocol{{Scheme: "mailto"}}}, "protocols[0]: target wajib diisi"},
+		{config.AppConfig{Protocols: []config.Protocol{{Scheme: "mailto", Target: "https://a.com/?to={to}"}}}, "protocols[0]"},
+	}
+	for _, tt := range tests {
+		if _, err := New(&tt.cfg); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("New(%+v) = %v, want %q", tt.cfg, err, tt.want)
+		}
+	}
+}
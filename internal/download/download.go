@@ -0,0 +1,186 @@
+// Package download menentukan di mana file yang diunduh dari app disimpan:
+// folder tujuan, nama file yang aman untuk Windows, nama pengganti jika file
+// sudah ada, dan tipe file yang boleh diunduh. Logikanya tidak bergantung
+// pada Windows, jadi bisa diuji di OS mana pun.
+package download
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/user/w2app/internal/appdata"
+)
+
+// Mode unduhan
+const (
+	ModeAuto = "auto" // Langsung simpan ke folder unduhan (default)
+	ModeAsk  = "ask"  // Selalu tanya lokasi simpan
+)
+
+// Modes adalah semua nilai downloads.mode yang didukung
+var Modes = []string{ModeAuto, ModeAsk}
+
+// DefaultName dipakai jika nama file dari server kosong atau tidak bisa dipakai
+const DefaultName = "download"
+
+// maxNameLength membatasi panjang nama file dalam byte, di bawah batas 255
+// NTFS supaya masih ada ruang untuk " (1)" dan ekstensi sementara WebView2
+const maxNameLength = 200
+
+// reservedNames adalah nama perangkat Windows yang tidak bisa dipakai sebagai
+// nama file, dengan ekstensi apa pun
+var reservedNames = []string{
+	"CON", "PRN", "AUX", "NUL",
+	"COM1", "COM2", "COM3", "COM4", "COM5", "COM6", "COM7", "COM8", "COM9",
+	"LPT1", "LPT2", "LPT3", "LPT4", "LPT5", "LPT6", "LPT7", "LPT8", "LPT9",
+}
+
+// compoundExts adalah ekstensi ganda yang tidak dipisah saat menambahkan
+// nomor ke nama file, jadi "data.tar.gz" menjadi "data (1).tar.gz"
+var compoundExts = []string{".tar.gz", ".tar.bz2", ".tar.xz", ".tar.zst"}
+
+var typeRe = regexp.MustCompile(`^(\.[a-z0-9_-]+)+$`)
+
+// CheckMode mengecek downloads.mode. Kosong berarti ModeAuto.
+func CheckMode(mode string) error {
+	if mode != "" && !slices.Contains(Modes, mode) {
+		return fmt.Errorf("downloads.mode %q tidak dikenal (pilih: %s)", mode, strings.Join(Modes, ", "))
+	}
+	return nil
+}
+
+// NormalizeType mengubah tipe file dari config ke bentuk baku: huruf kecil,
+// diawali titik ("PDF" menjadi ".pdf")
+func NormalizeType(t string) string {
+	t = strings.ToLower(strings.TrimSpace(t))
+	if t != "" && !strings.HasPrefix(t, ".") {
+		t = "." + t
+	}
+	return t
+}
+
+// CheckTypes mengecek downloads.allowed_types
+func CheckTypes(types []string) error {
+	for _, t := range types {
+		if !typeRe.MatchString(NormalizeType(t)) {
+			return fmt.Errorf("downloads.allowed_types: %q bukan ekstensi file seperti .pdf atau .tar.gz", t)
+		}
+	}
+	return nil
+}
+
+// Allowed mengecek apakah file bernama name boleh diunduh. Daftar types
+// kosong berarti semua tipe boleh.
+func Allowed(name string, types []string) bool {
+	if len(types) == 0 {
+		return true
+	}
+	name = strings.ToLower(name)
+	for _, t := range types {
+		if t = NormalizeType(t); t != "" && strings.HasSuffix(name, t) {
+			return true
+		}
+	}
+	return false
+}
+
+// Dir menentukan folder unduhan. folder dari config boleh berisi %VAR% dan
+// relatif terhadap folder exe; kosong berarti defaultDir, folder Downloads
+// milik user.
+func Dir(folder, defaultDir string, env appdata.Env) (string, error) {
+	if folder == "" {
+		if defaultDir == "" {
+			return "", fmt.Errorf("folder Downloads user tidak ditemukan")
+		}
+		return defaultDir, nil
+	}
+	dir, err := appdata.ResolvePath(folder, env)
+	if err != nil {
+		return "", fmt.Errorf("downloads.folder: %w", err)
+	}
+	return dir, nil
+}
+
+// FileName mengubah nama file yang disarankan server menjadi nama yang aman
+// untuk Windows: tanpa path, tanpa karakter terlarang, bukan nama perangkat,
+// dan tidak terlalu panjang
+func FileName(name string) string {
+	// Nama dari Content-Disposition atau URL bisa berisi path
+	name = name[strings.LastIndexAny(name, `/\`)+1:]
+
+	var b strings.Builder
+	for _, r := range name {
+		if r < 0x20 || r == 0x7f || strings.ContainsRune(`<>:"|?*`, r) {
+			r = '_'
+		}
+		b.WriteRune(r)
+	}
+	// Windows membuang titik dan spasi di akhir nama
+	name = strings.TrimRight(strings.TrimSpace(b.String()), ". ")
+	if name == "" {
+		return DefaultName
+	}
+
+	stem := strings.TrimSpace(strings.SplitN(name, ".", 2)[0])
+	if slices.Contains(reservedNames, strings.ToUpper(stem)) {
+		name = "_" + name
+	}
+
+	if len(name) > maxNameLength {
+		base, ext := splitExt(name)
+		if len(ext) > maxNameLength/4 {
+			base, ext = name, ""
+		}
+		name = truncate(base, maxNameLength-len(ext)) + ext
+	}
+	return name
+}
+
+// Unique mengembalikan path yang belum dipakai: path itu sendiri, atau
+// "nama (1).ext", "nama (2).ext", dan seterusnya seperti browser. exists
+// melaporkan apakah path sudah dipakai, termasuk oleh unduhan yang belum
+// selesai.
+func Unique(path string, exists func(string) bool) string {
+	if !exists(path) {
+		return path
+	}
+	dir, name := filepath.Split(path)
+	base, ext := splitExt(name)
+	for i := 1; ; i++ {
+		candidate := filepath.Join(dir, fmt.Sprintf("%s (%d)%s", base, i, ext))
+		if !exists(candidate) {
+			return candidate
+		}
+	}
+}
+
+// splitExt memisahkan nama file menjadi nama dan ekstensi. File yang namanya
+// diawali titik (".env") dianggap tidak punya ekstensi.
+func splitExt(name string) (base, ext string) {
+	lower := strings.ToLower(name)
+	for _, e := range compoundExts {
+		if strings.HasSuffix(lower, e) && len(name) > len(e) {
+			return name[:len(name)-len(e)], name[len(name)-len(e):]
+		}
+	}
+	ext = filepath.Ext(name)
+	if ext == name {
+		return name, ""
+	}
+	return strings.TrimSuffix(name, ext), ext
+}
+
+// truncate memotong s menjadi maksimal n byte tanpa memotong karakter UTF-8
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return strings.TrimRight(s[:n], ". ")
+}
@@ -0,0 +1,210 @@
+package download
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/user/w2app/internal/appdata"
+)
+
+func TestFileName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"report.pdf", "report.pdf"},
+		{"Laporan Bulanan (final).xlsx", "Laporan Bulanan (final).xlsx"},
+		{"", DefaultName},
+		{"   ", DefaultName},
+
+		// Path dibuang
+		{"../../etc/passwd", "passwd"},
+		{`C:\Users\x\evil.exe`, "evil.exe"},
+		{`..\..\a/b\c.txt`, "c.txt"},
+		{"folder/", DefaultName},
+
+		// Karakter terlarang dan karakter kontrol
+		{`a<b>c:d"e|f?g*h.txt`, "a_b_c_d_e_f_g_h.txt"},
+		{"a\x00b\tc\x7f.txt", "a_b_c_.txt"},
+
+		// Titik dan spasi di akhir
+		{"report.pdf. . ", "report.pdf"},
+		{"  notes.txt  ", "notes.txt"},
+		{"...", DefaultName},
+
+		// Nama perangkat Windows
+		{"CON.txt", "_CON.txt"},
+		{"con", "_con"},
+		{"nul.tar.gz", "_nul.tar.gz"},
+		{"Com1.log", "_Com1.log"},
+		{"LPT9", "_LPT9"},
+		{"CON .txt", "_CON .txt"},
+		{"CONSOLE.txt", "CONSOLE.txt"},
+		{"COM10.txt", "COM10.txt"},
+		{"my-con.txt", "my-con.txt"},
+
+		// Dotfile
+		{".env", ".env"},
+		{".gitignore.", ".gitignore"},
+	}
+	for _, tt := range tests {
+		if got := FileName(tt.name); got != tt.want {
+			t.Errorf("FileName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFileNameTruncate(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantExt string
+	}{
+		{strings.Repeat("a", 300) + ".pdf", ".pdf"},
+		// Batas 196 byte jatuh di tengah karakter 2 byte
+		{"a" + strings.Repeat("é", 150) + ".pdf", ".pdf"},
+		{strings.Repeat("日本", 100) + ".tar.gz", ".tar.gz"},
+		// Ekstensi yang terlalu panjang ikut dipotong
+		{"a." + strings.Repeat("x", 300), ""},
+		// Potongan tidak berakhir dengan titik atau spasi
+		{strings.Repeat("a", 195) + ". . . . . . . .txt", ".txt"},
+	}
+	for _, tt := range tests {
+		got := FileName(tt.name)
+		if len(got) > maxNameLength {
+			t.Errorf("FileName(%.20q...) = %d byte, maksimal %d", tt.name, len(got), maxNameLength)
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("FileName(%.20q...) = %q bukan UTF-8 yang valid", tt.name, got)
+		}
+		if tt.wantExt != "" && !strings.HasSuffix(got, tt.wantExt) {
+			t.Errorf("FileName(%.20q...) = %q, want akhiran %s", tt.name, got, tt.wantExt)
+		}
+		base := strings.TrimSuffix(got, tt.wantExt)
+		if strings.HasSuffix(base, ".") || strings.HasSuffix(base, " ") {
+			t.Errorf("FileName(%.20q...) = %q diakhiri titik atau spasi sebelum ekstensi", tt.name, got)
+		}
+	}
+	if got := FileName("a" + strings.Repeat("é", 150) + ".pdf"); got != "a"+strings.Repeat("é", 97)+".pdf" {
+		t.Errorf("FileName UTF-8 = %q", got)
+	}
+}
+
+func TestUnique(t *testing.T) {
+	dir := filepath.FromSlash("/dl")
+	tests := []struct {
+		name     string
+		existing []string
+		want     string
+	}{
+		{"report.pdf", nil, "report.pdf"},
+		{"report.pdf", []string{"report.pdf"}, "report (1).pdf"},
+		{"report.pdf", []string{"report.pdf", "report (1).pdf", "report (2).pdf"}, "report (3).pdf"},
+		{"data.tar.gz", []string{"data.tar.gz"}, "data (1).tar.gz"},
+		{"DATA.TAR.GZ", []string{"DATA.TAR.GZ"}, "DATA (1).TAR.GZ"},
+		{"backup.2024.zip", []string{"backup.2024.zip"}, "backup.2024 (1).zip"},
+		{"README", []string{"README"}, "README (1)"},
+		{".env", []string{".env"}, ".env (1)"},
+		{".config.json", []string{".config.json"}, ".config (1).json"},
+		// Lubang di urutan nomor dipakai lebih dulu
+		{"a.txt", []string{"a.txt", "a (2).txt"}, "a (1).txt"},
+	}
+	for _, tt := range tests {
+		existing := map[string]bool{}
+		for _, e := range tt.existing {
+			existing[filepath.Join(dir, e)] = true
+		}
+		got := Unique(filepath.Join(dir, tt.name), func(p string) bool { return existing[p] })
+		if want := filepath.Join(dir, tt.want); got != want {
+			t.Errorf("Unique(%q, %v) = %q, want %q", tt.name, tt.existing, got, want)
+		}
+	}
+}
+
+func TestSplitExt(t *testing.T) {
+	tests := []struct {
+		name, base, ext string
+	}{
+		{"a.txt", "a", ".txt"},
+		{"a.b.c", "a.b", ".c"},
+		{"a.tar.gz", "a", ".tar.gz"},
+		{"a.tar.zst", "a", ".tar.zst"},
+		{"a.gz", "a", ".gz"},
+		{"noext", "noext", ""},
+		{".env", ".env", ""},
+		{".tar.gz", ".tar", ".gz"},
+	}
+	for _, tt := range tests {
+		base, ext := splitExt(tt.name)
+		if base != tt.base || ext != tt.ext {
+			t.Errorf("splitExt(%q) = %q, %q, want %q, %q", tt.name, base, ext, tt.base, tt.ext)
+		}
+	}
+}
+
+func TestAllowed(t *testing.T) {
+	types := []string{"pdf", ".DOCX", " tar.gz ", ""}
+	tests := []struct {
+		name string
+		want bool
+	}{
+		{"a.pdf", true},
+		{"A.PDF", true},
+		{"a.docx", true},
+		{"a.tar.gz", true},
+		{"a.gz", false},
+		{"a.pdf.exe", false},
+		{"pdf", false},
+		{"a.exe", false},
+	}
+	for _, tt := range tests {
+		if got := Allowed(tt.name, types); got != tt.want {
+			t.Errorf("Allowed(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if !Allowed("a.exe", nil) {
+		t.Error("Allowed tanpa daftar tipe = false")
+	}
+}
+
+func TestCheckTypes(t *testing.T) {
+	if err := CheckTypes([]string{"pdf", ".tar.gz", "7z", ".my_ext"}); err != nil {
+		t.Errorf("CheckTypes: %v", err)
+	}
+	for _, typ := range []string{"", ".", "*.pdf", "pdf files", "a/b", ".pdf."} {
+		if err := CheckTypes([]string{typ}); err == nil {
+			t.Errorf("CheckTypes(%q): error nil", typ)
+		}
+	}
+}
+
+func TestDir(t *testing.T) {
+	env := appdata.Env{
+		Exe:    filepath.FromSlash("/opt/apps/App.exe"),
+		Getenv: func(name string) string { return map[string]string{"USERPROFILE": "/home/user"}[name] },
+	}
+	tests := []struct {
+		folder, defaultDir, want string
+	}{
+		{"", "/home/user/Downloads", "/home/user/Downloads"},
+		{"%USERPROFILE%/Desktop", "/home/user/Downloads", "/home/user/Desktop"},
+		{"downloads", "", "/opt/apps/downloads"},
+	}
+	for _, tt := range tests {
+		got, err := Dir(tt.folder, filepath.FromSlash(tt.defaultDir), env)
+		if err != nil {
+			t.Errorf("Dir(%q): %v", tt.folder, err)
+			continue
+		}
+		if got != filepath.FromSlash(tt.want) {
+			t.Errorf("Dir(%q) = %q, want %q", tt.folder, got, tt.want)
+		}
+	}
+	if _, err := Dir("", "", env); err == nil {
+		t.Error("Dir tanpa folder Downloads: error nil")
+	}
+	if _, err := Dir("%MISSING%/x", "", env); err == nil {
+		t.Error("Dir dengan variabel tidak ada: error nil")
+	}
+}
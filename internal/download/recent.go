@@ -0,0 +1,71 @@
+package download
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MaxRecent adalah jumlah unduhan terakhir yang diingat
+const MaxRecent = 10
+
+// recentFile menyimpan unduhan terakhir di direktori data profil
+const recentFile = "downloads.json"
+
+// Entry adalah satu unduhan yang sudah selesai
+type Entry struct {
+	Path string    `json:"path"`
+	URL  string    `json:"url,omitempty"`
+	Time time.Time `json:"time"`
+}
+
+// Recent mengembalikan unduhan terakhir di direktori data dir, yang terbaru
+// lebih dulu
+func Recent(dir string) ([]Entry, error) {
+	data, err := os.ReadFile(filepath.Join(dir, recentFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var entries []Entry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// AddRecent mencatat unduhan e di depan daftar dan mengembalikan daftar
+// barunya. Path yang sama hanya dicatat sekali, dan hanya MaxRecent unduhan
+// yang disimpan. Daftar lama yang rusak diganti.
+func AddRecent(dir string, e Entry) ([]Entry, error) {
+	old, _ := Recent(dir)
+	entries := []Entry{e}
+	for _, o := range old {
+		if len(entries) == MaxRecent {
+			break
+		}
+		if !strings.EqualFold(o.Path, e.Path) {
+			entries = append(entries, o)
+		}
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	path := filepath.Join(dir, recentFile)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return nil, err
+	}
+	return entries, nil
+}
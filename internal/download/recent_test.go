@@ -0,0 +1,83 @@
+package download
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestAddRecent(t *testing.T) {
+	dir := t.TempDir()
+	if entries, err := Recent(dir); entries != nil || err != nil {
+		t.Fatalf("Recent tanpa file = %v, %v", entries, err)
+	}
+
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	add := func(path string) []Entry {
+		t.Helper()
+		now = now.Add(time.Minute)
+		entries, err := AddRecent(dir, Entry{Path: path, URL: "https://example.com/" + filepath.Base(path), Time: now})
+		if err != nil {
+			t.Fatalf("AddRecent(%s): %v", path, err)
+		}
+		return entries
+	}
+	paths := func(entries []Entry) []string {
+		var p []string
+		for _, e := range entries {
+			p = append(p, e.Path)
+		}
+		return p
+	}
+
+	add(`C:\dl\a.pdf`)
+	add(`C:\dl\b.pdf`)
+	got := add(`C:\DL\A.PDF`) // Path Windows tidak membedakan huruf besar/kecil
+	if want := []string{`C:\DL\A.PDF`, `C:\dl\b.pdf`}; fmt.Sprint(paths(got)) != fmt.Sprint(want) {
+		t.Errorf("setelah duplikat = %v, want %v", paths(got), want)
+	}
+
+	for i := 0; i < MaxRecent+5; i++ {
+		got = add(fmt.Sprintf(`C:\dl\file%d.txt`, i))
+	}
+	if len(got) != MaxRecent {
+		t.Fatalf("%d entry, want %d", len(got), MaxRecent)
+	}
+	if got[0].Path != fmt.Sprintf(`C:\dl\file%d.txt`, MaxRecent+4) || got[MaxRecent-1].Path != `C:\dl\file5.txt` {
+		t.Errorf("urutan = %v", paths(got))
+	}
+
+	// Daftar yang tersimpan sama dengan yang dikembalikan
+	saved, err := Recent(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(saved) != len(got) || !saved[0].Time.Equal(got[0].Time) || saved[0].URL != got[0].URL {
+		t.Errorf("Recent = %+v, want %+v", saved, got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, recentFile+".tmp")); !os.IsNotExist(err) {
+		t.Errorf("file temporary tertinggal: %v", err)
+	}
+}
+
+func TestAddRecentCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, recentFile), []byte("{rusak"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Recent(dir); err == nil {
+		t.Error("Recent dari file rusak: error nil")
+	}
+	entries, err := AddRecent(dir, Entry{Path: "/dl/a.pdf"})
+	if err != nil {
+		t.Fatalf("AddRecent: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("AddRecent = %+v, want 1 entry", entries)
+	}
+	if saved, err := Recent(dir); err != nil || len(saved) != 1 {
+		t.Errorf("Recent = %+v, %v", saved, err)
+	}
+}
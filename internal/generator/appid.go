@@ -0,0 +1,66 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	"github.com/user/w2app/internal/config"
+)
+
+// NewAppID membuat AppID berformat reverse-DNS untuk app bernama name yang
+// membuka startURL, mis. "w2app.whatsapp.3f9a2c1e4b7d". Hasilnya selalu sama
+// untuk name dan startURL yang sama, jadi app yang di-build ulang tanpa app_id
+// tetap dikenali sebagai app yang sama, sedangkan dua app bernama sama untuk
+// situs berbeda mendapat ID berbeda.
+func NewAppID(name, startURL string) string {
+	var slug strings.Builder
+	dash := false
+	for _, r := range strings.ToLower(name) {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' {
+			slug.WriteRune(r)
+			dash = false
+		} else if !dash && slug.Len() > 0 {
+			slug.WriteByte('-')
+			dash = true
+		}
+	}
+	s := strings.TrimSuffix(slug.String(), "-")
+	if len(s) > 64 {
+		s = strings.TrimSuffix(s[:64], "-")
+	}
+	if s == "" {
+		s = "app"
+	}
+
+	sum := sha256.Sum256([]byte(strings.ToLower(name) + "\x00" + normalizeURL(startURL)))
+	return "w2app." + s + "." + hex.EncodeToString(sum[:6])
+}
+
+// normalizeURL menyamakan bagian URL yang tidak membedakan huruf besar/kecil
+// (scheme dan host), supaya perbedaan penulisan tidak mengubah AppID
+func normalizeURL(rawURL string) string {
+	rawURL = strings.TrimSpace(rawURL)
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	u.Scheme = strings.ToLower(u.Scheme)
+	u.Host = strings.ToLower(u.Host)
+	if u.Path == "" && u.Opaque == "" {
+		u.Path = "/"
+	}
+	return u.String()
+}
+
+// existingAppID mengembalikan AppID app w2app yang sudah ada di path, atau
+// string kosong jika file tidak ada, bukan app w2app, atau app lama tanpa
+// AppID
+func existingAppID(path string) string {
+	emb, err := config.ReadEmbedded(path)
+	if err != nil {
+		return ""
+	}
+	return emb.Config.AppID
+}
@@ -0,0 +1,47 @@
+package generator
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestNewAppID(t *testing.T) {
+	id := NewAppID("WhatsApp", "https://web.whatsapp.com/")
+	if !regexp.MustCompile(`^w2app\.whatsapp\.[0-9a-f]{12}$`).MatchString(id) {
+		t.Errorf("NewAppID = %q", id)
+	}
+
+	// Build ulang menghasilkan ID yang sama
+	for _, url := range []string{"https://web.whatsapp.com/", "HTTPS://Web.WhatsApp.com", " https://web.whatsapp.com/ "} {
+		if got := NewAppID("whatsapp", url); got != id {
+			t.Errorf("NewAppID(whatsapp, %q) = %q, want %q", url, got, id)
+		}
+	}
+
+	// App bernama sama untuk situs lain tidak boleh berbagi identitas
+	for _, url := range []string{"https://chat.example.com/", "https://web.whatsapp.com/business", "http://web.whatsapp.com/"} {
+		if got := NewAppID("WhatsApp", url); got == id {
+			t.Errorf("NewAppID(WhatsApp, %q) = %q, sama dengan %q", url, got, id)
+		}
+	}
+	if got := NewAppID("WhatsApp Work", "https://web.whatsapp.com/"); got == id {
+		t.Errorf("NewAppID dengan nama lain = %q, sama dengan %q", got, id)
+	}
+}
+
+func TestNewAppIDSlug(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"My Chat App!", `^w2app\.my-chat-app\.`},
+		{"  --Tools & Stuff--  ", `^w2app\.tools-stuff\.`},
+		{"日本語", `^w2app\.app\.`},
+		{"a very long application name that keeps going and going past the limit", `^w2app\.[a-z0-9-]{1,64}\.[0-9a-f]{12}$`},
+	}
+	for _, tt := range tests {
+		if got := NewAppID(tt.name, "https://example.com/"); !regexp.MustCompile(tt.want).MatchString(got) {
+			t.Errorf("NewAppID(%q) = %q, want %s", tt.name, got, tt.want)
+		}
+	}
+}
@@ -2,7 +2,10 @@ package generator
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
 	"embed"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"image"
@@ -10,30 +13,50 @@ import (
 	_ "image/jpeg"
 	_ "image/png"
 	"io"
+	"log/slog"
 	"net/http"
 	"net/url"
 	"os"
 	"path/filepath"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/tc-hib/winres"
 	"github.com/tc-hib/winres/version"
+	"github.com/user/w2app/internal/authenticode"
 	"github.com/user/w2app/internal/config"
+	"github.com/user/w2app/internal/deeplink"
+	"github.com/user/w2app/internal/navpolicy"
+	"github.com/user/w2app/internal/netfilter"
+	"github.com/user/w2app/internal/permission"
+	"github.com/user/w2app/internal/userscript"
 )
 
 //go:embed stubs/*
 var stubsFS embed.FS
 
+// StubVersion adalah versi stub yang di-embed di stubsFS jika stubs/ tidak
+// punya manifest, dan versi default untuk WriteStubManifest. Versi stub yang
+// dipakai disimpan di version info app (StubVersionKey) supaya 'w2app upgrade'
+// tahu stub mana yang dipakai.
+var StubVersion = "1.3.0"
+
+// StubVersionKey adalah nama string version info yang berisi StubVersion
+const StubVersionKey = "W2AppStubVersion"
+
 // Options adalah opsi untuk generate aplikasi
 type Options struct {
 	// Basic
 	URL      string
 	Name     string
+	AppID    string // Identitas tetap app, lihat config.AppConfig.AppID (kosong = AppID app lama di Output, atau NewAppID)
 	Output   string
 	Platform string
-	Icon     string // Path ke icon file (.ico, .png, .jpg) atau URL
+	Arch     string   // amd64, arm64, atau 386 (kosong = amd64)
+	StubDirs []string // Direktori stub yang dicari sebelum W2APP_STUB_PATH dan stub embedded
+	Icon     string   // Path ke icon file (.ico, .png, .jpg) atau URL
 
 	// Window
 	Width          int
@@ -45,12 +68,35 @@ type Options struct {
 	StartMaximized bool
 	TitleBarColor  string // Hex color e.g. "#1a1a2e" or "dark"/"light"
 
+	// Placement, lihat config.AppConfig
+	Monitor           string
+	X, Y              *int
+	Position          string
+	FullscreenMonitor string
+
 	// Behavior
 	SingleInstance     bool
 	UserAgent          string
 	ClearCacheOnExit   bool
 	EnableNotification bool // Enable push notifications
 
+	// Data
+	DataDir     string   // Lihat config.AppConfig.DataDir
+	Portable    bool     // Data disimpan di sebelah exe
+	ClearOnExit []string // cache, cookies, storage
+
+	// Profiles
+	EnableProfiles bool
+	Profiles       []string
+
+	// Downloads
+	DownloadFolder string   // Folder tujuan unduhan, default folder Downloads user
+	DownloadMode   string   // auto atau ask
+	DownloadTypes  []string // Ekstensi yang boleh diunduh, kosong berarti semua
+
+	// Permissions
+	Permissions map[string]string // jenis atau jenis@pola -> allow, deny, prompt
+
 	// System Tray
 	EnableTray      bool // Enable system tray icon
 	MinimizeToTray  bool // Minimize to tray instead of taskbar
@@ -63,15 +109,48 @@ type Options struct {
 	InjectJS      string
 	InjectCSSFile string
 	InjectJSFile  string
+	UserScriptDir string // Direktori berisi file .user.js
 
 	// Navigation
 	Whitelist        []string
 	BlockExternalNav bool
+	NavRules         []config.NavRule
+	PopupPolicy      string   // same, window, browser, atau block
+	AuthDomains      []string // Domain login yang selalu dibuka di window kedua
+	DeepLinks        []config.DeepLink
+	Protocols        []config.Protocol
+
+	// Network
+	Headers         []config.HeaderRule // Header tambahan per pola URL
+	BlockURLs       []string            // Pola URL yang diblokir
+	FilterListFiles []string            // File filter list Adblock yang di-embed
 
 	// Advanced
 	DisableContextMenu bool
 	DisableDevTools    bool
 	AutoIcon           bool // Auto-fetch favicon dari URL target
+
+	// Code signing (Authenticode, khusus Windows)
+	SignCert     string // Path ke file PFX berisi sertifikat code signing
+	SignPassword string // Password file PFX
+	TimestampURL string // Server timestamp RFC 3161 (opsional)
+
+	// Build banyak app sekaligus
+	IconCache *IconCache   // Cache download icon bersama (opsional)
+	Logger    *slog.Logger // Log progress dan warning (nil = tidak ada log)
+}
+
+// Result adalah ringkasan app yang berhasil di-generate
+type Result struct {
+	Path        string
+	Size        int64
+	SHA256      string            // Hex digest file hasil
+	Config      *config.AppConfig // Config yang di-embed
+	Stub        *Stub             // Stub yang dipakai
+	IconSource  string            // File atau URL icon yang di-embed (kosong jika tanpa icon)
+	Signer      string            // Common name sertifikat (kosong jika tidak di-sign)
+	Timestamped bool
+	Warnings    []string // Masalah yang tidak menggagalkan build (mis. icon gagal di-download)
 }
 
 // HTTP client dengan timeout
@@ -79,17 +158,29 @@ var httpClient = &http.Client{
 	Timeout: 30 * time.Second,
 }
 
-// Generate membuat aplikasi webview dari URL
-func Generate(opts Options) error {
+// Generate membuat aplikasi webview dari URL. File output ditulis lewat file
+// temporary dan baru di-rename setelah semua langkah berhasil, jadi build
+// yang gagal atau dibatalkan lewat ctx tidak meninggalkan exe setengah jadi.
+func Generate(ctx context.Context, opts Options) (*Result, error) {
+	log := opts.Logger
+	if log == nil {
+		log = slog.New(slog.DiscardHandler)
+	}
+	result := &Result{}
+	warn := func(msg string, err error) {
+		log.Warn(msg, "err", err)
+		result.Warnings = append(result.Warnings, fmt.Sprintf("%s: %v", msg, err))
+	}
+
 	// Validasi URL
 	if opts.URL == "" {
-		return fmt.Errorf("URL tidak boleh kosong")
+		return nil, fmt.Errorf("URL tidak boleh kosong")
 	}
 
 	// Validasi dan normalisasi URL
 	parsedURL, err := url.Parse(opts.URL)
 	if err != nil {
-		return fmt.Errorf("URL tidak valid: %w", err)
+		return nil, fmt.Errorf("URL tidak valid: %w", err)
 	}
 
 	// Tambahkan https:// jika tidak ada scheme
@@ -100,13 +191,13 @@ func Generate(opts Options) error {
 
 	// Validasi name
 	if opts.Name == "" {
-		return fmt.Errorf("nama aplikasi tidak boleh kosong")
+		return nil, fmt.Errorf("nama aplikasi tidak boleh kosong")
 	}
 
 	// Sanitize name untuk filename
 	safeName := sanitizeFilename(opts.Name)
 	if safeName == "" {
-		return fmt.Errorf("nama aplikasi tidak valid")
+		return nil, fmt.Errorf("nama aplikasi tidak valid")
 	}
 
 	// Set defaults
@@ -122,31 +213,73 @@ func Generate(opts Options) error {
 	if opts.Platform == "" {
 		opts.Platform = "windows"
 	}
+	if opts.Arch == "" {
+		opts.Arch = DefaultArch
+	}
+	if !slices.Contains(Archs, opts.Arch) {
+		return nil, fmt.Errorf("arch '%s' tidak didukung (pilih: %s)", opts.Arch, strings.Join(Archs, ", "))
+	}
+
+	// Tentukan nama file output
+	outFileName := safeName
+	if opts.Platform == "windows" {
+		outFileName += ".exe"
+	}
+	outPath := filepath.Join(opts.Output, outFileName)
+
+	// App yang di-build ulang tanpa app_id tetap memakai identitas lama,
+	// supaya direktori data, auto-start, dan AUMID-nya tidak berubah
+	if opts.AppID == "" {
+		opts.AppID = existingAppID(outPath)
+	}
+	if opts.AppID == "" {
+		opts.AppID = NewAppID(opts.Name, opts.URL)
+	}
+
+	// Load sertifikat di awal supaya password yang salah tidak baru ketahuan
+	// setelah icon di-download dan exe ditulis
+	var signCert *authenticode.Certificate
+	if opts.SignCert != "" {
+		if opts.Platform != "windows" {
+			return nil, fmt.Errorf("signing Authenticode hanya untuk platform windows")
+		}
+		signCert, err = authenticode.LoadPFX(opts.SignCert, opts.SignPassword)
+		if err != nil {
+			return nil, fmt.Errorf("gagal load sertifikat: %w", err)
+		}
+	}
+
+	// iconPath adalah file icon lokal, iconSource asal icon untuk laporan
+	iconPath, iconSource := opts.Icon, opts.Icon
 
 	// Auto-fetch favicon jika --auto-icon dan tidak ada icon yang di-set
 	if opts.AutoIcon && opts.Icon == "" {
-		fmt.Print("  Fetching favicon...")
-		iconPath, err := fetchFavicon(parsedURL)
+		log.Info("Fetching favicon", "site", parsedURL.Host)
+		path, source, release, err := opts.IconCache.favicon(ctx, parsedURL)
 		if err != nil {
-			fmt.Printf(" gagal: %v\n", err)
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			warn("favicon gagal di-fetch", err)
 		} else {
-			opts.Icon = iconPath
-			fmt.Printf(" OK\n")
-			defer os.Remove(iconPath) // Cleanup temp file
+			iconPath, iconSource = path, source
+			defer release() // Cleanup temp file
 		}
 	}
 
 	// Download icon jika berupa URL
-	if opts.Icon != "" && isURL(opts.Icon) {
-		fmt.Print("  Downloading icon...")
-		iconPath, err := downloadIcon(opts.Icon)
+	if isURL(iconPath) {
+		log.Info("Downloading icon", "url", iconPath)
+		path, _, release, err := opts.IconCache.download(ctx, iconPath)
 		if err != nil {
-			fmt.Printf(" gagal: %v\n", err)
-			opts.Icon = "" // Reset icon jika gagal download
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			warn("icon gagal di-download", err)
+			iconPath, iconSource = "", "" // Reset icon jika gagal download
 		} else {
-			opts.Icon = iconPath
-			fmt.Printf(" OK\n")
-			defer os.Remove(iconPath) // Cleanup temp file
+			iconPath = path
+			defer release() // Cleanup temp file
 		}
 	}
 
@@ -155,7 +288,7 @@ func Generate(opts Options) error {
 	if opts.InjectCSSFile != "" {
 		cssData, err := os.ReadFile(opts.InjectCSSFile)
 		if err != nil {
-			return fmt.Errorf("gagal membaca CSS file: %w", err)
+			return nil, fmt.Errorf("gagal membaca CSS file: %w", err)
 		}
 		injectCSS = string(cssData)
 	}
@@ -165,27 +298,46 @@ func Generate(opts Options) error {
 	if opts.InjectJSFile != "" {
 		jsData, err := os.ReadFile(opts.InjectJSFile)
 		if err != nil {
-			return fmt.Errorf("gagal membaca JS file: %w", err)
+			return nil, fmt.Errorf("gagal membaca JS file: %w", err)
 		}
 		injectJS = string(jsData)
 	}
 
-	// Tentukan stub file
-	stubFile := fmt.Sprintf("stubs/stub-%s-amd64.exe", opts.Platform)
-	if opts.Platform != "windows" {
-		stubFile = fmt.Sprintf("stubs/stub-%s-amd64", opts.Platform)
+	// Load userscripts dari direktori
+	var userScripts []config.UserScript
+	if opts.UserScriptDir != "" {
+		userScripts, err = userscript.LoadDir(opts.UserScriptDir)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca userscripts: %w", err)
+		}
 	}
 
-	// Baca stub dari embedded FS
-	stubData, err := stubsFS.ReadFile(stubFile)
+	// Load filter list dari file, digabung jadi satu
+	var filterLists []string
+	for _, path := range opts.FilterListFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca filter list: %w", err)
+		}
+		filterLists = append(filterLists, string(data))
+	}
+
+	// Cari stub di --stub-dir, W2APP_STUB_PATH, lalu embedded
+	stub, stubData, err := FindStub(opts.StubDirs, opts.Platform, opts.Arch)
 	if err != nil {
-		return fmt.Errorf("stub untuk platform '%s' tidak tersedia: %w", opts.Platform, err)
+		return nil, err
+	}
+	if stub.Source != EmbeddedStubSource {
+		log.Info("Memakai stub", "source", stub.Source, "version", stub.Version)
 	}
+	result.Stub = stub
 
 	// Buat config
 	cfg := config.AppConfig{
+		Schema:             config.SchemaVersion,
 		URL:                opts.URL,
 		Title:              opts.Name,
+		AppID:              opts.AppID,
 		Width:              opts.Width,
 		Height:             opts.Height,
 		Resizable:          opts.Resizable,
@@ -194,9 +346,20 @@ func Generate(opts Options) error {
 		AlwaysOnTop:        opts.AlwaysOnTop,
 		StartMaximized:     opts.StartMaximized,
 		TitleBarColor:      opts.TitleBarColor,
+		Monitor:            opts.Monitor,
+		X:                  opts.X,
+		Y:                  opts.Y,
+		Position:           opts.Position,
+		FullscreenMonitor:  opts.FullscreenMonitor,
 		SingleInstance:     opts.SingleInstance,
 		UserAgent:          opts.UserAgent,
 		ClearCacheOnExit:   opts.ClearCacheOnExit,
+		DataDir:            opts.DataDir,
+		Portable:           opts.Portable,
+		ClearOnExit:        opts.ClearOnExit,
+		EnableProfiles:     opts.EnableProfiles,
+		Profiles:           opts.Profiles,
+		Permissions:        opts.Permissions,
 		EnableNotification: opts.EnableNotification,
 		EnableTray:         opts.EnableTray,
 		MinimizeToTray:     opts.MinimizeToTray,
@@ -205,107 +368,135 @@ func Generate(opts Options) error {
 		EnableAutoStart:    opts.EnableAutoStart,
 		InjectCSS:          injectCSS,
 		InjectJS:           injectJS,
+		UserScripts:        userScripts,
 		Whitelist:          opts.Whitelist,
 		BlockExternalNav:   opts.BlockExternalNav,
+		NavRules:           opts.NavRules,
+		PopupPolicy:        opts.PopupPolicy,
+		AuthDomains:        opts.AuthDomains,
+		DeepLinks:          opts.DeepLinks,
+		Protocols:          opts.Protocols,
 		DisableContextMenu: opts.DisableContextMenu,
 		DisableDevTools:    opts.DisableDevTools,
 	}
+	if opts.DownloadFolder != "" || opts.DownloadMode != "" || len(opts.DownloadTypes) > 0 {
+		cfg.Downloads = &config.Downloads{
+			Folder:       opts.DownloadFolder,
+			Mode:         opts.DownloadMode,
+			AllowedTypes: opts.DownloadTypes,
+		}
+	}
 
-	// Serialize config ke JSON
-	configJSON, err := json.Marshal(cfg)
-	if err != nil {
-		return fmt.Errorf("gagal serialize config: %w", err)
+	if len(opts.Headers) > 0 || len(opts.BlockURLs) > 0 || len(filterLists) > 0 {
+		cfg.Network = &config.Network{
+			Headers:    opts.Headers,
+			Block:      opts.BlockURLs,
+			FilterList: strings.Join(filterLists, "\n"),
+		}
+	}
+
+	// Validasi config supaya kesalahan ketahuan saat generate, bukan saat app dijalankan
+	if err := validateConfig(&cfg); err != nil {
+		return nil, err
 	}
 
 	// Buat output directory jika belum ada
 	if err := os.MkdirAll(opts.Output, 0755); err != nil {
-		return fmt.Errorf("gagal membuat output directory: %w", err)
+		return nil, fmt.Errorf("gagal membuat output directory: %w", err)
 	}
 
-	// Tentukan nama file output
-	outFileName := safeName
-	if opts.Platform == "windows" {
-		outFileName += ".exe"
+	// Windows: config disimpan sebagai resource RCDATA bersama icon dan version
+	// info, sehingga tidak ada data di belakang PE image dan app bisa di-sign
+	// dengan Authenticode. Platform lain memakai trailer di akhir file.
+	var icon *winres.Icon
+	if opts.Platform == "windows" && iconPath != "" {
+		icon, err = loadIcon(iconPath)
+		if err != nil {
+			warn("gagal embed icon", err)
+		} else {
+			result.IconSource = iconSource
+		}
 	}
-	outPath := filepath.Join(opts.Output, outFileName)
 
-	// Buat file output dengan stub
-	outFile, err := os.Create(outPath)
-	if err != nil {
-		return fmt.Errorf("gagal membuat file output: %w", err)
+	if err := ctx.Err(); err != nil {
+		return nil, err
 	}
+	err = buildFile(outPath, 0755, func(tmpPath string) error {
+		// Tulis stub binary
+		if err := os.WriteFile(tmpPath, stubData, 0755); err != nil {
+			return fmt.Errorf("gagal menulis stub: %w", err)
+		}
 
-	// Tulis stub binary
-	if _, err := outFile.Write(stubData); err != nil {
-		outFile.Close()
-		return fmt.Errorf("gagal menulis stub: %w", err)
-	}
-	outFile.Close()
+		if opts.Platform != "windows" {
+			// Tulis trailer: marker + config JSON + footer (panjang, versi, checksum)
+			f, err := os.OpenFile(tmpPath, os.O_APPEND|os.O_WRONLY, 0)
+			if err != nil {
+				return fmt.Errorf("gagal membuka file untuk append config: %w", err)
+			}
+			err = config.WriteTrailer(f, &cfg)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return fmt.Errorf("gagal menulis config: %w", err)
+			}
+			return nil
+		}
 
-	// PENTING: Embed icon SEBELUM append marker+JSON
-	// winres.WriteObject memodifikasi PE headers/resources, jadi harus dilakukan
-	// sebelum kita append data tambahan di akhir file
-	iconEmbedded := false
-	iconSource := ""
-	if opts.Icon != "" && opts.Platform == "windows" {
-		if err := embedIcon(outPath, opts.Icon, opts.Name); err != nil {
-			fmt.Printf("  Warning: Gagal embed icon: %v\n", err)
-		} else {
-			iconEmbedded = true
-			iconSource = opts.Icon
+		configJSON, err := json.Marshal(cfg)
+		if err != nil {
+			return fmt.Errorf("gagal serialize config: %w", err)
+		}
+		if err := embedResources(tmpPath, outFileName, icon, opts.Name, stub.Version, configJSON); err != nil {
+			return fmt.Errorf("gagal embed resource: %w", err)
 		}
-	}
 
-	// Buka file untuk append marker dan config
-	outFile, err = os.OpenFile(outPath, os.O_APPEND|os.O_WRONLY, 0755)
+		// Signing harus jadi langkah terakhir: setiap perubahan file setelah
+		// ini akan membuat signature tidak valid
+		if signCert != nil {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			log.Info("Signing", "signer", signCert.Leaf.Subject.CommonName)
+			signOpts := authenticode.SignOptions{TimestampURL: opts.TimestampURL}
+			if err := authenticode.SignFileContext(ctx, tmpPath, signCert, signOpts); err != nil {
+				return fmt.Errorf("gagal sign executable: %w", err)
+			}
+			result.Signer = signCert.Leaf.Subject.CommonName
+			result.Timestamped = opts.TimestampURL != ""
+		}
+		return nil
+	})
 	if err != nil {
-		return fmt.Errorf("gagal membuka file untuk append config: %w", err)
+		return nil, err
 	}
 
-	// Tulis marker
-	if _, err := outFile.WriteString(config.ConfigMarker); err != nil {
-		outFile.Close()
-		return fmt.Errorf("gagal menulis marker: %w", err)
+	result.Path = outPath
+	result.Size, result.SHA256, err = hashFile(outPath)
+	if err != nil {
+		return nil, err
 	}
 
-	// Tulis config JSON
-	if _, err := outFile.Write(configJSON); err != nil {
-		outFile.Close()
-		return fmt.Errorf("gagal menulis config: %w", err)
-	}
+	result.Config = &cfg
+	log.Info("App dibuat", "path", outPath, "size", result.Size)
 
-	// Get file size before closing
-	fileSize := getFileSize(outFile)
-	outFile.Close()
+	return result, nil
+}
 
-	// Print summary
-	fmt.Printf("\n✓ Berhasil membuat aplikasi: %s\n", outPath)
-	fmt.Printf("  URL       : %s\n", opts.URL)
-	fmt.Printf("  Ukuran    : %s\n", fileSize)
-	fmt.Printf("  Window    : %dx%d", opts.Width, opts.Height)
-	if opts.Resizable {
-		fmt.Print(" (resizable)")
-	}
-	if opts.Fullscreen {
-		fmt.Print(" (fullscreen)")
+// hashFile mengembalikan ukuran dan SHA-256 (hex) file di path
+func hashFile(path string) (int64, string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return 0, "", err
 	}
-	fmt.Println()
+	defer f.Close()
 
-	if opts.SingleInstance {
-		fmt.Println("  Mode      : Single instance")
-	}
-	if iconEmbedded {
-		fmt.Printf("  Icon      : %s (embedded)\n", iconSource)
-	}
-	if injectCSS != "" {
-		fmt.Printf("  CSS       : %d bytes injected\n", len(injectCSS))
-	}
-	if injectJS != "" {
-		fmt.Printf("  JS        : %d bytes injected\n", len(injectJS))
+	h := sha256.New()
+	n, err := io.Copy(h, f)
+	if err != nil {
+		return 0, "", fmt.Errorf("gagal menghitung SHA-256: %w", err)
 	}
-	fmt.Println()
-
-	return nil
+	return n, hex.EncodeToString(h.Sum(nil)), nil
 }
 
 // isURL mengecek apakah string adalah URL
@@ -314,8 +505,12 @@ func isURL(s string) bool {
 }
 
 // downloadIcon mendownload icon dari URL ke file temporary
-func downloadIcon(iconURL string) (string, error) {
-	resp, err := httpClient.Get(iconURL)
+func downloadIcon(ctx context.Context, iconURL string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, iconURL, nil)
+	if err != nil {
+		return "", fmt.Errorf("URL icon tidak valid: %w", err)
+	}
+	resp, err := httpClient.Do(req)
 	if err != nil {
 		return "", fmt.Errorf("gagal download: %w", err)
 	}
@@ -345,8 +540,9 @@ func downloadIcon(iconURL string) (string, error) {
 	return tmpFile.Name(), nil
 }
 
-// fetchFavicon mencoba mendapatkan favicon dari website
-func fetchFavicon(siteURL *url.URL) (string, error) {
+// fetchFavicon mencoba mendapatkan favicon dari website. Mengembalikan path
+// file temporary dan URL favicon yang berhasil.
+func fetchFavicon(ctx context.Context, siteURL *url.URL) (string, string, error) {
 	baseURL := fmt.Sprintf("%s://%s", siteURL.Scheme, siteURL.Host)
 
 	// Coba berbagai lokasi favicon umum
@@ -360,17 +556,20 @@ func fetchFavicon(siteURL *url.URL) (string, error) {
 	}
 
 	for _, faviconURL := range faviconURLs {
-		iconPath, err := downloadIcon(faviconURL)
+		if err := ctx.Err(); err != nil {
+			return "", "", err
+		}
+		iconPath, err := downloadIcon(ctx, faviconURL)
 		if err == nil {
 			// Verifikasi bahwa file adalah image yang valid
 			if isValidImage(iconPath) {
-				return iconPath, nil
+				return iconPath, faviconURL, nil
 			}
 			os.Remove(iconPath)
 		}
 	}
 
-	return "", fmt.Errorf("tidak dapat menemukan favicon")
+	return "", "", fmt.Errorf("tidak dapat menemukan favicon")
 }
 
 // isValidImage mengecek apakah file adalah image yang valid
@@ -448,46 +647,65 @@ func getIconExtension(contentType, iconURL string) string {
 	return ".ico"
 }
 
-// embedIcon menambahkan icon ke executable Windows menggunakan WriteToEXE
-func embedIcon(exePath, iconPath, appName string) error {
+// loadIcon membaca file icon (.ico, .png, .jpg, dll) menjadi winres.Icon
+func loadIcon(iconPath string) (*winres.Icon, error) {
 	// Baca icon file
 	iconData, err := os.ReadFile(iconPath)
 	if err != nil {
-		return fmt.Errorf("gagal membaca icon file: %w", err)
+		return nil, fmt.Errorf("gagal membaca icon file: %w", err)
 	}
 
-	// Buat winres resource set
-	rs := winres.ResourceSet{}
-
 	// Cek format berdasarkan magic bytes
-	ext := detectImageFormat(iconData)
-
-	// Use ID 1 for the main application icon (this is the standard convention)
-	iconResID := winres.ID(1)
-
-	if ext == ".ico" {
+	if detectImageFormat(iconData) == ".ico" {
 		// Load .ico file langsung
 		ico, err := winres.LoadICO(bytes.NewReader(iconData))
 		if err != nil {
-			return fmt.Errorf("gagal load ICO: %w", err)
-		}
-		rs.SetIcon(iconResID, ico)
-	} else {
-		// Load sebagai image (png, jpg, dll)
-		img, _, err := image.Decode(bytes.NewReader(iconData))
-		if err != nil {
-			return fmt.Errorf("gagal decode image: %w", err)
+			return nil, fmt.Errorf("gagal load ICO: %w", err)
 		}
+		return ico, nil
+	}
 
-		// Konversi image ke icon dengan multiple sizes untuk kualitas lebih baik
-		ico, err := winres.NewIconFromResizedImage(img, nil)
-		if err != nil {
-			return fmt.Errorf("gagal konversi ke icon: %w", err)
+	// Load sebagai image (png, jpg, dll)
+	img, _, err := image.Decode(bytes.NewReader(iconData))
+	if err != nil {
+		return nil, fmt.Errorf("gagal decode image: %w", err)
+	}
+
+	// Konversi image ke icon dengan multiple sizes untuk kualitas lebih baik
+	ico, err := winres.NewIconFromResizedImage(img, nil)
+	if err != nil {
+		return nil, fmt.Errorf("gagal konversi ke icon: %w", err)
+	}
+	return ico, nil
+}
+
+// embedResources menulis config (RCDATA), version info, dan icon (jika ada)
+// ke executable Windows menggunakan WriteToEXE. fileName adalah nama akhir
+// exe untuk version info, karena exePath bisa berupa file temporary.
+func embedResources(exePath, fileName string, icon *winres.Icon, appName, stubVersion string, configJSON []byte) error {
+	// Buat winres resource set
+	rs := winres.ResourceSet{}
+
+	// Use ID 1 for the main application icon (this is the standard convention)
+	if icon != nil {
+		if err := rs.SetIcon(winres.ID(1), icon); err != nil {
+			return fmt.Errorf("gagal set icon: %w", err)
 		}
-		rs.SetIcon(iconResID, ico)
+	}
+
+	// Config app
+	if err := rs.Set(winres.RT_RCDATA, winres.Name(config.ResourceName), winres.LCIDNeutral, configJSON); err != nil {
+		return fmt.Errorf("gagal set config resource: %w", err)
 	}
 
 	// Set version info
+	rs.SetVersionInfo(newVersionInfo(appName, fileName, stubVersion))
+
+	return writeResources(exePath, &rs)
+}
+
+// newVersionInfo membuat version info default untuk app baru
+func newVersionInfo(appName, fileName, stubVersion string) version.Info {
 	vi := version.Info{
 		ProductVersion: [4]uint16{1, 0, 0, 0},
 		FileVersion:    [4]uint16{1, 0, 0, 0},
@@ -496,43 +714,106 @@ func embedIcon(exePath, iconPath, appName string) error {
 	vi.Set(0x0409, "FileDescription", appName+" - Web App")
 	vi.Set(0x0409, "CompanyName", "W2App")
 	vi.Set(0x0409, "LegalCopyright", "Generated by W2App")
-	vi.Set(0x0409, "OriginalFilename", filepath.Base(exePath))
-	rs.SetVersionInfo(vi)
+	vi.Set(0x0409, "OriginalFilename", fileName)
+	vi.Set(0x0409, StubVersionKey, stubVersion)
+	return vi
+}
 
-	// Buka source exe untuk dibaca
-	srcFile, err := os.Open(exePath)
-	if err != nil {
-		return fmt.Errorf("gagal membuka exe source: %w", err)
+// writeResources mengganti section .rsrc exe dengan rs. File baru ditulis ke
+// file temporary lalu di-rename, jadi exe asli tetap utuh jika ada error.
+// Signature Authenticode (jika ada) dihapus karena pasti tidak valid lagi.
+func writeResources(exePath string, rs *winres.ResourceSet) error {
+	return replaceFile(exePath, func(dst *os.File) error {
+		src, err := os.Open(exePath)
+		if err != nil {
+			return fmt.Errorf("gagal membuka exe source: %w", err)
+		}
+		defer src.Close()
+
+		if err := rs.WriteToEXE(dst, src, winres.WithAuthenticode(winres.RemoveSignature)); err != nil {
+			return fmt.Errorf("gagal write resources ke exe: %w", err)
+		}
+		return nil
+	})
+}
+
+// replaceFile mengganti isi path secara atomic: write menulis ke file
+// temporary di direktori yang sama, lalu file itu di-rename menimpa path.
+// Permission file asli dipertahankan.
+func replaceFile(path string, write func(*os.File) error) error {
+	mode := os.FileMode(0755)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
 	}
 
-	// Buat temp file untuk output
-	tmpFile, err := os.CreateTemp(filepath.Dir(exePath), "w2app-tmp-*.exe")
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), "w2app-tmp-*")
 	if err != nil {
-		srcFile.Close()
 		return fmt.Errorf("gagal buat temp file: %w", err)
 	}
 	tmpPath := tmpFile.Name()
 
-	// WriteToEXE: tulis exe baru dengan resources yang ditambahkan
-	err = rs.WriteToEXE(tmpFile, srcFile)
-	srcFile.Close()
-	tmpFile.Close()
-
+	err = write(tmpFile)
+	if cerr := tmpFile.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("gagal menulis temp file: %w", cerr)
+	}
+	if err == nil {
+		err = os.Chmod(tmpPath, mode)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
 	if err != nil {
 		os.Remove(tmpPath)
-		return fmt.Errorf("gagal write resources ke exe: %w", err)
+		return err
 	}
+	return nil
+}
 
-	// Ganti file asli dengan file yang sudah dimodifikasi
-	if err := os.Remove(exePath); err != nil {
-		os.Remove(tmpPath)
-		return fmt.Errorf("gagal hapus exe asli: %w", err)
+// buildFile membuat file di path lewat file temporary di direktori yang sama.
+// build menerima path file temporary (sudah ada, kosong) untuk diisi; jika
+// build gagal, file temporary dihapus dan path tidak disentuh sama sekali.
+func buildFile(path string, mode os.FileMode, build func(tmpPath string) error) error {
+	tmpFile, err := os.CreateTemp(filepath.Dir(path), "w2app-build-*")
+	if err != nil {
+		return fmt.Errorf("gagal buat temp file: %w", err)
 	}
+	tmpPath := tmpFile.Name()
+	tmpFile.Close()
 
-	if err := os.Rename(tmpPath, exePath); err != nil {
-		return fmt.Errorf("gagal rename temp ke exe: %w", err)
+	err = build(tmpPath)
+	if err == nil {
+		err = os.Chmod(tmpPath, mode)
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return err
 	}
+	return nil
+}
 
+// validateConfig mengecek config sebelum ditulis ke executable
+func validateConfig(cfg *config.AppConfig) error {
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("config tidak valid: %w", err)
+	}
+	if _, err := navpolicy.New(cfg); err != nil {
+		return fmt.Errorf("aturan navigasi tidak valid: %w", err)
+	}
+	if _, err := deeplink.New(cfg); err != nil {
+		return fmt.Errorf("deep link tidak valid: %w", err)
+	}
+	if _, err := permission.New(cfg.Permissions); err != nil {
+		return fmt.Errorf("izin tidak valid: %w", err)
+	}
+	if _, err := netfilter.New(cfg.Network); err != nil {
+		return fmt.Errorf("aturan network tidak valid: %w", err)
+	}
+	if err := userscript.Check(cfg.UserScripts); err != nil {
+		return fmt.Errorf("userscript tidak valid: %w", err)
+	}
 	return nil
 }
 
@@ -562,24 +843,6 @@ func detectImageFormat(data []byte) string {
 	return ".png" // default
 }
 
-// ListPlatforms mengembalikan daftar platform yang tersedia
-func ListPlatforms() []string {
-	entries, err := stubsFS.ReadDir("stubs")
-	if err != nil {
-		return []string{}
-	}
-
-	var platforms []string
-	for _, entry := range entries {
-		name := entry.Name()
-		parts := strings.Split(name, "-")
-		if len(parts) >= 2 {
-			platforms = append(platforms, parts[1])
-		}
-	}
-	return platforms
-}
-
 // sanitizeFilename membersihkan nama file dari karakter yang tidak valid
 func sanitizeFilename(name string) string {
 	reg := regexp.MustCompile(`[<>:"/\\|?*\x00-\x1f]`)
@@ -590,17 +853,8 @@ func sanitizeFilename(name string) string {
 	return safe
 }
 
-// getFileSize mengembalikan ukuran file dalam format human-readable
-func getFileSize(f *os.File) string {
-	info, err := f.Stat()
-	if err != nil {
-		return "unknown"
-	}
-	return formatBytes(info.Size())
-}
-
-// formatBytes mengembalikan ukuran dalam format human-readable
-func formatBytes(size int64) string {
+// FormatBytes mengembalikan ukuran dalam format human-readable
+func FormatBytes(size int64) string {
 	const unit = 1024
 	if size < unit {
 		return fmt.Sprintf("%d B", size)
@@ -613,11 +867,6 @@ func formatBytes(size int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
 }
 
-// formatSize mengembalikan ukuran file dalam format human-readable (deprecated, use getFileSize)
-func formatSize(f *os.File) string {
-	return getFileSize(f)
-}
-
 // CopyFile menyalin file dari src ke dst
 func CopyFile(src, dst string) error {
 	srcFile, err := os.Open(src)
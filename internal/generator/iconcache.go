@@ -0,0 +1,113 @@
+package generator
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"sync"
+)
+
+// IconCache menyimpan icon yang sudah di-download (favicon maupun URL icon)
+// supaya build banyak app sekaligus tidak men-download icon yang sama
+// berulang kali. Aman dipakai dari beberapa goroutine. File cache dihapus
+// dengan Close setelah semua build selesai.
+type IconCache struct {
+	mu      sync.Mutex
+	entries map[string]*iconEntry
+}
+
+type iconEntry struct {
+	done   chan struct{} // Ditutup setelah download selesai
+	path   string
+	source string
+	err    error
+}
+
+// NewIconCache membuat cache icon kosong
+func NewIconCache() *IconCache {
+	return &IconCache{entries: map[string]*iconEntry{}}
+}
+
+// favicon mengembalikan favicon situs, dari cache jika sudah pernah di-fetch
+func (c *IconCache) favicon(ctx context.Context, siteURL *url.URL) (string, string, func(), error) {
+	return c.get(ctx, "favicon:"+siteURL.Scheme+"://"+siteURL.Host, func(ctx context.Context) (string, string, error) {
+		return fetchFavicon(ctx, siteURL)
+	})
+}
+
+// download mengembalikan icon dari URL, dari cache jika sudah pernah di-download
+func (c *IconCache) download(ctx context.Context, iconURL string) (string, string, func(), error) {
+	return c.get(ctx, "url:"+iconURL, func(ctx context.Context) (string, string, error) {
+		path, err := downloadIcon(ctx, iconURL)
+		return path, iconURL, err
+	})
+}
+
+// get menjalankan fetch sekali per key dan mengembalikan path file, asal
+// icon, dan fungsi release. Tanpa cache (c == nil) file hasil fetch milik
+// pemanggil dan dihapus lewat release.
+func (c *IconCache) get(ctx context.Context, key string, fetch func(context.Context) (string, string, error)) (string, string, func(), error) {
+	if c == nil {
+		path, source, err := fetch(ctx)
+		if err != nil {
+			return "", "", func() {}, err
+		}
+		return path, source, func() { os.Remove(path) }, nil
+	}
+
+	for {
+		c.mu.Lock()
+		e, ok := c.entries[key]
+		if !ok {
+			e = &iconEntry{done: make(chan struct{})}
+			c.entries[key] = e
+		}
+		c.mu.Unlock()
+
+		if !ok {
+			e.path, e.source, e.err = fetch(ctx)
+			// Pembatalan milik satu build jangan sampai tersimpan untuk build lain
+			if isContextErr(e.err) {
+				c.mu.Lock()
+				delete(c.entries, key)
+				c.mu.Unlock()
+			}
+			close(e.done)
+		}
+
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			return "", "", func() {}, ctx.Err()
+		}
+
+		// Build yang men-download dibatalkan, tapi build ini belum: coba lagi
+		if isContextErr(e.err) && ctx.Err() == nil {
+			continue
+		}
+		return e.path, e.source, func() {}, e.err
+	}
+}
+
+func isContextErr(err error) bool {
+	return errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// Close menghapus semua file icon di cache
+func (c *IconCache) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key, e := range c.entries {
+		select {
+		case <-e.done:
+			if e.err == nil {
+				os.Remove(e.path)
+			}
+			delete(c.entries, key)
+		default:
+			// Masih di-download, biarkan
+		}
+	}
+	return nil
+}
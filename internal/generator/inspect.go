@@ -0,0 +1,145 @@
+package generator
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/tc-hib/winres"
+	"github.com/tc-hib/winres/version"
+	"github.com/user/w2app/internal/authenticode"
+	"github.com/user/w2app/internal/config"
+)
+
+// AppInfo adalah hasil inspeksi app hasil generate
+type AppInfo struct {
+	Path           string            `json:"path"`
+	FileSize       int64             `json:"file_size"`
+	StubSize       int64             `json:"stub_size"`
+	Storage        string            `json:"storage"`
+	TrailerVersion int               `json:"trailer_version,omitempty"`
+	Config         *config.AppConfig `json:"config"`
+	Icons          []IconInfo        `json:"icons"`
+	VersionInfo    map[string]string `json:"version_info"`
+	Signature      *SignatureInfo    `json:"signature,omitempty"`
+}
+
+// IconInfo adalah satu icon group di resource exe
+type IconInfo struct {
+	ID    string   `json:"id"`
+	Lang  uint16   `json:"lang"`
+	Sizes []string `json:"sizes"`
+}
+
+// SignatureInfo adalah ringkasan signature Authenticode
+type SignatureInfo struct {
+	Valid     bool       `json:"valid"`
+	Signer    string     `json:"signer,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Error     string     `json:"error,omitempty"`
+}
+
+// Inspect membaca config, icon, version info, dan signature dari app
+func Inspect(path string) (*AppInfo, error) {
+	emb, err := config.ReadEmbedded(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info := &AppInfo{
+		Path:        path,
+		FileSize:    emb.FileSize,
+		StubSize:    emb.StubSize,
+		Storage:     emb.Storage,
+		Config:      emb.Config,
+		Icons:       []IconInfo{},
+		VersionInfo: map[string]string{},
+	}
+	if emb.Trailer != nil {
+		info.TrailerVersion = emb.Trailer.Version
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// Stub non-Windows bukan PE, jadi tidak punya resource
+	rs, err := winres.LoadFromEXE(f)
+	if err != nil {
+		return info, nil
+	}
+
+	rs.WalkType(winres.RT_GROUP_ICON, func(resID winres.Identifier, langID uint16, data []byte) bool {
+		info.Icons = append(info.Icons, IconInfo{
+			ID:    identifierString(resID),
+			Lang:  langID,
+			Sizes: iconGroupSizes(data),
+		})
+		return true
+	})
+
+	rs.WalkType(winres.RT_VERSION, func(resID winres.Identifier, langID uint16, data []byte) bool {
+		vi, err := version.FromBytes(data)
+		if err != nil {
+			return true
+		}
+		for key, value := range vi.Table().GetMainTranslation() {
+			info.VersionInfo[key] = value
+		}
+		return false
+	})
+
+	sig, err := authenticode.VerifyFile(path)
+	switch {
+	case errors.Is(err, authenticode.ErrNotSigned):
+	case err != nil:
+		info.Signature = &SignatureInfo{Error: err.Error()}
+	default:
+		info.Signature = &SignatureInfo{Valid: true, Signer: sig.Signer.Subject.String()}
+		if !sig.Timestamp.IsZero() {
+			info.Signature.Timestamp = &sig.Timestamp
+		}
+	}
+
+	return info, nil
+}
+
+func identifierString(id winres.Identifier) string {
+	switch v := id.(type) {
+	case winres.ID:
+		return fmt.Sprintf("#%d", uint16(v))
+	case winres.Name:
+		return string(v)
+	}
+	return fmt.Sprint(id)
+}
+
+// iconGroupSizes membaca ukuran setiap gambar dari data GRPICONDIR
+func iconGroupSizes(data []byte) []string {
+	sizes := []string{}
+	if len(data) < 6 {
+		return sizes
+	}
+	count := int(binary.LittleEndian.Uint16(data[4:]))
+	for i := 0; i < count; i++ {
+		off := 6 + i*14
+		if off+14 > len(data) {
+			break
+		}
+		entry := data[off : off+14]
+		w, h := int(entry[0]), int(entry[1])
+		// Nilai 0 berarti 256 piksel
+		if w == 0 {
+			w = 256
+		}
+		if h == 0 {
+			h = 256
+		}
+		sizes = append(sizes, fmt.Sprintf("%dx%d", w, h))
+	}
+	return sizes
+}
@@ -0,0 +1,144 @@
+package generator
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/tc-hib/winres"
+	"github.com/user/w2app/internal/authenticode"
+	"github.com/user/w2app/internal/config"
+)
+
+// ReconfigureOptions adalah opsi untuk mengubah config app yang sudah ada
+type ReconfigureOptions struct {
+	Changes []config.Change
+
+	// Sign ulang setelah config diubah (opsional, khusus Windows)
+	SignCert     string
+	SignPassword string
+	TimestampURL string
+}
+
+// ReconfigureResult menjelaskan apa yang berubah
+type ReconfigureResult struct {
+	Old              *config.AppConfig
+	New              *config.AppConfig
+	Storage          string
+	SignatureRemoved bool // App sebelumnya di-sign dan tidak di-sign ulang
+	Signed           bool
+}
+
+// Reconfigure mengubah config app yang sudah di-generate tanpa build ulang.
+// Icon, version info, dan resource lain tetap dipertahankan. App dengan
+// config trailer tetap memakai trailer (trailer lama dibuang dan diganti
+// trailer V2).
+func Reconfigure(path string, opts ReconfigureOptions) (*ReconfigureResult, error) {
+	emb, err := config.ReadEmbedded(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := config.ApplyChanges(emb.Raw, opts.Changes)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	var signCert *authenticode.Certificate
+	if opts.SignCert != "" {
+		if emb.Storage != config.StorageResource {
+			return nil, fmt.Errorf("app dengan config trailer tidak bisa di-sign, buat ulang dengan 'w2app create'")
+		}
+		signCert, err = authenticode.LoadPFX(opts.SignCert, opts.SignPassword)
+		if err != nil {
+			return nil, fmt.Errorf("gagal load sertifikat: %w", err)
+		}
+	}
+
+	result := &ReconfigureResult{Old: emb.Config, New: cfg, Storage: emb.Storage}
+
+	switch emb.Storage {
+	case config.StorageResource:
+		payload, err := json.Marshal(cfg)
+		if err != nil {
+			return nil, fmt.Errorf("gagal serialize config: %w", err)
+		}
+
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		rs, err := winres.LoadFromEXE(f)
+		if err == nil {
+			result.SignatureRemoved, err = winres.IsSignedEXE(f)
+		}
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("gagal membaca resource: %w", err)
+		}
+
+		if err := rs.Set(winres.RT_RCDATA, winres.Name(config.ResourceName), winres.LCIDNeutral, payload); err != nil {
+			return nil, fmt.Errorf("gagal set config resource: %w", err)
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		mode := os.FileMode(0755)
+		if info, err := os.Stat(path); err == nil {
+			mode = info.Mode().Perm()
+		}
+
+		// Config dan signature ditulis ke file temporary yang sama, jadi app
+		// tidak berubah sama sekali jika signing gagal (mis. server timestamp
+		// tidak bisa dihubungi)
+		err = buildFile(path, mode, func(tmpPath string) error {
+			if err := os.WriteFile(tmpPath, data, mode); err != nil {
+				return fmt.Errorf("gagal menyalin exe: %w", err)
+			}
+			if err := writeResources(tmpPath, rs); err != nil {
+				return err
+			}
+			if signCert != nil {
+				signOpts := authenticode.SignOptions{TimestampURL: opts.TimestampURL}
+				if err := authenticode.SignFile(tmpPath, signCert, signOpts); err != nil {
+					return fmt.Errorf("gagal sign executable: %w", err)
+				}
+				result.Signed = true
+				result.SignatureRemoved = false
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+	default:
+		err := replaceFile(path, func(dst *os.File) error {
+			src, err := os.Open(path)
+			if err != nil {
+				return err
+			}
+			defer src.Close()
+
+			// Salin stub tanpa trailer lama, lalu tulis trailer baru
+			if _, err := io.Copy(dst, io.NewSectionReader(src, 0, emb.Trailer.Offset)); err != nil {
+				return fmt.Errorf("gagal menyalin stub: %w", err)
+			}
+			if err := config.WriteTrailer(dst, cfg); err != nil {
+				return fmt.Errorf("gagal menulis config: %w", err)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,98 @@
+package generator
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/w2app/internal/authenticode"
+	"github.com/user/w2app/internal/config"
+)
+
+const testPFX = "../authenticode/testdata/modern.pfx"
+
+func generateTest(t *testing.T) string {
+	t.Helper()
+	t.Setenv(StubPathEnv, "")
+	res, err := Generate(context.Background(), Options{
+		URL:    "https://chat.example.com",
+		Name:   "Chat",
+		Output: t.TempDir(),
+	})
+	if err != nil {
+		t.Fatalf("Generate: %v", err)
+	}
+	return res.Path
+}
+
+func TestReconfigureSign(t *testing.T) {
+	path := generateTest(t)
+	result, err := Reconfigure(path, ReconfigureOptions{
+		Changes:      []config.Change{{Key: "title", Value: "Chat Baru"}},
+		SignCert:     testPFX,
+		SignPassword: "test",
+	})
+	if err != nil {
+		t.Fatalf("Reconfigure: %v", err)
+	}
+	if !result.Signed || result.Old.Title != "Chat" || result.New.Title != "Chat Baru" {
+		t.Errorf("Reconfigure = %+v", result)
+	}
+
+	emb, err := config.ReadEmbedded(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if emb.Config.Title != "Chat Baru" {
+		t.Errorf("title = %q", emb.Config.Title)
+	}
+	if _, err := authenticode.VerifyFile(path); err != nil {
+		t.Errorf("VerifyFile: %v", err)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("file temporary tertinggal: %v", entries)
+	}
+}
+
+func TestReconfigureSignFailure(t *testing.T) {
+	path := generateTest(t)
+	before, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Server timestamp yang sedang error
+	tsa := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "down", http.StatusServiceUnavailable)
+	}))
+	defer tsa.Close()
+
+	_, err = Reconfigure(path, ReconfigureOptions{
+		Changes:      []config.Change{{Key: "title", Value: "Chat Baru"}},
+		SignCert:     testPFX,
+		SignPassword: "test",
+		TimestampURL: tsa.URL,
+	})
+	if err == nil || !strings.Contains(err.Error(), "sign") {
+		t.Fatalf("Reconfigure = %v, want error sign", err)
+	}
+
+	// App di disk tidak berubah sama sekali
+	after, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(before, after) {
+		t.Error("app berubah padahal signing gagal")
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("file temporary tertinggal: %v", entries)
+	}
+}
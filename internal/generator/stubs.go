@@ -0,0 +1,252 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/user/w2app/internal/config"
+)
+
+// StubManifestName adalah nama file manifest di direktori stub
+const StubManifestName = "manifest.json"
+
+// StubPathEnv berisi direktori stub tambahan, dipisah seperti PATH
+const StubPathEnv = "W2APP_STUB_PATH"
+
+// EmbeddedStubSource adalah Source untuk stub yang di-embed di w2app
+const EmbeddedStubSource = "embedded"
+
+// DefaultArch adalah arsitektur jika Options.Arch kosong
+const DefaultArch = "amd64"
+
+// Archs adalah arsitektur yang bisa dipilih dengan --arch
+var Archs = []string{"amd64", "arm64", "386"}
+
+// Stub adalah satu entry di manifest stub
+type Stub struct {
+	Platform string `json:"platform"`
+	Arch     string `json:"arch"`
+	File     string `json:"file"` // Relatif terhadap direktori manifest
+	Version  string `json:"version"`
+	Schema   int    `json:"schema"` // Versi config schema tertinggi yang dipahami stub
+	SHA256   string `json:"sha256"`
+
+	Source   string `json:"-"` // Direktori stub, atau EmbeddedStubSource
+	Verified bool   `json:"-"` // Hash file sudah dicek cocok dengan manifest
+	Err      error  `json:"-"` // Diisi ListStubs jika stub tidak bisa dipakai
+
+	fsys fs.FS
+}
+
+// StubManifest adalah isi manifest.json di direktori stub
+type StubManifest struct {
+	Stubs []*Stub `json:"stubs"`
+}
+
+type stubSource struct {
+	name string
+	fsys fs.FS
+}
+
+// stubSources menyusun urutan pencarian stub: dirs (--stub-dir), lalu
+// W2APP_STUB_PATH, lalu stub yang di-embed. Sumber pertama yang punya stub
+// untuk platform/arch yang diminta yang dipakai.
+func stubSources(dirs []string) []stubSource {
+	var sources []stubSource
+	for _, dir := range append(slices.Clone(dirs), filepath.SplitList(os.Getenv(StubPathEnv))...) {
+		if dir != "" {
+			sources = append(sources, stubSource{name: dir, fsys: os.DirFS(dir)})
+		}
+	}
+	embedded, _ := fs.Sub(stubsFS, "stubs")
+	return append(sources, stubSource{name: EmbeddedStubSource, fsys: embedded})
+}
+
+// loadStubs membaca manifest satu sumber stub. Stub yang di-embed tanpa
+// manifest (build lama) dikenali dari nama filenya.
+func loadStubs(src stubSource) ([]*Stub, error) {
+	data, err := fs.ReadFile(src.fsys, StubManifestName)
+	if errors.Is(err, fs.ErrNotExist) && src.name == EmbeddedStubSource {
+		return legacyStubs(src)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca manifest stub di %s: %w", src.name, err)
+	}
+
+	var m StubManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("manifest stub %s tidak valid: %w", filepath.Join(src.name, StubManifestName), err)
+	}
+	for i, s := range m.Stubs {
+		if s == nil || s.Platform == "" || s.Arch == "" || s.File == "" || s.SHA256 == "" {
+			return nil, fmt.Errorf("manifest stub %s: entry %d wajib punya platform, arch, file, dan sha256",
+				filepath.Join(src.name, StubManifestName), i+1)
+		}
+		s.Source = src.name
+		s.fsys = src.fsys
+	}
+	return m.Stubs, nil
+}
+
+func legacyStubs(src stubSource) ([]*Stub, error) {
+	entries, err := fs.ReadDir(src.fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+	var stubs []*Stub
+	for _, entry := range entries {
+		platform, arch, ok := parseStubName(entry.Name())
+		if !ok {
+			continue
+		}
+		stubs = append(stubs, &Stub{
+			Platform: platform,
+			Arch:     arch,
+			File:     entry.Name(),
+			Version:  StubVersion,
+			Schema:   config.SchemaVersion,
+			Source:   src.name,
+			fsys:     src.fsys,
+		})
+	}
+	return stubs, nil
+}
+
+// parseStubName memecah nama file stub-<platform>-<arch>[.exe]
+func parseStubName(name string) (platform, arch string, ok bool) {
+	base, found := strings.CutPrefix(strings.TrimSuffix(name, ".exe"), "stub-")
+	if !found {
+		return "", "", false
+	}
+	platform, arch, ok = strings.Cut(base, "-")
+	return platform, arch, ok && platform != "" && arch != ""
+}
+
+// load membaca file stub dan mencocokkan hash-nya dengan manifest
+func (s *Stub) load() ([]byte, error) {
+	data, err := fs.ReadFile(s.fsys, s.File)
+	if err != nil {
+		return nil, fmt.Errorf("gagal membaca stub %s/%s dari %s: %w", s.Platform, s.Arch, s.Source, err)
+	}
+	sum := sha256.Sum256(data)
+	digest := hex.EncodeToString(sum[:])
+
+	// Stub embedded tanpa manifest tidak punya hash pembanding
+	if s.SHA256 == "" {
+		s.SHA256 = digest
+		return data, nil
+	}
+	if !strings.EqualFold(s.SHA256, digest) {
+		return nil, fmt.Errorf("hash stub %s dari %s tidak cocok dengan manifest (manifest %s, file %s)",
+			s.File, s.Source, s.SHA256, digest)
+	}
+	s.Verified = true
+	return data, nil
+}
+
+// FindStub mencari stub untuk platform dan arch di dirs, W2APP_STUB_PATH, lalu
+// stub yang di-embed. Stub yang hash-nya tidak cocok dengan manifest, atau yang
+// tidak memahami config schema saat ini, ditolak.
+func FindStub(dirs []string, platform, arch string) (*Stub, []byte, error) {
+	for _, src := range stubSources(dirs) {
+		stubs, err := loadStubs(src)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, s := range stubs {
+			if s.Platform != platform || s.Arch != arch {
+				continue
+			}
+			data, err := s.load()
+			if err != nil {
+				return nil, nil, err
+			}
+			if s.Schema < config.SchemaVersion {
+				return nil, nil, fmt.Errorf("stub %s/%s dari %s hanya mendukung config schema %d (butuh %d)",
+					platform, arch, s.Source, s.Schema, config.SchemaVersion)
+			}
+			return s, data, nil
+		}
+	}
+	return nil, nil, fmt.Errorf("stub untuk platform '%s' arch '%s' tidak tersedia", platform, arch)
+}
+
+// ListStubs mengembalikan stub yang akan dipakai untuk setiap platform/arch,
+// dengan urutan pencarian yang sama dengan FindStub. Hash setiap stub dicek
+// dan masalahnya dicatat di Stub.Err.
+func ListStubs(dirs []string) ([]*Stub, error) {
+	var stubs []*Stub
+	seen := map[string]bool{}
+	for _, src := range stubSources(dirs) {
+		loaded, err := loadStubs(src)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range loaded {
+			key := s.Platform + "/" + s.Arch
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			if _, err := s.load(); err != nil {
+				s.Err = err
+			} else if s.Schema < config.SchemaVersion {
+				s.Err = fmt.Errorf("hanya mendukung config schema %d (butuh %d)", s.Schema, config.SchemaVersion)
+			}
+			stubs = append(stubs, s)
+		}
+	}
+	return stubs, nil
+}
+
+// WriteStubManifest membuat manifest.json untuk semua file stub-<platform>-<arch>
+// di dir. Version kosong berarti StubVersion.
+func WriteStubManifest(dir, version string) (*StubManifest, error) {
+	if version == "" {
+		version = StubVersion
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	m := &StubManifest{}
+	for _, entry := range entries {
+		platform, arch, ok := parseStubName(entry.Name())
+		if !ok || entry.IsDir() {
+			continue
+		}
+		_, digest, err := hashFile(filepath.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		m.Stubs = append(m.Stubs, &Stub{
+			Platform: platform,
+			Arch:     arch,
+			File:     entry.Name(),
+			Version:  version,
+			Schema:   config.SchemaVersion,
+			SHA256:   digest,
+		})
+	}
+	if len(m.Stubs) == 0 {
+		return nil, fmt.Errorf("tidak ada file stub-<platform>-<arch> di %s", dir)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := os.WriteFile(filepath.Join(dir, StubManifestName), append(data, '\n'), 0644); err != nil {
+		return nil, fmt.Errorf("gagal menulis manifest stub: %w", err)
+	}
+	return m, nil
+}
@@ -0,0 +1,74 @@
+package generator
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/user/w2app/internal/config"
+)
+
+// writeStubDir membuat direktori stub dengan manifest yang mengaku
+// mendukung config schema schema
+func writeStubDir(t *testing.T, schema int) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "stub-windows-arm64.exe"), []byte("MZ stub"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	m, err := WriteStubManifest(dir, "0.9.0")
+	if err != nil {
+		t.Fatalf("WriteStubManifest: %v", err)
+	}
+	m.Stubs[0].Schema = schema
+	data, _ := json.Marshal(m)
+	if err := os.WriteFile(filepath.Join(dir, StubManifestName), data, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
+func TestFindStub(t *testing.T) {
+	t.Setenv(StubPathEnv, "")
+	dir := writeStubDir(t, config.SchemaVersion)
+	s, data, err := FindStub([]string{dir}, "windows", "arm64")
+	if err != nil {
+		t.Fatalf("FindStub: %v", err)
+	}
+	if s.Source != dir || s.Version != "0.9.0" || string(data) != "MZ stub" {
+		t.Errorf("FindStub = %+v, %q", s, data)
+	}
+
+	if _, _, err := FindStub([]string{dir}, "linux", "arm64"); err == nil {
+		t.Error("FindStub platform tidak ada: error nil")
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "stub-windows-arm64.exe"), []byte("MZ diubah"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := FindStub([]string{dir}, "windows", "arm64"); err == nil || !strings.Contains(err.Error(), "hash") {
+		t.Errorf("FindStub dengan hash berbeda = %v", err)
+	}
+}
+
+func TestFindStubOldSchema(t *testing.T) {
+	t.Setenv(StubPathEnv, "")
+	// Stub skema lama mengabaikan field seperti network.block dan
+	// permissions, jadi tidak boleh dipakai
+	dir := writeStubDir(t, config.SchemaVersion-1)
+	if _, _, err := FindStub([]string{dir}, "windows", "arm64"); err == nil || !strings.Contains(err.Error(), "schema") {
+		t.Errorf("FindStub skema lama = %v", err)
+	}
+
+	stubs, err := ListStubs([]string{dir})
+	if err != nil {
+		t.Fatalf("ListStubs: %v", err)
+	}
+	for _, s := range stubs {
+		if s.Source == dir && (s.Err == nil || !strings.Contains(s.Err.Error(), "schema")) {
+			t.Errorf("ListStubs: Err = %v, want error schema", s.Err)
+		}
+	}
+}
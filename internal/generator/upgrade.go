@@ -0,0 +1,272 @@
+package generator
+
+import (
+	"bytes"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/tc-hib/winres"
+	"github.com/tc-hib/winres/version"
+	"github.com/user/w2app/internal/authenticode"
+	"github.com/user/w2app/internal/config"
+)
+
+// UpgradeOptions adalah opsi untuk memindahkan app ke stub terbaru
+type UpgradeOptions struct {
+	Output   string   // File atau direktori hasil upgrade (kosong = timpa app asli)
+	StubDirs []string // Direktori stub yang dicari sebelum W2APP_STUB_PATH dan stub embedded
+
+	// Sign ulang hasil upgrade (opsional, khusus Windows)
+	SignCert     string
+	SignPassword string
+	TimestampURL string
+}
+
+// UpgradeResult menjelaskan hasil upgrade
+type UpgradeResult struct {
+	Output           string
+	Platform         string
+	Arch             string
+	OldStubVersion   string // Kosong jika app dibuat sebelum versi stub dicatat
+	NewStubVersion   string
+	OldSchema        int
+	NewSchema        int
+	OldStorage       string
+	NewStorage       string
+	IconKept         bool
+	SignatureRemoved bool // App sebelumnya di-sign dan hasilnya tidak di-sign ulang
+	Signed           bool
+}
+
+// Upgrade membuat ulang app dari stub terbaru untuk platform dan arch app
+// tersebut (lihat FindStub). Config lama
+// dimigrasi ke skema terbaru, sedangkan icon dan version info app Windows
+// dibawa ke binary baru. App lama tidak disentuh sampai hasil baru selesai
+// ditulis.
+func Upgrade(path string, opts UpgradeOptions) (*UpgradeResult, error) {
+	emb, err := config.ReadEmbedded(path)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, oldSchema, err := config.Migrate(emb.Raw)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+
+	platform, arch, err := detectTarget(path)
+	if err != nil {
+		return nil, err
+	}
+	stub, stubData, err := FindStub(opts.StubDirs, platform, arch)
+	if err != nil {
+		return nil, err
+	}
+
+	var signCert *authenticode.Certificate
+	if opts.SignCert != "" {
+		if platform != "windows" {
+			return nil, fmt.Errorf("signing Authenticode hanya untuk platform windows")
+		}
+		signCert, err = authenticode.LoadPFX(opts.SignCert, opts.SignPassword)
+		if err != nil {
+			return nil, fmt.Errorf("gagal load sertifikat: %w", err)
+		}
+	}
+
+	outPath := opts.Output
+	if outPath == "" {
+		outPath = path
+	} else if info, err := os.Stat(outPath); err == nil && info.IsDir() {
+		outPath = filepath.Join(outPath, filepath.Base(path))
+	}
+
+	result := &UpgradeResult{
+		Output:         outPath,
+		Platform:       platform,
+		Arch:           arch,
+		NewStubVersion: stub.Version,
+		OldSchema:      oldSchema,
+		NewSchema:      config.SchemaVersion,
+		OldStorage:     emb.Storage,
+		NewStorage:     config.StorageTrailer,
+	}
+
+	var rs *winres.ResourceSet
+	if platform == "windows" {
+		rs, err = upgradeResources(path, cfg, result)
+		if err != nil {
+			return nil, err
+		}
+		result.NewStorage = config.StorageResource
+	}
+
+	mode := os.FileMode(0755)
+	if info, err := os.Stat(path); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	// Semua langkah dikerjakan di file temporary, baru di-rename ke output
+	err = buildFile(outPath, mode, func(tmpPath string) error {
+		if err := os.WriteFile(tmpPath, stubData, mode); err != nil {
+			return fmt.Errorf("gagal menulis stub: %w", err)
+		}
+
+		if rs == nil {
+			f, err := os.OpenFile(tmpPath, os.O_APPEND|os.O_WRONLY, 0)
+			if err != nil {
+				return err
+			}
+			err = config.WriteTrailer(f, cfg)
+			if cerr := f.Close(); err == nil {
+				err = cerr
+			}
+			if err != nil {
+				return fmt.Errorf("gagal menulis config: %w", err)
+			}
+			return nil
+		}
+
+		if err := writeResources(tmpPath, rs); err != nil {
+			return fmt.Errorf("gagal embed resource: %w", err)
+		}
+		if signCert != nil {
+			signOpts := authenticode.SignOptions{TimestampURL: opts.TimestampURL}
+			if err := authenticode.SignFile(tmpPath, signCert, signOpts); err != nil {
+				return fmt.Errorf("gagal sign executable: %w", err)
+			}
+			result.Signed = true
+			result.SignatureRemoved = false
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// upgradeResources menyusun resource untuk stub baru: icon dan version info
+// dari app lama, ditambah config yang sudah dimigrasi
+func upgradeResources(path string, cfg *config.AppConfig, result *UpgradeResult) (*winres.ResourceSet, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	// App tanpa section .rsrc (misalnya app trailer tanpa icon) tetap bisa di-upgrade
+	old, err := winres.LoadFromEXE(f)
+	if err != nil {
+		old = &winres.ResourceSet{}
+	}
+	if result.SignatureRemoved, err = winres.IsSignedEXE(f); err != nil {
+		return nil, fmt.Errorf("gagal membaca signature: %w", err)
+	}
+
+	rs := &winres.ResourceSet{}
+
+	var walkErr error
+	old.WalkType(winres.RT_GROUP_ICON, func(resID winres.Identifier, langID uint16, _ []byte) bool {
+		icon, err := old.GetIconTranslation(resID, langID)
+		if err == nil {
+			err = rs.SetIconTranslation(resID, langID, icon)
+		}
+		if err != nil {
+			walkErr = fmt.Errorf("gagal menyalin icon %s: %w", identifierString(resID), err)
+			return false
+		}
+		result.IconKept = true
+		return true
+	})
+	if walkErr != nil {
+		return nil, walkErr
+	}
+
+	var vi *version.Info
+	old.WalkType(winres.RT_VERSION, func(_ winres.Identifier, _ uint16, data []byte) bool {
+		vi, _ = version.FromBytes(data)
+		return vi == nil
+	})
+	if vi == nil {
+		info := newVersionInfo(cfg.Title, filepath.Base(path), result.NewStubVersion)
+		vi = &info
+	} else {
+		result.OldStubVersion = vi.Table().GetMainTranslation()[StubVersionKey]
+		for langID := range vi.Table() {
+			vi.Set(langID, StubVersionKey, result.NewStubVersion)
+		}
+	}
+	rs.SetVersionInfo(*vi)
+
+	configJSON, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("gagal serialize config: %w", err)
+	}
+	if err := rs.Set(winres.RT_RCDATA, winres.Name(config.ResourceName), winres.LCIDNeutral, configJSON); err != nil {
+		return nil, fmt.Errorf("gagal set config resource: %w", err)
+	}
+	return rs, nil
+}
+
+// detectTarget membaca platform dan arch app dari header executable
+func detectTarget(path string) (platform, arch string, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", "", err
+	}
+	defer f.Close()
+
+	header := make([]byte, 4)
+	if _, err := f.ReadAt(header, 0); err != nil {
+		return "", "", fmt.Errorf("gagal membaca %s: %w", path, err)
+	}
+
+	switch {
+	case bytes.HasPrefix(header, []byte("MZ")):
+		pf, err := pe.NewFile(f)
+		if err != nil {
+			return "", "", fmt.Errorf("gagal membaca header PE %s: %w", path, err)
+		}
+		arch = map[uint16]string{
+			pe.IMAGE_FILE_MACHINE_AMD64: "amd64",
+			pe.IMAGE_FILE_MACHINE_ARM64: "arm64",
+			pe.IMAGE_FILE_MACHINE_I386:  "386",
+		}[pf.Machine]
+		platform = "windows"
+	case bytes.Equal(header, []byte(elf.ELFMAG)):
+		ef, err := elf.NewFile(f)
+		if err != nil {
+			return "", "", fmt.Errorf("gagal membaca header ELF %s: %w", path, err)
+		}
+		arch = map[elf.Machine]string{
+			elf.EM_X86_64:  "amd64",
+			elf.EM_AARCH64: "arm64",
+			elf.EM_386:     "386",
+		}[ef.Machine]
+		platform = "linux"
+	default:
+		mf, err := macho.NewFile(f)
+		if err != nil {
+			return "", "", fmt.Errorf("format executable %s tidak dikenal", path)
+		}
+		arch = map[macho.Cpu]string{
+			macho.CpuAmd64: "amd64",
+			macho.CpuArm64: "arm64",
+			macho.Cpu386:   "386",
+		}[mf.Cpu]
+		platform = "darwin"
+	}
+	if arch == "" {
+		return "", "", fmt.Errorf("arsitektur %s tidak didukung", path)
+	}
+	return platform, arch, nil
+}
@@ -0,0 +1,251 @@
+// Package ipc meneruskan pesan dari instance kedua sebuah app ke instance yang
+// sedang berjalan. Di Windows kanalnya named pipe, di OS lain unix socket.
+// Pesan dikirim sebagai JSON per baris dan setiap pesan dibalas, jadi pengirim
+// tahu pesannya sudah diproses tanpa perlu file di temp atau polling.
+package ipc
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Tipe pesan
+const (
+	TypeShow              = "show"               // Tampilkan dan fokuskan window
+	TypeNotificationClick = "notification-click" // Notifikasi ID diklik
+	TypeOpenURL           = "open-url"           // Buka URL di window app
+	TypeArgv              = "argv"               // Argumen command line instance kedua
+)
+
+// DefaultTimeout adalah batas waktu Send untuk terhubung dan menunggu balasan
+const DefaultTimeout = 3 * time.Second
+
+// maxMessageSize membatasi ukuran satu pesan yang diterima server
+const maxMessageSize = 64 << 10
+
+var (
+	// ErrNotRunning dikembalikan Send jika tidak ada instance yang mendengarkan
+	ErrNotRunning = errors.New("tidak ada instance yang berjalan")
+
+	// ErrInUse dikembalikan Listen jika instance lain sudah mendengarkan
+	ErrInUse = errors.New("kanal IPC sudah dipakai instance lain")
+
+	errClosed = errors.New("listener sudah ditutup")
+)
+
+// Message adalah satu pesan dari instance kedua
+type Message struct {
+	Type string   `json:"type"`
+	ID   string   `json:"id,omitempty"`   // TypeNotificationClick
+	URL  string   `json:"url,omitempty"`  // TypeOpenURL
+	Args []string `json:"args,omitempty"` // TypeArgv
+}
+
+// Check mengecek apakah pesan lengkap untuk tipenya
+func (m Message) Check() error {
+	switch m.Type {
+	case TypeShow, TypeArgv:
+	case TypeNotificationClick:
+		if m.ID == "" {
+			return errors.New("pesan notification-click tanpa id")
+		}
+	case TypeOpenURL:
+		if m.URL == "" {
+			return errors.New("pesan open-url tanpa url")
+		}
+	default:
+		return fmt.Errorf("tipe pesan tidak dikenal: %q", m.Type)
+	}
+	return nil
+}
+
+// reply adalah balasan server untuk satu pesan
+type reply struct {
+	Error string `json:"error,omitempty"`
+}
+
+// Handler memproses satu pesan. Error-nya dikirim balik ke pengirim.
+// Handler dipanggil dari goroutine server, bukan dari UI thread.
+type Handler func(Message) error
+
+// listener adalah kanal yang menerima koneksi, per OS
+type listener interface {
+	Accept() (io.ReadWriteCloser, error)
+	Close() error
+}
+
+// Server mendengarkan pesan untuk satu key
+type Server struct {
+	ln      listener
+	handler Handler
+	closed  atomic.Bool
+	wg      sync.WaitGroup
+}
+
+// Address mengembalikan alamat kanal untuk key: nama named pipe di Windows,
+// path unix socket di OS lain. Key di-hash supaya karakter apa pun aman.
+func Address(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return address("w2app-" + hex.EncodeToString(sum[:16]))
+}
+
+// Listen mulai mendengarkan pesan untuk key. Hanya satu server per key yang
+// bisa berjalan; server kedua mendapat ErrInUse.
+func Listen(key string, handler Handler) (*Server, error) {
+	ln, err := listen(Address(key))
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{ln: ln, handler: handler}
+	s.wg.Add(1)
+	go s.serve()
+	return s, nil
+}
+
+// Close berhenti mendengarkan. Koneksi yang sedang diproses dibiarkan selesai.
+func (s *Server) Close() error {
+	if s.closed.Swap(true) {
+		return nil
+	}
+	err := s.ln.Close()
+	s.wg.Wait()
+	return err
+}
+
+func (s *Server) serve() {
+	defer s.wg.Done()
+	for {
+		conn, err := s.ln.Accept()
+		if s.closed.Load() || errors.Is(err, errClosed) || errors.Is(err, net.ErrClosed) {
+			if conn != nil {
+				conn.Close()
+			}
+			return
+		}
+		if err != nil {
+			// Error sementara (mis. klien putus saat terhubung): jangan
+			// sampai loop ini memakan CPU
+			time.Sleep(50 * time.Millisecond)
+			continue
+		}
+		go s.handle(conn)
+	}
+}
+
+// handle membaca pesan dari satu koneksi sampai klien menutupnya
+func (s *Server) handle(conn io.ReadWriteCloser) {
+	defer conn.Close()
+	r := bufio.NewReaderSize(conn, 4096)
+	enc := json.NewEncoder(conn)
+	for {
+		line, err := readLine(r)
+		if err != nil {
+			return
+		}
+		var m Message
+		var rep reply
+		if err := json.Unmarshal(line, &m); err != nil {
+			rep.Error = "pesan tidak valid: " + err.Error()
+		} else if err := m.Check(); err != nil {
+			rep.Error = err.Error()
+		} else if err := s.handler(m); err != nil {
+			rep.Error = err.Error()
+		}
+		if enc.Encode(rep) != nil {
+			return
+		}
+	}
+}
+
+// readLine membaca satu baris tanpa newline, maksimal maxMessageSize
+func readLine(r *bufio.Reader) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, isPrefix, err := r.ReadLine()
+		if err != nil {
+			return nil, err
+		}
+		line = append(line, chunk...)
+		if len(line) > maxMessageSize {
+			return nil, errors.New("pesan terlalu besar")
+		}
+		if !isPrefix {
+			return line, nil
+		}
+	}
+}
+
+// Send mengirim pesan ke instance yang mendengarkan di key dan menunggu
+// balasan setiap pesan. Pesan diproses berurutan; pengiriman berhenti di pesan
+// pertama yang gagal. Timeout 0 berarti DefaultTimeout, dan berlaku untuk
+// terhubung sampai balasan terakhir, jadi instance yang macet tidak membuat
+// pengirim ikut macet.
+func Send(key string, timeout time.Duration, msgs ...Message) error {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	deadline := time.Now().Add(timeout)
+	conn, err := dial(Address(key), deadline)
+	if err != nil {
+		return err
+	}
+	return exchange(conn, deadline, msgs)
+}
+
+// exchange mengirim msgs lewat conn sampai deadline, lalu menutup conn.
+// Koneksi tanpa SetDeadline (named pipe Windows) dilayani di goroutine
+// sendiri dan ditutup saat deadline lewat, yang menghentikan Read-nya.
+func exchange(conn io.ReadWriteCloser, deadline time.Time, msgs []Message) error {
+	defer conn.Close()
+
+	if d, ok := conn.(interface{ SetDeadline(time.Time) error }); ok {
+		d.SetDeadline(deadline)
+		return send(conn, msgs)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- send(conn, msgs) }()
+	timer := time.NewTimer(time.Until(deadline))
+	defer timer.Stop()
+	select {
+	case err := <-done:
+		return err
+	case <-timer.C:
+		return fmt.Errorf("instance tidak membalas: %w", os.ErrDeadlineExceeded)
+	}
+}
+
+func send(conn io.ReadWriter, msgs []Message) error {
+	r := bufio.NewReader(conn)
+	enc := json.NewEncoder(conn)
+	for _, m := range msgs {
+		if err := m.Check(); err != nil {
+			return err
+		}
+		if err := enc.Encode(m); err != nil {
+			return fmt.Errorf("gagal mengirim pesan %s: %w", m.Type, err)
+		}
+		line, err := readLine(r)
+		if err != nil {
+			return fmt.Errorf("tidak ada balasan untuk pesan %s: %w", m.Type, err)
+		}
+		var rep reply
+		if err := json.Unmarshal(line, &rep); err != nil {
+			return fmt.Errorf("balasan pesan %s tidak valid: %w", m.Type, err)
+		}
+		if rep.Error != "" {
+			return fmt.Errorf("pesan %s ditolak: %s", m.Type, rep.Error)
+		}
+	}
+	return nil
+}
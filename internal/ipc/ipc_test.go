@@ -0,0 +1,262 @@
+//go:build !windows
+
+package ipc
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// testKey membuat key unik dengan socket di direktori temp test
+func testKey(t *testing.T) string {
+	t.Helper()
+	t.Setenv("XDG_RUNTIME_DIR", t.TempDir())
+	return "w2app.test." + t.Name()
+}
+
+func listenTest(t *testing.T, key string, handler Handler) *Server {
+	t.Helper()
+	s, err := Listen(key, handler)
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	t.Cleanup(func() { s.Close() })
+	return s
+}
+
+func TestAddress(t *testing.T) {
+	t.Setenv("XDG_RUNTIME_DIR", "/run/user/1000")
+	a, b := Address("com.example.chat"), Address("com.example.chat#work")
+	if a == b {
+		t.Errorf("Address sama untuk key berbeda: %s", a)
+	}
+	if !strings.HasPrefix(a, "/run/user/1000/w2app-") || !strings.HasSuffix(a, ".sock") {
+		t.Errorf("Address = %s", a)
+	}
+	if Address("com.example.chat") != a {
+		t.Error("Address tidak stabil")
+	}
+}
+
+func TestSendNotRunning(t *testing.T) {
+	key := testKey(t)
+	if err := Send(key, 0, Message{Type: TypeShow}); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Send = %v, want ErrNotRunning", err)
+	}
+}
+
+func TestListenInUse(t *testing.T) {
+	key := testKey(t)
+	listenTest(t, key, func(Message) error { return nil })
+	if s, err := Listen(key, func(Message) error { return nil }); !errors.Is(err, ErrInUse) {
+		if s != nil {
+			s.Close()
+		}
+		t.Errorf("Listen kedua = %v, want ErrInUse", err)
+	}
+}
+
+func TestSendOrder(t *testing.T) {
+	key := testKey(t)
+	var mu sync.Mutex
+	var got []Message
+	listenTest(t, key, func(m Message) error {
+		mu.Lock()
+		got = append(got, m)
+		mu.Unlock()
+		return nil
+	})
+
+	want := []Message{
+		{Type: TypeShow},
+		{Type: TypeNotificationClick, ID: "notif-1"},
+		{Type: TypeOpenURL, URL: "https://example.com/a?b=c"},
+		{Type: TypeArgv, Args: []string{"--profile", "work", "w2app://open"}},
+	}
+	if err := Send(key, 0, want...); err != nil {
+		t.Fatalf("Send: %v", err)
+	}
+	// Send baru kembali setelah semua pesan dibalas, jadi semuanya sudah
+	// diproses
+	mu.Lock()
+	defer mu.Unlock()
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("pesan diterima = %+v, want %+v", got, want)
+	}
+}
+
+func TestSendHandlerError(t *testing.T) {
+	key := testKey(t)
+	var mu sync.Mutex
+	var received []string
+	listenTest(t, key, func(m Message) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, m.Type)
+		if m.Type == TypeOpenURL {
+			return errors.New("window belum siap")
+		}
+		return nil
+	})
+
+	err := Send(key, 0,
+		Message{Type: TypeShow},
+		Message{Type: TypeOpenURL, URL: "https://example.com"},
+		Message{Type: TypeArgv},
+	)
+	if err == nil || !strings.Contains(err.Error(), "window belum siap") {
+		t.Errorf("Send = %v, want error dari handler", err)
+	}
+	// Pengiriman berhenti di pesan yang gagal
+	mu.Lock()
+	defer mu.Unlock()
+	if want := []string{TypeShow, TypeOpenURL}; !reflect.DeepEqual(received, want) {
+		t.Errorf("pesan diterima = %v, want %v", received, want)
+	}
+}
+
+func TestSendInvalidMessage(t *testing.T) {
+	key := testKey(t)
+	called := false
+	listenTest(t, key, func(Message) error {
+		called = true
+		return nil
+	})
+	for _, m := range []Message{{Type: "reboot"}, {Type: TypeOpenURL}, {Type: TypeNotificationClick}} {
+		if err := Send(key, 0, m); err == nil {
+			t.Errorf("Send(%+v): error nil", m)
+		}
+	}
+	if called {
+		t.Error("handler dipanggil untuk pesan tidak valid")
+	}
+}
+
+func TestServerRejectsInvalid(t *testing.T) {
+	key := testKey(t)
+	listenTest(t, key, func(Message) error { return nil })
+
+	conn, err := net.Dial("unix", Address(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(time.Second))
+	io.WriteString(conn, "bukan json\n"+`{"type":"reboot"}`+"\n")
+	buf := make([]byte, 512)
+	var replies string
+	for strings.Count(replies, "\n") < 2 {
+		n, err := conn.Read(buf)
+		if err != nil {
+			t.Fatalf("Read: %v (balasan %q)", err, replies)
+		}
+		replies += string(buf[:n])
+	}
+	lines := strings.Split(strings.TrimSpace(replies), "\n")
+	if !strings.Contains(lines[0], "pesan tidak valid") || !strings.Contains(lines[1], "reboot") {
+		t.Errorf("balasan = %q", lines)
+	}
+}
+
+func TestSendTimeout(t *testing.T) {
+	key := testKey(t)
+	release := make(chan struct{})
+	listenTest(t, key, func(Message) error {
+		<-release
+		return nil
+	})
+	defer close(release)
+
+	start := time.Now()
+	err := Send(key, 200*time.Millisecond, Message{Type: TypeShow})
+	if err == nil {
+		t.Fatal("Send ke instance yang macet: error nil")
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("Send baru kembali setelah %s", d)
+	}
+}
+
+// noDeadlineConn menyembunyikan SetDeadline, seperti named pipe di Windows
+type noDeadlineConn struct {
+	io.ReadWriteCloser
+}
+
+func TestExchangeWithoutDeadline(t *testing.T) {
+	client, server := net.Pipe()
+	defer server.Close()
+	// Server membaca pesan tapi tidak pernah membalas
+	go io.Copy(io.Discard, server)
+
+	start := time.Now()
+	err := exchange(noDeadlineConn{client}, time.Now().Add(200*time.Millisecond), []Message{{Type: TypeShow}})
+	if !errors.Is(err, os.ErrDeadlineExceeded) {
+		t.Errorf("exchange = %v, want ErrDeadlineExceeded", err)
+	}
+	if d := time.Since(start); d > 2*time.Second {
+		t.Errorf("exchange baru kembali setelah %s", d)
+	}
+	// Koneksi ditutup, jadi goroutine yang membaca balasan ikut berhenti
+	if _, err := client.Write([]byte("x")); err == nil {
+		t.Error("koneksi masih terbuka")
+	}
+}
+
+func TestListenReplacesStaleSocket(t *testing.T) {
+	key := testKey(t)
+	path := Address(key)
+
+	// Socket sisa instance yang mati tanpa menghapus file-nya
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	ln.Close()
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("socket sisa tidak ada: %v", err)
+	}
+
+	listenTest(t, key, func(Message) error { return nil })
+	if err := Send(key, 0, Message{Type: TypeShow}); err != nil {
+		t.Errorf("Send setelah socket sisa diganti: %v", err)
+	}
+}
+
+func TestCloseRemovesSocket(t *testing.T) {
+	key := testKey(t)
+	s, err := Listen(key, func(Message) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(Address(key))
+	if err != nil {
+		t.Fatalf("socket tidak dibuat: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("permission socket = %o, want 600", perm)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+	if err := s.Close(); err != nil {
+		t.Errorf("Close kedua: %v", err)
+	}
+	if _, err := os.Stat(Address(key)); !os.IsNotExist(err) {
+		t.Errorf("socket masih ada setelah Close: %v", err)
+	}
+	if err := Send(key, 0, Message{Type: TypeShow}); !errors.Is(err, ErrNotRunning) {
+		t.Errorf("Send setelah Close = %v, want ErrNotRunning", err)
+	}
+
+	// Key yang sama bisa dipakai lagi
+	listenTest(t, key, func(Message) error { return nil })
+}
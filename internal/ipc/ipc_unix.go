@@ -0,0 +1,65 @@
+//go:build !windows
+
+package ipc
+
+import (
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+)
+
+// address menaruh socket di XDG_RUNTIME_DIR (khusus user) jika ada, atau di
+// direktori temp
+func address(name string) string {
+	dir := os.Getenv("XDG_RUNTIME_DIR")
+	if dir == "" {
+		dir = os.TempDir()
+	}
+	return filepath.Join(dir, name+".sock")
+}
+
+type unixListener struct {
+	*net.UnixListener
+}
+
+func (l unixListener) Accept() (io.ReadWriteCloser, error) {
+	return l.UnixListener.Accept()
+}
+
+// listen membuat unix socket di path. Socket sisa instance yang mati (tidak
+// ada yang menjawab) dihapus dulu. File socket dihapus saat listener ditutup.
+func listen(path string) (listener, error) {
+	ln, err := net.Listen("unix", path)
+	if errors.Is(err, syscall.EADDRINUSE) {
+		if conn, derr := net.DialTimeout("unix", path, time.Second); derr == nil {
+			conn.Close()
+			return nil, ErrInUse
+		}
+		os.Remove(path)
+		ln, err = net.Listen("unix", path)
+	}
+	if err != nil {
+		return nil, err
+	}
+	// Hanya user yang sama yang boleh mengirim pesan
+	if err := os.Chmod(path, 0600); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	return unixListener{ln.(*net.UnixListener)}, nil
+}
+
+func dial(path string, deadline time.Time) (io.ReadWriteCloser, error) {
+	conn, err := net.DialTimeout("unix", path, time.Until(deadline))
+	if errors.Is(err, syscall.ENOENT) || errors.Is(err, syscall.ECONNREFUSED) {
+		return nil, ErrNotRunning
+	}
+	if err != nil {
+		return nil, err
+	}
+	return conn, nil
+}
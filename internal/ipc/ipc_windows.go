@@ -0,0 +1,171 @@
+package ipc
+
+import (
+	"io"
+	"sync"
+	"time"
+
+	"golang.org/x/sys/windows"
+)
+
+const pipeBufferSize = 4096
+
+// address memakai SID user di nama pipe: nama named pipe berlaku untuk semua
+// sesi di mesin, sedangkan app user lain harus punya kanal sendiri
+func address(name string) string {
+	if u, err := windows.GetCurrentProcessToken().GetTokenUser(); err == nil {
+		name += "-" + u.User.Sid.String()
+	}
+	return `\\.\pipe\` + name
+}
+
+// pipeConn adalah satu ujung named pipe yang dibuka tanpa overlapped I/O
+type pipeConn struct {
+	h         windows.Handle
+	closeOnce sync.Once
+}
+
+func (c *pipeConn) Read(b []byte) (int, error) {
+	var n uint32
+	err := windows.ReadFile(c.h, b, &n, nil)
+	if err == windows.ERROR_BROKEN_PIPE {
+		return 0, io.EOF
+	}
+	return int(n), err
+}
+
+func (c *pipeConn) Write(b []byte) (int, error) {
+	var n uint32
+	err := windows.WriteFile(c.h, b, &n, nil)
+	return int(n), err
+}
+
+// Close membatalkan Read atau Write yang sedang menunggu di goroutine lain,
+// lalu menutup handle. Aman dipanggil lebih dari sekali.
+func (c *pipeConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		windows.CancelIoEx(c.h, nil)
+		err = windows.CloseHandle(c.h)
+	})
+	return err
+}
+
+// pipeListener menyiapkan satu instance pipe yang menunggu klien. Setelah
+// klien terhubung, instance baru dibuat untuk klien berikutnya.
+type pipeListener struct {
+	name   string
+	mu     sync.Mutex
+	next   windows.Handle
+	closed bool
+}
+
+func createPipe(name string, first bool) (windows.Handle, error) {
+	p, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return windows.InvalidHandle, err
+	}
+	flags := uint32(windows.PIPE_ACCESS_DUPLEX)
+	if first {
+		// Gagal jika pipe dengan nama ini sudah ada, jadi proses lain tidak
+		// bisa menyerobot nama pipe app
+		flags |= windows.FILE_FLAG_FIRST_PIPE_INSTANCE
+	}
+	mode := uint32(windows.PIPE_TYPE_BYTE | windows.PIPE_READMODE_BYTE | windows.PIPE_WAIT | windows.PIPE_REJECT_REMOTE_CLIENTS)
+	h, err := windows.CreateNamedPipe(p, flags, mode, windows.PIPE_UNLIMITED_INSTANCES,
+		pipeBufferSize, pipeBufferSize, 0, nil)
+	if err != nil {
+		if first && (err == windows.ERROR_ACCESS_DENIED || err == windows.ERROR_PIPE_BUSY) {
+			return windows.InvalidHandle, ErrInUse
+		}
+		return windows.InvalidHandle, err
+	}
+	return h, nil
+}
+
+func listen(name string) (listener, error) {
+	h, err := createPipe(name, true)
+	if err != nil {
+		return nil, err
+	}
+	return &pipeListener{name: name, next: h}, nil
+}
+
+func (l *pipeListener) Accept() (io.ReadWriteCloser, error) {
+	l.mu.Lock()
+	h := l.next
+	closed := l.closed
+	l.mu.Unlock()
+	if closed {
+		return nil, errClosed
+	}
+
+	err := windows.ConnectNamedPipe(h, nil)
+	if err == windows.ERROR_PIPE_CONNECTED {
+		err = nil
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.closed {
+		windows.CloseHandle(h)
+		l.next = windows.InvalidHandle
+		return nil, errClosed
+	}
+	// Instance yang gagal (mis. klien sudah putus) tidak bisa dipakai lagi,
+	// jadi instance baru tetap dibuat
+	next, nerr := createPipe(l.name, false)
+	if nerr != nil {
+		windows.CloseHandle(h)
+		l.closed = true
+		return nil, nerr
+	}
+	l.next = next
+	if err != nil {
+		windows.CloseHandle(h)
+		return nil, err
+	}
+	return &pipeConn{h: h}, nil
+}
+
+// Close menutup listener. ConnectNamedPipe yang sedang menunggu dibangunkan
+// dengan terhubung sebentar ke pipe sendiri.
+func (l *pipeListener) Close() error {
+	l.mu.Lock()
+	if l.closed {
+		l.mu.Unlock()
+		return nil
+	}
+	l.closed = true
+	l.mu.Unlock()
+
+	if conn, err := dial(l.name, time.Now().Add(time.Second)); err == nil {
+		conn.Close()
+	}
+	return nil
+}
+
+func dial(name string, deadline time.Time) (io.ReadWriteCloser, error) {
+	p, err := windows.UTF16PtrFromString(name)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		// SECURITY_IDENTIFICATION: server tidak bisa menyamar sebagai user ini
+		h, err := windows.CreateFile(p, windows.GENERIC_READ|windows.GENERIC_WRITE, 0, nil,
+			windows.OPEN_EXISTING, windows.SECURITY_SQOS_PRESENT|windows.SECURITY_IDENTIFICATION, 0)
+		switch {
+		case err == nil:
+			return &pipeConn{h: h}, nil
+		case err == windows.ERROR_FILE_NOT_FOUND:
+			return nil, ErrNotRunning
+		case err != windows.ERROR_PIPE_BUSY:
+			return nil, err
+		}
+		// Semua instance pipe sedang melayani klien lain
+		if time.Now().After(deadline) {
+			return nil, err
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -0,0 +1,233 @@
+package manifest
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"github.com/user/w2app/internal/config"
+	"github.com/user/w2app/internal/generator"
+)
+
+// Spec adalah pengaturan satu app di manifest. Nama key sama dengan flag
+// 'w2app create', kecuali nav-rules, deep-links, dan protocols yang berupa daftar
+// object.
+type Spec struct {
+	// Basic
+	URL      string `json:"url"`
+	Name     string `json:"name"`
+	AppID    string `json:"app-id"`
+	Output   string `json:"out" manifest:"path"`
+	Platform string `json:"platform"`
+	Arch     string `json:"arch"`
+	StubDir  string `json:"stub-dir" manifest:"path"`
+	Icon     string `json:"icon" manifest:"path"`
+	AutoIcon bool   `json:"auto-icon"`
+
+	// Window
+	Width          int    `json:"width"`
+	Height         int    `json:"height"`
+	Resizable      bool   `json:"resizable"`
+	Fullscreen     bool   `json:"fullscreen"`
+	Frameless      bool   `json:"frameless"`
+	AlwaysOnTop    bool   `json:"always-on-top"`
+	StartMaximized bool   `json:"maximized"`
+	TitleBarColor  string `json:"titlebar-color"`
+
+	// Placement
+	Monitor           string `json:"monitor"`
+	X                 *int   `json:"x"`
+	Y                 *int   `json:"y"`
+	Position          string `json:"position"`
+	FullscreenMonitor string `json:"fullscreen-monitor"`
+
+	// Behavior
+	SingleInstance     bool   `json:"single-instance"`
+	UserAgent          string `json:"user-agent"`
+	ClearCacheOnExit   bool   `json:"clear-cache"`
+	EnableNotification bool   `json:"enable-notification"`
+
+	// Data
+	DataDir     string   `json:"data-dir"`
+	Portable    bool     `json:"portable"`
+	ClearOnExit []string `json:"clear-on-exit"`
+
+	// Profiles
+	EnableProfiles bool     `json:"enable-profiles"`
+	Profiles       []string `json:"profiles"`
+
+	// Downloads
+	DownloadFolder string   `json:"download-dir"`
+	DownloadMode   string   `json:"download-mode"`
+	DownloadTypes  []string `json:"download-types"`
+
+	// Permissions
+	Permissions map[string]string `json:"permissions"`
+
+	// System Tray
+	EnableTray      bool `json:"tray"`
+	MinimizeToTray  bool `json:"minimize-to-tray"`
+	CloseToTray     bool `json:"close-to-tray"`
+	StartMinimized  bool `json:"start-minimized"`
+	EnableAutoStart bool `json:"auto-startup"`
+
+	// Injection
+	InjectCSS     string `json:"inject-css"`
+	InjectJS      string `json:"inject-js"`
+	InjectCSSFile string `json:"css-file" manifest:"path"`
+	InjectJSFile  string `json:"js-file" manifest:"path"`
+	UserScriptDir string `json:"userscripts" manifest:"path"`
+
+	// Navigation
+	Whitelist        []string         `json:"whitelist"`
+	BlockExternalNav bool             `json:"block-external"`
+	NavRules         []config.NavRule `json:"nav-rules"`
+
+	// Popups
+	PopupPolicy string   `json:"popup"`
+	AuthDomains []string `json:"auth-domains"`
+
+	// Deep links
+	DeepLinks []config.DeepLink `json:"deep-links"`
+
+	// Protocols
+	Protocols []config.Protocol `json:"protocols"`
+
+	// Network
+	Headers     []config.HeaderRule `json:"headers"`
+	BlockURLs   []string            `json:"block-urls"`
+	FilterLists []string            `json:"filter-lists" manifest:"path"`
+
+	// Advanced
+	DisableContextMenu bool `json:"no-context-menu"`
+	DisableDevTools    bool `json:"no-devtools"`
+
+	// Code signing
+	SignCert     string `json:"sign-cert" manifest:"path"`
+	SignPassEnv  string `json:"sign-pass-env"`
+	TimestampURL string `json:"timestamp-url"`
+}
+
+// defaultSpec berisi nilai default yang sama dengan default flag 'w2app create'
+func defaultSpec() Spec {
+	return Spec{
+		Platform:  "windows",
+		Arch:      generator.DefaultArch,
+		Width:     1024,
+		Height:    768,
+		Resizable: true,
+	}
+}
+
+// specFields memetakan key manifest ke field Spec
+var specFields = func() map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	t := reflect.TypeOf(Spec{})
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fields[f.Tag.Get("json")] = f
+	}
+	return fields
+}()
+
+// Keys mengembalikan semua key app yang dikenal manifest
+func Keys() []string {
+	t := reflect.TypeOf(Spec{})
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		keys = append(keys, t.Field(i).Tag.Get("json"))
+	}
+	return keys
+}
+
+// Options mengubah Spec menjadi opsi generator. Password sertifikat dibaca
+// dari environment variable sign-pass-env, sama seperti 'w2app create'.
+func (s *Spec) Options() (generator.Options, error) {
+	var signPassword string
+	if s.SignPassEnv != "" {
+		if s.SignCert == "" {
+			return generator.Options{}, fmt.Errorf("sign-pass-env membutuhkan sign-cert")
+		}
+		pass, ok := os.LookupEnv(s.SignPassEnv)
+		if !ok {
+			return generator.Options{}, fmt.Errorf("environment variable %s tidak di-set", s.SignPassEnv)
+		}
+		signPassword = pass
+	}
+
+	var whitelist []string
+	for _, domain := range s.Whitelist {
+		if domain = strings.TrimSpace(domain); domain != "" {
+			whitelist = append(whitelist, domain)
+		}
+	}
+
+	var stubDirs []string
+	if s.StubDir != "" {
+		stubDirs = []string{s.StubDir}
+	}
+
+	return generator.Options{
+		URL:                s.URL,
+		Name:               s.Name,
+		AppID:              s.AppID,
+		Output:             s.Output,
+		Platform:           strings.ToLower(s.Platform),
+		Arch:               strings.ToLower(s.Arch),
+		StubDirs:           stubDirs,
+		Icon:               s.Icon,
+		AutoIcon:           s.AutoIcon,
+		Width:              s.Width,
+		Height:             s.Height,
+		Resizable:          s.Resizable,
+		Fullscreen:         s.Fullscreen,
+		Frameless:          s.Frameless,
+		AlwaysOnTop:        s.AlwaysOnTop,
+		StartMaximized:     s.StartMaximized,
+		TitleBarColor:      s.TitleBarColor,
+		Monitor:            s.Monitor,
+		X:                  s.X,
+		Y:                  s.Y,
+		Position:           s.Position,
+		FullscreenMonitor:  s.FullscreenMonitor,
+		SingleInstance:     s.SingleInstance,
+		UserAgent:          s.UserAgent,
+		ClearCacheOnExit:   s.ClearCacheOnExit,
+		DataDir:            s.DataDir,
+		Portable:           s.Portable,
+		ClearOnExit:        s.ClearOnExit,
+		EnableProfiles:     s.EnableProfiles,
+		Profiles:           s.Profiles,
+		EnableNotification: s.EnableNotification,
+		DownloadFolder:     s.DownloadFolder,
+		DownloadMode:       s.DownloadMode,
+		DownloadTypes:      s.DownloadTypes,
+		Permissions:        s.Permissions,
+		EnableTray:         s.EnableTray,
+		MinimizeToTray:     s.MinimizeToTray,
+		CloseToTray:        s.CloseToTray,
+		StartMinimized:     s.StartMinimized,
+		EnableAutoStart:    s.EnableAutoStart,
+		InjectCSS:          s.InjectCSS,
+		InjectJS:           s.InjectJS,
+		InjectCSSFile:      s.InjectCSSFile,
+		InjectJSFile:       s.InjectJSFile,
+		UserScriptDir:      s.UserScriptDir,
+		Whitelist:          whitelist,
+		BlockExternalNav:   s.BlockExternalNav,
+		NavRules:           s.NavRules,
+		PopupPolicy:        s.PopupPolicy,
+		AuthDomains:        s.AuthDomains,
+		DeepLinks:          s.DeepLinks,
+		Protocols:          s.Protocols,
+		Headers:            s.Headers,
+		BlockURLs:          s.BlockURLs,
+		FilterListFiles:    s.FilterLists,
+		DisableContextMenu: s.DisableContextMenu,
+		DisableDevTools:    s.DisableDevTools,
+		SignCert:           s.SignCert,
+		SignPassword:       signPassword,
+		TimestampURL:       s.TimestampURL,
+	}, nil
+}
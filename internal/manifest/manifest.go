@@ -0,0 +1,453 @@
+// Package manifest membaca file manifest project (JSON atau YAML) yang berisi
+// daftar app untuk 'w2app build'.
+//
+// Contoh w2app.json:
+//
+//	{
+//	  "extends": "../shared/base.json",
+//	  "defaults": {"tray": true, "out": "dist"},
+//	  "apps": [
+//	    {"name": "WhatsApp", "url": "https://web.whatsapp.com", "icon": "icons/wa.png"},
+//	    {"name": "Telegram", "url": "https://web.telegram.org", "extends": "chat.json"}
+//	  ]
+//	}
+//
+// Key app sama dengan flag 'w2app create'. "extends" mewarisi "defaults" dari
+// manifest lain (beserta extends-nya sendiri), bisa di level file maupun per
+// app. Urutan prioritas dari rendah ke tinggi: extends file, defaults,
+// extends app, lalu key di app itu sendiri. Path relatif (icon, css-file,
+// js-file, sign-cert, out) selalu relatif terhadap file yang menuliskannya.
+package manifest
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// Manifest adalah hasil load file manifest beserta semua extends-nya
+type Manifest struct {
+	Path string
+	Apps []*App
+}
+
+// App adalah satu app di manifest yang sudah digabung dengan defaults
+type App struct {
+	Spec
+	File string // File dan baris tempat app dideklarasikan
+	Line int
+}
+
+// Source mengembalikan lokasi deklarasi app dalam format file:baris
+func (a *App) Source() string {
+	return fmt.Sprintf("%s:%d", a.File, a.Line)
+}
+
+// setting adalah nilai satu key beserta file yang menuliskannya
+type setting struct {
+	value *node
+	file  string
+	line  int
+}
+
+// layer adalah kumpulan setting hasil gabungan extends dan defaults
+type layer map[string]setting
+
+func (l layer) merge(other layer) {
+	for key, s := range other {
+		l[key] = s
+	}
+}
+
+// Load membaca manifest di path
+func Load(path string) (*Manifest, error) {
+	l := &loader{defaults: map[string]layer{}}
+
+	root, err := readFile(path)
+	if err != nil {
+		return nil, err
+	}
+	top, err := topLevel(path, root, true)
+	if err != nil {
+		return nil, err
+	}
+
+	base, err := l.fileDefaults(path, top)
+	if err != nil {
+		return nil, err
+	}
+
+	apps := top["apps"]
+	if apps == nil {
+		return nil, fmt.Errorf("%s: tidak ada app, tambahkan key \"apps\"", path)
+	}
+	if apps.Value.Kind != arrayNode {
+		return nil, &lineError{File: path, Line: apps.Line, Err: fmt.Errorf("apps harus berupa array")}
+	}
+
+	m := &Manifest{Path: path}
+	for _, item := range apps.Value.Items {
+		app, err := l.app(path, item, base)
+		if err != nil {
+			return nil, err
+		}
+		m.Apps = append(m.Apps, app)
+	}
+	if err := checkOutputs(m.Apps); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// LoadAll membaca beberapa manifest sekaligus dan mengembalikan semua app-nya.
+// App dari manifest berbeda juga tidak boleh menulis ke output yang sama.
+func LoadAll(paths []string) ([]*App, error) {
+	var apps []*App
+	for _, path := range paths {
+		m, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		apps = append(apps, m.Apps...)
+	}
+	if err := checkOutputs(apps); err != nil {
+		return nil, err
+	}
+	return apps, nil
+}
+
+// checkOutputs memastikan tidak ada dua app yang saling menimpa file output
+// atau memakai app-id yang sama
+func checkOutputs(apps []*App) error {
+	outputs := map[string]*App{}
+	ids := map[string]*App{}
+	for _, app := range apps {
+		if app.AppID != "" {
+			if prev, ok := ids[app.AppID]; ok {
+				return &lineError{File: app.File, Line: app.Line, Err: fmt.Errorf("app-id %q sudah dipakai app %q di %s", app.AppID, prev.Name, prev.Source())}
+			}
+			ids[app.AppID] = app
+		}
+
+		dir, err := filepath.Abs(app.Output)
+		if err != nil {
+			return err
+		}
+		key := strings.ToLower(filepath.Join(dir, app.Name) + "/" + app.Platform)
+		if prev, ok := outputs[key]; ok {
+			return &lineError{File: app.File, Line: app.Line, Err: fmt.Errorf("app %q sudah dideklarasikan di %s dengan output yang sama", app.Name, prev.Source())}
+		}
+		outputs[key] = app
+	}
+	return nil
+}
+
+// Names adalah nama file manifest yang dicari, berurutan
+var Names = []string{"w2app.json", "w2app.yaml", "w2app.yml"}
+
+// Find mencari semua manifest di root dan subdirektorinya. Direktori
+// tersembunyi, node_modules, dan vendor dilewati. Per direktori hanya satu
+// manifest yang dipakai, sesuai urutan Names.
+func Find(root string) ([]string, error) {
+	var paths []string
+	err := filepath.WalkDir(root, func(path string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		name := d.Name()
+		if path != root && (strings.HasPrefix(name, ".") || name == "node_modules" || name == "vendor") {
+			return filepath.SkipDir
+		}
+		for _, candidate := range Names {
+			p := filepath.Join(path, candidate)
+			if info, err := os.Stat(p); err == nil && !info.IsDir() {
+				paths = append(paths, p)
+				break
+			}
+		}
+		return nil
+	})
+	return paths, err
+}
+
+type loader struct {
+	defaults map[string]layer // Cache defaults per file (path absolut)
+	stack    []string         // File yang sedang di-load, untuk deteksi extends melingkar
+}
+
+// fileDefaults menggabungkan extends dan defaults milik satu file manifest
+func (l *loader) fileDefaults(path string, top map[string]*field) (layer, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if cached, ok := l.defaults[abs]; ok {
+		return cached, nil
+	}
+	for _, p := range l.stack {
+		if p == abs {
+			return nil, fmt.Errorf("extends melingkar: %s", strings.Join(append(l.stack, abs), " -> "))
+		}
+	}
+	l.stack = append(l.stack, abs)
+	defer func() { l.stack = l.stack[:len(l.stack)-1] }()
+
+	result := layer{}
+	if ext := top["extends"]; ext != nil {
+		base, err := l.extends(path, ext)
+		if err != nil {
+			return nil, err
+		}
+		result.merge(base)
+	}
+	if def := top["defaults"]; def != nil {
+		settings, err := appSettings(path, def.Value, false)
+		if err != nil {
+			return nil, err
+		}
+		result.merge(settings)
+	}
+
+	l.defaults[abs] = result
+	return result, nil
+}
+
+// extends me-load defaults dari manifest yang dirujuk key extends
+func (l *loader) extends(path string, ext *field) (layer, error) {
+	target, ok := ext.Value.Value.(string)
+	if ext.Value.Kind != scalarNode || !ok || target == "" {
+		return nil, &lineError{File: path, Line: ext.Line, Err: fmt.Errorf("extends harus berupa path file")}
+	}
+	target = resolvePath(path, target)
+
+	root, err := readFile(target)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, &lineError{File: path, Line: ext.Line, Err: fmt.Errorf("file extends %s tidak ditemukan", target)}
+		}
+		return nil, err
+	}
+	top, err := topLevel(target, root, false)
+	if err != nil {
+		return nil, err
+	}
+	return l.fileDefaults(target, top)
+}
+
+// app menggabungkan defaults dengan satu entry di apps
+func (l *loader) app(path string, item *node, base layer) (*App, error) {
+	if item.Kind != objectNode {
+		return nil, &lineError{File: path, Line: item.Line, Err: fmt.Errorf("setiap app harus berupa object")}
+	}
+
+	settings := layer{}
+	settings.merge(base)
+	for _, f := range item.Fields {
+		if f.Key != "extends" {
+			continue
+		}
+		ext, err := l.extends(path, &f)
+		if err != nil {
+			return nil, err
+		}
+		settings.merge(ext)
+	}
+	own, err := appSettings(path, item, true)
+	if err != nil {
+		return nil, err
+	}
+	settings.merge(own)
+
+	// Tanpa out, hasil build ditulis di sebelah manifest
+	app := &App{Spec: defaultSpec(), File: path, Line: item.Line}
+	app.Output = filepath.Dir(path)
+	if err := decodeSpec(&app.Spec, settings); err != nil {
+		return nil, err
+	}
+
+	if app.Name == "" {
+		return nil, &lineError{File: path, Line: item.Line, Err: fmt.Errorf("app tidak memiliki name")}
+	}
+	if app.URL == "" {
+		return nil, &lineError{File: path, Line: item.Line, Err: fmt.Errorf("app %q tidak memiliki url", app.Name)}
+	}
+	return app, nil
+}
+
+// readFile membaca dan mem-parse satu file manifest
+func readFile(path string) (*node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	root, err := parseFile(path, data)
+	if err != nil {
+		var le *lineError
+		if errors.As(err, &le) {
+			le.File = path
+			return nil, le
+		}
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return root, nil
+}
+
+// topLevel memetakan key level teratas manifest. File yang di-extend hanya
+// boleh berisi extends dan defaults.
+func topLevel(path string, root *node, allowApps bool) (map[string]*field, error) {
+	if root.Kind != objectNode {
+		return nil, &lineError{File: path, Line: root.Line, Err: fmt.Errorf("manifest harus berupa object")}
+	}
+
+	top := map[string]*field{}
+	for i := range root.Fields {
+		f := &root.Fields[i]
+		switch f.Key {
+		case "extends", "defaults":
+		case "apps":
+			if !allowApps {
+				return nil, &lineError{File: path, Line: f.Line, Err: fmt.Errorf("apps tidak boleh ada di file yang di-extend, pindahkan ke defaults")}
+			}
+		default:
+			return nil, &lineError{File: path, Line: f.Line, Err: fmt.Errorf("key %q tidak dikenal (gunakan extends, defaults, atau apps)", f.Key)}
+		}
+		if prev, ok := top[f.Key]; ok {
+			return nil, &lineError{File: path, Line: f.Line, Err: fmt.Errorf("key %q sudah ada di baris %d", f.Key, prev.Line)}
+		}
+		top[f.Key] = f
+	}
+	return top, nil
+}
+
+// appSettings mengecek key di object app (atau defaults) dan mengumpulkannya
+func appSettings(path string, obj *node, allowExtends bool) (layer, error) {
+	if obj.Kind != objectNode {
+		return nil, &lineError{File: path, Line: obj.Line, Err: fmt.Errorf("defaults harus berupa object")}
+	}
+
+	settings := layer{}
+	for _, f := range obj.Fields {
+		if f.Key == "extends" && allowExtends {
+			continue
+		}
+		if _, ok := specFields[f.Key]; !ok {
+			return nil, &lineError{File: path, Line: f.Line, Err: fmt.Errorf("key %q tidak dikenal%s", f.Key, suggestKey(f.Key))}
+		}
+		if prev, ok := settings[f.Key]; ok {
+			return nil, &lineError{File: path, Line: f.Line, Err: fmt.Errorf("key %q sudah ada di baris %d", f.Key, prev.line)}
+		}
+		settings[f.Key] = setting{value: f.Value, file: path, line: f.Line}
+	}
+	return settings, nil
+}
+
+// decodeSpec mengisi spec dari settings. Nilai dicek per key supaya error
+// tipe data bisa menunjuk ke baris yang salah.
+func decodeSpec(spec *Spec, settings layer) error {
+	v := reflect.ValueOf(spec).Elem()
+	for key, s := range settings {
+		sf := specFields[key]
+		value := s.value.plain()
+
+		// Angka untuk key string dipakai sebagai teks, mis. monitor: 2
+		if sf.Type.Kind() == reflect.String {
+			switch n := value.(type) {
+			case json.Number, int, float64:
+				value = fmt.Sprint(n)
+			}
+		}
+
+		isPath := sf.Tag.Get("manifest") == "path"
+		if str, ok := value.(string); ok {
+			switch {
+			case sf.Type.Kind() == reflect.Slice && sf.Type.Elem().Kind() == reflect.String:
+				// Sama seperti flag: daftar dipisah koma
+				var items []any
+				for _, item := range strings.Split(str, ",") {
+					items = append(items, item)
+				}
+				value = items
+			case isPath:
+				value = resolveSettingPath(s.file, key, str)
+			}
+		}
+		if items, ok := value.([]any); ok && isPath {
+			for i, item := range items {
+				if str, ok := item.(string); ok {
+					items[i] = resolveSettingPath(s.file, key, str)
+				}
+			}
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			return &lineError{File: s.file, Line: s.line, Err: err}
+		}
+		dec := json.NewDecoder(strings.NewReader(string(data)))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(v.FieldByIndex(sf.Index).Addr().Interface()); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				err = fmt.Errorf("%s harus berupa %s", key, kindName(sf.Type))
+			} else {
+				err = fmt.Errorf("nilai %s tidak valid: %w", key, err)
+			}
+			return &lineError{File: s.file, Line: s.line, Err: err}
+		}
+	}
+	return nil
+}
+
+// resolveSettingPath membuat path relatif terhadap file yang menuliskannya.
+// URL icon dibiarkan apa adanya.
+func resolveSettingPath(file, key, value string) string {
+	if value == "" || (key == "icon" && (strings.HasPrefix(value, "http://") || strings.HasPrefix(value, "https://"))) {
+		return value
+	}
+	return resolvePath(file, value)
+}
+
+func resolvePath(file, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(filepath.Dir(file), path)
+}
+
+func kindName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean (true/false)"
+	case reflect.Int:
+		return "angka"
+	case reflect.String:
+		return "string"
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.String {
+			return "array string"
+		}
+		return "array object"
+	case reflect.Map:
+		return "object"
+	case reflect.Pointer:
+		return kindName(t.Elem())
+	}
+	return t.String()
+}
+
+// suggestKey menyarankan key yang mirip untuk typo umum (mis. underscore
+// dari config JSON, atau flag dengan "--")
+func suggestKey(key string) string {
+	candidate := strings.ToLower(strings.TrimLeft(strings.ReplaceAll(key, "_", "-"), "-"))
+	if _, ok := specFields[candidate]; ok && candidate != key {
+		return fmt.Sprintf(", maksud Anda %q?", candidate)
+	}
+	return ""
+}
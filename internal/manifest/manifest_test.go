@@ -0,0 +1,400 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/user/w2app/internal/config"
+)
+
+// writeFiles menulis files (path relatif dengan "/") ke direktori temp
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadJSON(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"shared/base.json": `{
+  "defaults": {
+    "tray": true,
+    "width": 800,
+    "height": 600,
+    "icon": "icons/base.png",
+    "user-agent": "base"
+  }
+}`,
+		"chat.json": `{
+  "extends": "shared/base.json",
+  "defaults": {"height": 700, "user-agent": "chat", "single-instance": true}
+}`,
+		"w2app.json": `{
+  "extends": "shared/base.json",
+  "defaults": {"width": 900, "out": "dist", "user-agent": "defaults"},
+  "apps": [
+    {"name": "WhatsApp", "url": "https://web.whatsapp.com"},
+    {
+      "name": "Telegram",
+      "url": "https://web.telegram.org",
+      "extends": "chat.json",
+      "height": 500,
+      "icon": "https://telegram.org/favicon.ico",
+      "whitelist": "telegram.org, t.me",
+      "nav-rules": [{"pattern": "t.me/*", "action": "external"}]
+    }
+  ]
+}`,
+	})
+	path := filepath.Join(dir, "w2app.json")
+	m, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if len(m.Apps) != 2 {
+		t.Fatalf("%d app, want 2", len(m.Apps))
+	}
+
+	wa, tg := m.Apps[0], m.Apps[1]
+	if wa.Source() != path+":5" || tg.Source() != path+":6" {
+		t.Errorf("Source = %s, %s", wa.Source(), tg.Source())
+	}
+
+	// extends file < defaults < extends app < key app
+	if !wa.EnableTray || wa.Width != 900 || wa.Height != 600 || wa.UserAgent != "defaults" || wa.SingleInstance {
+		t.Errorf("WhatsApp = %+v", wa.Spec)
+	}
+	if !tg.EnableTray || tg.Width != 800 || tg.Height != 500 || tg.UserAgent != "chat" || !tg.SingleInstance {
+		t.Errorf("Telegram = %+v", tg.Spec)
+	}
+
+	// Path relatif terhadap file yang menuliskannya; URL icon apa adanya
+	if want := filepath.Join(dir, "shared", "icons", "base.png"); wa.Icon != want {
+		t.Errorf("icon = %q, want %q", wa.Icon, want)
+	}
+	if tg.Icon != "https://telegram.org/favicon.ico" {
+		t.Errorf("icon URL = %q", tg.Icon)
+	}
+	if want := filepath.Join(dir, "dist"); wa.Output != want || tg.Output != want {
+		t.Errorf("out = %q, %q, want %q", wa.Output, tg.Output, want)
+	}
+
+	// Default yang sama dengan flag 'w2app create'
+	if wa.Platform != "windows" || !wa.Resizable {
+		t.Errorf("default = %+v", wa.Spec)
+	}
+	if want := []string{"telegram.org", " t.me"}; !reflect.DeepEqual(tg.Whitelist, want) {
+		t.Errorf("whitelist = %q, want %q", tg.Whitelist, want)
+	}
+	if want := []config.NavRule{{Pattern: "t.me/*", Action: "external"}}; !reflect.DeepEqual(tg.NavRules, want) {
+		t.Errorf("nav-rules = %+v", tg.NavRules)
+	}
+}
+
+func TestLoadYAML(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"base.yml": `defaults:
+  tray: true
+  css-file: style.css
+`,
+		"w2app.yaml": `# Daftar app
+extends: base.yml
+defaults: &common
+  monitor: 2
+  width: 1280
+apps:
+  - name: Mail
+    url: https://mail.example.com
+    clear-on-exit: cache,cookies
+    permissions:
+      camera: deny
+      notifications@https://mail.example.com: allow
+    x: -100
+  - name: Docs
+    url: https://docs.example.com
+    whitelist:
+      - docs.example.com
+      - "*.example.com"
+    out: build/docs
+`,
+	})
+	path := filepath.Join(dir, "w2app.yaml")
+	m, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	mail, docs := m.Apps[0], m.Apps[1]
+	if mail.Line != 7 || docs.Line != 14 {
+		t.Errorf("Line = %d, %d, want 7, 14", mail.Line, docs.Line)
+	}
+	// Angka untuk key string dipakai sebagai teks
+	if mail.Monitor != "2" || mail.Width != 1280 || !mail.EnableTray {
+		t.Errorf("Mail = %+v", mail.Spec)
+	}
+	if mail.X == nil || *mail.X != -100 || mail.Y != nil {
+		t.Errorf("x, y = %v, %v", mail.X, mail.Y)
+	}
+	if want := []string{"cache", "cookies"}; !reflect.DeepEqual(mail.ClearOnExit, want) {
+		t.Errorf("clear-on-exit = %q", mail.ClearOnExit)
+	}
+	if want := map[string]string{"camera": "deny", "notifications@https://mail.example.com": "allow"}; !reflect.DeepEqual(mail.Permissions, want) {
+		t.Errorf("permissions = %v", mail.Permissions)
+	}
+	if want := filepath.Join(dir, "style.css"); docs.InjectCSSFile != want {
+		t.Errorf("css-file = %q, want %q", docs.InjectCSSFile, want)
+	}
+	if want := []string{"docs.example.com", "*.example.com"}; !reflect.DeepEqual(docs.Whitelist, want) {
+		t.Errorf("whitelist = %q", docs.Whitelist)
+	}
+	if want := filepath.Join(dir, "build", "docs"); docs.Output != want {
+		t.Errorf("out = %q, want %q", docs.Output, want)
+	}
+	if want := dir; mail.Output != want {
+		t.Errorf("out default = %q, want %q", mail.Output, want)
+	}
+}
+
+func TestLoadError(t *testing.T) {
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string // {dir} diganti direktori temp
+	}{
+		{
+			name: "key tidak dikenal dengan saran",
+			files: map[string]string{"w2app.json": `{
+  "apps": [
+    {
+      "name": "A",
+      "url": "https://a.com",
+      "always_on_top": true
+    }
+  ]
+}`},
+			want: `{dir}/w2app.json:6: key "always_on_top" tidak dikenal, maksud Anda "always-on-top"?`,
+		},
+		{
+			name:  "key flag dengan --",
+			files: map[string]string{"w2app.yaml": "apps:\n  - name: A\n    url: https://a.com\n    --tray: true\n"},
+			want:  `{dir}/w2app.yaml:4: key "--tray" tidak dikenal, maksud Anda "tray"?`,
+		},
+		{
+			name:  "key tidak dikenal tanpa saran",
+			files: map[string]string{"w2app.yaml": "defaults:\n  colour: red\napps: []\n"},
+			want:  `{dir}/w2app.yaml:2: key "colour" tidak dikenal`,
+		},
+		{
+			name:  "key top level",
+			files: map[string]string{"w2app.json": "{\n  \"app\": []\n}"},
+			want:  `{dir}/w2app.json:2: key "app" tidak dikenal (gunakan extends, defaults, atau apps)`,
+		},
+		{
+			name: "tipe angka",
+			files: map[string]string{"w2app.json": `{
+  "apps": [{
+    "name": "A", "url": "https://a.com",
+    "width": "lebar"
+  }]
+}`},
+			want: `{dir}/w2app.json:4: width harus berupa angka`,
+		},
+		{
+			name:  "tipe boolean YAML",
+			files: map[string]string{"w2app.yaml": "apps:\n  - name: A\n    url: https://a.com\n    tray: yes\n"},
+			want:  `{dir}/w2app.yaml:4: tray harus berupa boolean (true/false)`,
+		},
+		{
+			name:  "tipe array object",
+			files: map[string]string{"w2app.yaml": "apps:\n  - name: A\n    url: https://a.com\n    nav-rules: t.me\n"},
+			want:  `{dir}/w2app.yaml:4: nav-rules harus berupa array object`,
+		},
+		{
+			name:  "key ganda",
+			files: map[string]string{"w2app.json": "{\"apps\": [{\n  \"name\": \"A\",\n  \"url\": \"https://a.com\",\n  \"name\": \"B\"\n}]}"},
+			want:  `{dir}/w2app.json:4: key "name" sudah ada di baris 2`,
+		},
+		{
+			name:  "apps bukan array",
+			files: map[string]string{"w2app.yaml": "defaults: {}\napps:\n  name: A\n"},
+			want:  `{dir}/w2app.yaml:2: apps harus berupa array`,
+		},
+		{
+			name:  "tanpa apps",
+			files: map[string]string{"w2app.json": `{"defaults": {}}`},
+			want:  `{dir}/w2app.json: tidak ada app, tambahkan key "apps"`,
+		},
+		{
+			name:  "tanpa url",
+			files: map[string]string{"w2app.yaml": "apps:\n  - name: A\n  - name: B\n    url: https://b.com\n"},
+			want:  `{dir}/w2app.yaml:2: app "A" tidak memiliki url`,
+		},
+		{
+			name:  "syntax JSON",
+			files: map[string]string{"w2app.json": "{\n  \"apps\": [\n    {\"name\": \"A\"},,\n  ]\n}"},
+			want:  `{dir}/w2app.json:3: invalid character ',' looking for beginning of value`,
+		},
+		{
+			name:  "JSON tidak lengkap",
+			files: map[string]string{"w2app.json": "{\n  \"apps\": [\n"},
+			want:  `{dir}/w2app.json:3: unexpected end of JSON input`,
+		},
+		{
+			name:  "JSON kosong",
+			files: map[string]string{"w2app.json": ""},
+			want:  `{dir}/w2app.json:1: JSON tidak lengkap`,
+		},
+		{
+			name:  "syntax YAML",
+			files: map[string]string{"w2app.yaml": "apps:\n  - name: A\n    url: a: b\n"},
+			want:  `{dir}/w2app.yaml:3: mapping values are not allowed in this context`,
+		},
+		{
+			name:  "merge key YAML",
+			files: map[string]string{"w2app.yaml": "defaults: &b {tray: true}\napps:\n  - <<: *b\n    name: A\n"},
+			want:  `{dir}/w2app.yaml:3: merge key '<<' tidak didukung, gunakan extends`,
+		},
+		{
+			name:  "extends tidak ada",
+			files: map[string]string{"w2app.json": "{\n  \"extends\": \"base.json\",\n  \"apps\": []\n}"},
+			want:  `{dir}/w2app.json:2: file extends {dir}/base.json tidak ditemukan`,
+		},
+		{
+			name:  "extends bukan string",
+			files: map[string]string{"w2app.yaml": "extends: [a.yml]\napps: []\n"},
+			want:  `{dir}/w2app.yaml:1: extends harus berupa path file`,
+		},
+		{
+			name: "extends berisi apps",
+			files: map[string]string{
+				"w2app.yaml": "extends: base.yaml\napps: []\n",
+				"base.yaml":  "defaults: {}\napps: []\n",
+			},
+			want: `{dir}/base.yaml:2: apps tidak boleh ada di file yang di-extend, pindahkan ke defaults`,
+		},
+		{
+			// Error di file yang di-extend menunjuk ke file itu
+			name: "error di file extends",
+			files: map[string]string{
+				"w2app.yaml":       "extends: shared/base.json\napps:\n  - name: A\n    url: https://a.com\n",
+				"shared/base.json": "{\n  \"defaults\": {\n    \"height\": true\n  }\n}",
+			},
+			want: `{dir}/shared/base.json:3: height harus berupa angka`,
+		},
+		{
+			name: "extends melingkar",
+			files: map[string]string{
+				"w2app.json": `{"extends": "a.json", "apps": []}`,
+				"a.json":     `{"extends": "sub/b.json"}`,
+				"sub/b.json": `{"extends": "../a.json"}`,
+			},
+			want: `extends melingkar: {dir}/w2app.json -> {dir}/a.json -> {dir}/sub/b.json -> {dir}/a.json`,
+		},
+		{
+			name: "output sama",
+			files: map[string]string{"w2app.yaml": `apps:
+  - name: Chat
+    url: https://a.com
+  - name: chat
+    url: https://b.com
+`},
+			want: `{dir}/w2app.yaml:4: app "chat" sudah dideklarasikan di {dir}/w2app.yaml:2 dengan output yang sama`,
+		},
+		{
+			name: "app-id sama",
+			files: map[string]string{"w2app.yaml": `defaults:
+  app-id: com.example.chat
+apps:
+  - name: A
+    url: https://a.com
+  - name: B
+    url: https://b.com
+`},
+			want: `{dir}/w2app.yaml:6: app-id "com.example.chat" sudah dipakai app "A" di {dir}/w2app.yaml:4`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := writeFiles(t, tt.files)
+			name := "w2app.json"
+			if _, ok := tt.files["w2app.yaml"]; ok {
+				name = "w2app.yaml"
+			}
+			_, err := Load(filepath.Join(dir, name))
+			if err == nil {
+				t.Fatal("error nil")
+			}
+			want := strings.ReplaceAll(tt.want, "{dir}/", dir+string(filepath.Separator))
+			want = strings.ReplaceAll(filepath.FromSlash(want), "{dir}", dir)
+			if !strings.HasPrefix(err.Error(), want) {
+				t.Errorf("error = %q\nwant   %q", err, want)
+			}
+		})
+	}
+}
+
+func TestLoadAll(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"a/w2app.yaml": "apps:\n  - name: Chat\n    url: https://a.com\n    out: ../dist\n",
+		"b/w2app.yaml": "apps:\n  - name: Chat\n    url: https://b.com\n    out: ../dist\n",
+	})
+	a, b := filepath.Join(dir, "a", "w2app.yaml"), filepath.Join(dir, "b", "w2app.yaml")
+	if _, err := LoadAll([]string{a}); err != nil {
+		t.Fatalf("LoadAll: %v", err)
+	}
+	_, err := LoadAll([]string{a, b})
+	if err == nil || !strings.HasPrefix(err.Error(), b+":2: ") || !strings.Contains(err.Error(), a+":2") {
+		t.Errorf("LoadAll = %v", err)
+	}
+}
+
+func TestFind(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"w2app.yaml":                "apps: []",
+		"w2app.yml":                 "apps: []",
+		"chat/w2app.json":           "{}",
+		"chat/w2app.yaml":           "apps: []",
+		"tools/deep/w2app.yml":      "apps: []",
+		".git/w2app.json":           "{}",
+		"node_modules/x/w2app.json": "{}",
+		"vendor/w2app.json":         "{}",
+		"other/app.json":            "{}",
+	})
+	paths, err := Find(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join(dir, "w2app.yaml"),
+		filepath.Join(dir, "chat", "w2app.json"),
+		filepath.Join(dir, "tools", "deep", "w2app.yml"),
+	}
+	if !reflect.DeepEqual(paths, want) {
+		t.Errorf("Find = %q, want %q", paths, want)
+	}
+}
+
+func TestSuggestKey(t *testing.T) {
+	for key, want := range map[string]string{
+		"always_on_top": `, maksud Anda "always-on-top"?`,
+		"--tray":        `, maksud Anda "tray"?`,
+		"Width":         `, maksud Anda "width"?`,
+		"tray":          "",
+		"colour":        "",
+	} {
+		if got := suggestKey(key); got != want {
+			t.Errorf("suggestKey(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
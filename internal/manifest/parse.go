@@ -0,0 +1,220 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// node adalah nilai di file manifest beserta nomor barisnya, sama untuk
+// JSON maupun YAML, supaya error bisa menunjuk ke baris yang salah
+type node struct {
+	Line   int
+	Fields []field // Object
+	Items  []*node // Array
+	Value  any     // Scalar: string, bool, nil, json.Number, int, float64
+	Kind   nodeKind
+}
+
+type field struct {
+	Key   string
+	Line  int
+	Value *node
+}
+
+type nodeKind int
+
+const (
+	scalarNode nodeKind = iota
+	objectNode
+	arrayNode
+)
+
+// parseFile mem-parse isi manifest berdasarkan ekstensi file
+func parseFile(path string, data []byte) (*node, error) {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return parseYAML(data)
+	}
+	return parseJSON(data)
+}
+
+// parseJSON membaca JSON token demi token supaya posisi setiap key diketahui
+func parseJSON(data []byte) (*node, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	lineAt := func(offset int64) int {
+		return bytes.Count(data[:offset], []byte("\n")) + 1
+	}
+
+	n, err := readJSON(dec, lineAt)
+	if err == nil {
+		if _, err = dec.Token(); err == io.EOF {
+			return n, nil
+		} else if err == nil {
+			err = fmt.Errorf("ada data setelah akhir dokumen")
+		}
+	}
+
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		return nil, &lineError{Line: lineAt(syntaxErr.Offset), Err: syntaxErr}
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, &lineError{Line: lineAt(int64(len(data))), Err: fmt.Errorf("JSON tidak lengkap")}
+	}
+	var le *lineError
+	if errors.As(err, &le) {
+		return nil, err
+	}
+	return nil, &lineError{Line: lineAt(dec.InputOffset()), Err: err}
+}
+
+func readJSON(dec *json.Decoder, lineAt func(int64) int) (*node, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+	n := &node{Line: lineAt(dec.InputOffset())}
+
+	delim, ok := tok.(json.Delim)
+	if !ok {
+		n.Value = tok
+		return n, nil
+	}
+
+	switch delim {
+	case '{':
+		n.Kind = objectNode
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			f := field{Key: keyTok.(string), Line: lineAt(dec.InputOffset())}
+			if f.Value, err = readJSON(dec, lineAt); err != nil {
+				return nil, err
+			}
+			n.Fields = append(n.Fields, f)
+		}
+	case '[':
+		n.Kind = arrayNode
+		for dec.More() {
+			item, err := readJSON(dec, lineAt)
+			if err != nil {
+				return nil, err
+			}
+			n.Items = append(n.Items, item)
+		}
+	}
+
+	// Delimiter penutup
+	if _, err := dec.Token(); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+var yamlLineRe = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+func parseYAML(data []byte) (*node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		// Ubah "yaml: line N: ..." menjadi format file:baris yang sama dengan JSON
+		if m := yamlLineRe.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			return nil, &lineError{Line: line, Err: errors.New(m[2])}
+		}
+		return nil, err
+	}
+	if doc.Kind == 0 {
+		return &node{Line: 1, Kind: objectNode}, nil
+	}
+	return convertYAML(&doc)
+}
+
+func convertYAML(y *yaml.Node) (*node, error) {
+	n := &node{Line: y.Line}
+	switch y.Kind {
+	case yaml.DocumentNode:
+		return convertYAML(y.Content[0])
+	case yaml.AliasNode:
+		return convertYAML(y.Alias)
+	case yaml.MappingNode:
+		n.Kind = objectNode
+		for i := 0; i+1 < len(y.Content); i += 2 {
+			key := y.Content[i]
+			if key.Kind != yaml.ScalarNode {
+				return nil, &lineError{Line: key.Line, Err: fmt.Errorf("key harus berupa string")}
+			}
+			// Merge key YAML (<<) tidak didukung, pakai extends
+			if key.Tag == "!!merge" {
+				return nil, &lineError{Line: key.Line, Err: fmt.Errorf("merge key '<<' tidak didukung, gunakan extends")}
+			}
+			value, err := convertYAML(y.Content[i+1])
+			if err != nil {
+				return nil, err
+			}
+			n.Fields = append(n.Fields, field{Key: key.Value, Line: key.Line, Value: value})
+		}
+	case yaml.SequenceNode:
+		n.Kind = arrayNode
+		for _, c := range y.Content {
+			item, err := convertYAML(c)
+			if err != nil {
+				return nil, err
+			}
+			n.Items = append(n.Items, item)
+		}
+	default:
+		if err := y.Decode(&n.Value); err != nil {
+			return nil, &lineError{Line: y.Line, Err: err}
+		}
+	}
+	return n, nil
+}
+
+// plain mengubah node menjadi nilai Go biasa yang bisa di-marshal ke JSON
+func (n *node) plain() any {
+	switch n.Kind {
+	case objectNode:
+		m := make(map[string]any, len(n.Fields))
+		for _, f := range n.Fields {
+			m[f.Key] = f.Value.plain()
+		}
+		return m
+	case arrayNode:
+		items := make([]any, len(n.Items))
+		for i, item := range n.Items {
+			items[i] = item.plain()
+		}
+		return items
+	}
+	return n.Value
+}
+
+// lineError adalah error yang terjadi pada baris tertentu di file manifest
+type lineError struct {
+	File string
+	Line int
+	Err  error
+}
+
+func (e *lineError) Error() string {
+	if e.File == "" {
+		return fmt.Sprintf("baris %d: %v", e.Line, e.Err)
+	}
+	return fmt.Sprintf("%s:%d: %v", e.File, e.Line, e.Err)
+}
+
+func (e *lineError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,187 @@
+package navpolicy
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// Pattern adalah pola URL untuk aturan navigasi.
+//
+// Format yang didukung:
+//
+//	example.com             host persis
+//	.example.com            example.com dan semua subdomain-nya
+//	*.example.com           subdomain example.com saja
+//	example.com/docs        path /docs dan semua turunannya
+//	example.com/api/*/v2    wildcard di path
+//	https://example.com     hanya untuk scheme tertentu
+//	localhost:8080          host dengan port tertentu
+//
+// Pola "*" cocok dengan semua URL http/https.
+type Pattern struct {
+	raw    string
+	scheme string // kosong atau "*" berarti http dan https
+	host   string // glob, kosong berarti semua host
+	port   string
+	path   string // glob, kosong berarti semua path
+	suffix bool   // pola diawali "." (domain + subdomain)
+}
+
+// ParsePattern mem-parse string pola menjadi Pattern
+func ParsePattern(s string) (*Pattern, error) {
+	raw := strings.TrimSpace(s)
+	if raw == "" {
+		return nil, fmt.Errorf("pola kosong")
+	}
+
+	p := &Pattern{raw: raw}
+	rest := raw
+
+	if i := strings.Index(rest, "://"); i >= 0 {
+		p.scheme = strings.ToLower(rest[:i])
+		rest = rest[i+3:]
+		if p.scheme == "" {
+			return nil, fmt.Errorf("pola %q: scheme kosong", raw)
+		}
+	}
+
+	hostPart := rest
+	if i := strings.Index(rest, "/"); i >= 0 {
+		hostPart = rest[:i]
+		p.path = rest[i:]
+	}
+
+	if hostPart == "" {
+		return nil, fmt.Errorf("pola %q: host kosong", raw)
+	}
+
+	if i := strings.LastIndex(hostPart, ":"); i >= 0 && !strings.Contains(hostPart[i:], "]") {
+		p.port = hostPart[i+1:]
+		hostPart = hostPart[:i]
+	}
+
+	hostPart = strings.ToLower(hostPart)
+	// Host IPv6 ditulis dalam kurung siku, sedangkan Match membandingkan
+	// dengan u.Hostname() yang tanpa kurung
+	if strings.HasPrefix(hostPart, "[") && strings.HasSuffix(hostPart, "]") {
+		hostPart = hostPart[1 : len(hostPart)-1]
+		if hostPart == "" {
+			return nil, fmt.Errorf("pola %q: host kosong", raw)
+		}
+	}
+	if strings.HasPrefix(hostPart, ".") {
+		p.suffix = true
+		hostPart = strings.TrimPrefix(hostPart, ".")
+		if hostPart == "" {
+			return nil, fmt.Errorf("pola %q: domain kosong", raw)
+		}
+	}
+	if hostPart != "*" {
+		p.host = hostPart
+	}
+
+	if p.path == "/" || p.path == "/*" {
+		p.path = ""
+	}
+
+	return p, nil
+}
+
+// String mengembalikan pola dalam bentuk aslinya
+func (p *Pattern) String() string {
+	return p.raw
+}
+
+// Match mengecek apakah URL cocok dengan pola
+func (p *Pattern) Match(u *url.URL) bool {
+	scheme := strings.ToLower(u.Scheme)
+	switch p.scheme {
+	case "", "*":
+		if scheme != "http" && scheme != "https" {
+			return false
+		}
+	default:
+		if scheme != p.scheme {
+			return false
+		}
+	}
+
+	if p.host != "" && !p.matchHost(strings.ToLower(u.Hostname())) {
+		return false
+	}
+
+	if p.port != "" && p.port != "*" && p.port != effectivePort(u) {
+		return false
+	}
+
+	if p.path != "" && !matchPath(p.path, u.EscapedPath()) {
+		return false
+	}
+
+	return true
+}
+
+func (p *Pattern) matchHost(host string) bool {
+	if p.suffix {
+		return host == p.host || strings.HasSuffix(host, "."+p.host)
+	}
+	return globMatch(p.host, host)
+}
+
+// matchPath mencocokkan path dengan glob. Pola tanpa wildcard di akhir juga
+// cocok dengan semua path di bawahnya ("/docs" cocok dengan "/docs/a").
+func matchPath(pattern, path string) bool {
+	if path == "" {
+		path = "/"
+	}
+	if globMatch(pattern, path) {
+		return true
+	}
+	if !strings.HasSuffix(pattern, "*") {
+		return globMatch(strings.TrimSuffix(pattern, "/")+"/*", path)
+	}
+	return false
+}
+
+// effectivePort mengembalikan port URL, termasuk port default scheme
+func effectivePort(u *url.URL) string {
+	if port := u.Port(); port != "" {
+		return port
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http":
+		return "80"
+	case "https":
+		return "443"
+	}
+	return ""
+}
+
+// globMatch mencocokkan s dengan pola yang boleh berisi '*' (nol atau lebih
+// karakter apa saja) dan '?' (tepat satu karakter)
+func globMatch(pattern, s string) bool {
+	px, sx := 0, 0
+	starPx, starSx := -1, 0
+	for sx < len(s) {
+		switch {
+		case px < len(pattern) && (pattern[px] == '?' || pattern[px] == s[sx]):
+			px++
+			sx++
+		case px < len(pattern) && pattern[px] == '*':
+			starPx = px
+			starSx = sx
+			px++
+		case starPx >= 0:
+			px = starPx + 1
+			starSx++
+			sx = starSx
+		default:
+			return false
+		}
+	}
+	for px < len(pattern) && pattern[px] == '*' {
+		px++
+	}
+	return px == len(pattern)
+}
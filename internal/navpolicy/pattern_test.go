@@ -0,0 +1,107 @@
+package navpolicy
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParsePatternError(t *testing.T) {
+	for _, s := range []string{"", "  ", "://example.com", "https://", "/docs", ".", "[]:80"} {
+		if _, err := ParsePattern(s); err == nil {
+			t.Errorf("ParsePattern(%q): error nil", s)
+		}
+	}
+}
+
+func TestPatternMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		url     string
+		want    bool
+	}{
+		{"*", "https://example.com/a", true},
+		{"*", "http://example.com", true},
+		{"*", "ftp://example.com", false},
+
+		{"example.com", "https://example.com/", true},
+		{"example.com", "https://EXAMPLE.com/a/b", true},
+		{"example.com", "https://www.example.com/", false},
+		{"example.com", "mailto:a@example.com", false},
+
+		{".example.com", "https://example.com/", true},
+		{".example.com", "https://a.b.example.com/", true},
+		{".example.com", "https://badexample.com/", false},
+
+		{"*.example.com", "https://a.example.com/", true},
+		{"*.example.com", "https://example.com/", false},
+
+		{"ex?mple.com", "https://example.com/", true},
+		{"ex?mple.com", "https://exmple.com/", false},
+
+		{"example.com/docs", "https://example.com/docs", true},
+		{"example.com/docs", "https://example.com/docs/a/b", true},
+		{"example.com/docs", "https://example.com/docsx", false},
+		{"example.com/docs/", "https://example.com/docs/a", true},
+		{"example.com/", "https://example.com/anything", true},
+		{"example.com/*", "https://example.com/", true},
+
+		{"example.com/api/*/v2", "https://example.com/api/x/v2", true},
+		{"example.com/api/*/v2", "https://example.com/api/x/y/v2", true},
+		{"example.com/api/*/v2", "https://example.com/api/x/v3", false},
+
+		{"https://example.com", "https://example.com/", true},
+		{"https://example.com", "http://example.com/", false},
+		{"*://example.com", "http://example.com/", true},
+		{"mailto://*", "mailto://x", true},
+
+		{"localhost:8080", "http://localhost:8080/", true},
+		{"localhost:8080", "http://localhost:8081/", false},
+		{"localhost:8080", "http://localhost/", false},
+		{"example.com:443", "https://example.com/", true},
+		{"example.com:80", "http://example.com/", true},
+		{"localhost:*", "http://localhost:3000/", true},
+
+		{"http://[::1]:8080/*", "http://[::1]:8080/a", true},
+		{"http://[::1]:8080/*", "http://[::1]:8081/a", false},
+		{"[::1]", "http://[::1]/", true},
+		{"[::1]", "http://[::2]/", false},
+		{"[FE80::1]", "http://[fe80::1]:3000/", true},
+	}
+	for _, tt := range tests {
+		p, err := ParsePattern(tt.pattern)
+		if err != nil {
+			t.Errorf("ParsePattern(%q): %v", tt.pattern, err)
+			continue
+		}
+		u, err := url.Parse(tt.url)
+		if err != nil {
+			t.Fatalf("url.Parse(%q): %v", tt.url, err)
+		}
+		if got := p.Match(u); got != tt.want {
+			t.Errorf("%q.Match(%q) = %v, want %v", tt.pattern, tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"", "", true},
+		{"*", "", true},
+		{"*", "abc", true},
+		{"a*c", "abbbc", true},
+		{"a*c", "abcd", false},
+		{"a?c", "abc", true},
+		{"a?c", "ac", false},
+		{"*b*", "abc", true},
+		{"**", "x", true},
+		{"a*b*c", "aXbYbZc", true},
+	}
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.s); got != tt.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", tt.pattern, tt.s, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,184 @@
+// Package navpolicy menentukan apa yang terjadi ketika halaman di dalam app
+// bernavigasi ke URL lain: dibuka di dalam app, dibuka di browser default,
+// atau diblokir.
+package navpolicy
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/user/w2app/internal/config"
+)
+
+// Action adalah keputusan untuk sebuah navigasi
+type Action string
+
+const (
+	Allow    Action = "allow"    // Buka di dalam app
+	External Action = "external" // Buka di browser default
+	Block    Action = "block"    // Batalkan navigasi
+)
+
+// ParseAction mengubah string dari config menjadi Action
+func ParseAction(s string) (Action, error) {
+	switch Action(strings.ToLower(strings.TrimSpace(s))) {
+	case Allow:
+		return Allow, nil
+	case External:
+		return External, nil
+	case Block:
+		return Block, nil
+	}
+	return "", fmt.Errorf("action %q tidak dikenal (allow, external, block)", s)
+}
+
+// Request menjelaskan navigasi yang sedang dievaluasi
+type Request struct {
+	URL           string // URL tujuan
+	From          string // URL halaman saat ini (boleh kosong)
+	UserInitiated bool   // Navigasi dipicu langsung oleh user (klik, submit)
+	Redirect      bool   // Navigasi adalah hasil redirect server
+}
+
+type rule struct {
+	pattern *Pattern
+	action  Action
+}
+
+// Policy adalah aturan navigasi yang sudah di-compile dari AppConfig
+type Policy struct {
+	rules     []rule
+	whitelist []*Pattern
+	homeHost  string
+	strict    bool
+	blockExt  bool
+	popup     PopupAction
+	auth      []*Pattern
+}
+
+// New membuat Policy dari AppConfig.
+//
+// Urutan evaluasi: nav_rules (aturan pertama yang cocok menang),
+// auth_domains, whitelist, host URL awal, lalu default. Default-nya adalah
+// External, atau Block jika BlockExternalNav aktif.
+//
+// Jika tidak ada nav_rules, whitelist, maupun BlockExternalNav, policy
+// berjalan dalam mode longgar seperti perilaku lama: hanya navigasi yang
+// dipicu user ke host lain yang dibuka di browser, redirect dan navigasi dari
+// script tetap di dalam app.
+func New(cfg *config.AppConfig) (*Policy, error) {
+	p := &Policy{
+		blockExt: cfg.BlockExternalNav,
+		strict:   len(cfg.NavRules) > 0 || len(cfg.Whitelist) > 0 || cfg.BlockExternalNav,
+	}
+
+	if u, err := url.Parse(cfg.URL); err == nil {
+		p.homeHost = strings.ToLower(u.Hostname())
+	}
+
+	for i, r := range cfg.NavRules {
+		pattern, err := ParsePattern(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("nav_rules[%d]: %w", i, err)
+		}
+		action, err := ParseAction(r.Action)
+		if err != nil {
+			return nil, fmt.Errorf("nav_rules[%d]: %w", i, err)
+		}
+		p.rules = append(p.rules, rule{pattern: pattern, action: action})
+	}
+
+	for _, entry := range cfg.Whitelist {
+		pattern, err := ParsePattern(whitelistPattern(entry))
+		if err != nil {
+			return nil, fmt.Errorf("whitelist: %w", err)
+		}
+		p.whitelist = append(p.whitelist, pattern)
+	}
+
+	popup, err := ParsePopupAction(cfg.PopupPolicy)
+	if err != nil {
+		return nil, err
+	}
+	p.popup = popup
+	if p.auth, err = parseAuthDomains(cfg.AuthDomains); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// whitelistPattern mengubah domain polos di whitelist ("example.com") menjadi
+// pola yang juga mencakup subdomain (".example.com")
+func whitelistPattern(entry string) string {
+	entry = strings.TrimSpace(entry)
+	if strings.ContainsAny(entry, "*/:") || strings.HasPrefix(entry, ".") {
+		return entry
+	}
+	return "." + entry
+}
+
+// Decide mengembalikan Action untuk sebuah navigasi
+func (p *Policy) Decide(req Request) Action {
+	u, err := url.Parse(req.URL)
+	if err != nil {
+		return Block
+	}
+
+	switch strings.ToLower(u.Scheme) {
+	case "about", "data", "blob", "javascript":
+		// Navigasi internal browser selalu di dalam app
+		return Allow
+	case "http", "https":
+	default:
+		// mailto:, tel:, dan protocol lain diserahkan ke aplikasi sistem
+		// kecuali ada aturan eksplisit
+		for _, r := range p.rules {
+			if r.pattern.scheme == strings.ToLower(u.Scheme) && r.pattern.Match(u) {
+				return r.action
+			}
+		}
+		if p.blockExt {
+			return Block
+		}
+		return External
+	}
+
+	for _, r := range p.rules {
+		if r.pattern.Match(u) {
+			return r.action
+		}
+	}
+
+	for _, a := range p.auth {
+		if a.Match(u) {
+			return Allow
+		}
+	}
+
+	for _, w := range p.whitelist {
+		if w.Match(u) {
+			return Allow
+		}
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if host == p.homeHost {
+		return Allow
+	}
+
+	if !p.strict {
+		if !req.UserInitiated || req.Redirect {
+			return Allow
+		}
+		if from, err := url.Parse(req.From); err == nil && strings.EqualFold(from.Hostname(), host) {
+			return Allow
+		}
+	}
+
+	if p.blockExt {
+		return Block
+	}
+	return External
+}
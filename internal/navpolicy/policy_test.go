@@ -0,0 +1,130 @@
+package navpolicy
+
+import (
+	"testing"
+
+	"github.com/user/w2app/internal/config"
+)
+
+func TestNewError(t *testing.T) {
+	tests := []*config.AppConfig{
+		{URL: "https://example.com", NavRules: []config.NavRule{{Pattern: "", Action: "allow"}}},
+		{URL: "https://example.com", NavRules: []config.NavRule{{Pattern: "a.com", Action: "open"}}},
+		{URL: "https://example.com", Whitelist: []string{"https://"}},
+		{URL: "https://example.com", PopupPolicy: "tab"},
+		{URL: "https://example.com", AuthDomains: []string{"://x"}},
+	}
+	for i, cfg := range tests {
+		if _, err := New(cfg); err == nil {
+			t.Errorf("tests[%d]: error nil", i)
+		}
+	}
+}
+
+func TestDecide(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.AppConfig
+		req  Request
+		want Action
+	}{
+		// Mode longgar: tanpa nav_rules, whitelist, dan block_external_nav
+		{"home", config.AppConfig{}, Request{URL: "https://app.example.com/x", UserInitiated: true}, Allow},
+		{"home case", config.AppConfig{}, Request{URL: "https://APP.example.com/", UserInitiated: true}, Allow},
+		{"loose click", config.AppConfig{}, Request{URL: "https://other.com/", UserInitiated: true}, External},
+		{"loose script", config.AppConfig{}, Request{URL: "https://other.com/"}, Allow},
+		{"loose redirect", config.AppConfig{}, Request{URL: "https://other.com/", UserInitiated: true, Redirect: true}, Allow},
+		{"loose same host", config.AppConfig{}, Request{URL: "https://other.com/b", From: "https://other.com/a", UserInitiated: true}, Allow},
+
+		{"internal scheme", config.AppConfig{BlockExternalNav: true}, Request{URL: "about:blank"}, Allow},
+		{"data", config.AppConfig{BlockExternalNav: true}, Request{URL: "data:text/html,x"}, Allow},
+		{"mailto", config.AppConfig{}, Request{URL: "mailto:a@example.com", UserInitiated: true}, External},
+		{"mailto blocked", config.AppConfig{BlockExternalNav: true}, Request{URL: "mailto:a@example.com"}, Block},
+		{"mailto rule", config.AppConfig{
+			BlockExternalNav: true,
+			NavRules:         []config.NavRule{{Pattern: "mailto://*", Action: "external"}},
+		}, Request{URL: "mailto://a@example.com"}, External},
+		{"invalid url", config.AppConfig{}, Request{URL: "https://%zz"}, Block},
+
+		// Mode ketat
+		{"whitelist domain", config.AppConfig{Whitelist: []string{"docs.com"}}, Request{URL: "https://docs.com/a"}, Allow},
+		{"whitelist subdomain", config.AppConfig{Whitelist: []string{"docs.com"}}, Request{URL: "https://a.docs.com/"}, Allow},
+		{"whitelist other", config.AppConfig{Whitelist: []string{"docs.com"}}, Request{URL: "https://other.com/"}, External},
+		{"whitelist script", config.AppConfig{Whitelist: []string{"docs.com"}}, Request{URL: "https://other.com/"}, External},
+		{"whitelist path", config.AppConfig{Whitelist: []string{"docs.com/api/*"}}, Request{URL: "https://docs.com/web"}, External},
+		{"strict home", config.AppConfig{Whitelist: []string{"docs.com"}}, Request{URL: "https://app.example.com/"}, Allow},
+		{"block external", config.AppConfig{BlockExternalNav: true}, Request{URL: "https://other.com/", UserInitiated: true}, Block},
+		{"block external whitelist", config.AppConfig{
+			BlockExternalNav: true,
+			Whitelist:        []string{"docs.com"},
+		}, Request{URL: "https://docs.com/"}, Allow},
+
+		// nav_rules dievaluasi berurutan sebelum whitelist dan host awal
+		{"rule first wins", config.AppConfig{NavRules: []config.NavRule{
+			{Pattern: "example.com/admin", Action: "block"},
+			{Pattern: ".example.com", Action: "allow"},
+		}}, Request{URL: "https://example.com/admin/users"}, Block},
+		{"rule second", config.AppConfig{NavRules: []config.NavRule{
+			{Pattern: "example.com/admin", Action: "block"},
+			{Pattern: ".example.com", Action: "allow"},
+		}}, Request{URL: "https://example.com/home"}, Allow},
+		{"rule before whitelist", config.AppConfig{
+			Whitelist: []string{"docs.com"},
+			NavRules:  []config.NavRule{{Pattern: "docs.com/private", Action: "external"}},
+		}, Request{URL: "https://docs.com/private"}, External},
+		{"rule before home", config.AppConfig{
+			NavRules: []config.NavRule{{Pattern: "app.example.com/logout", Action: "block"}},
+		}, Request{URL: "https://app.example.com/logout"}, Block},
+		{"rule action case", config.AppConfig{
+			NavRules: []config.NavRule{{Pattern: "other.com", Action: " Allow "}},
+		}, Request{URL: "https://other.com/"}, Allow},
+
+		{"auth domain", config.AppConfig{
+			BlockExternalNav: true,
+			AuthDomains:      []string{"accounts.google.com"},
+		}, Request{URL: "https://accounts.google.com/o/oauth2"}, Allow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.URL = "https://app.example.com/"
+			p, err := New(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.Decide(tt.req); got != tt.want {
+				t.Errorf("Decide(%+v) = %q, want %q", tt.req, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPopup(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.AppConfig
+		url  string
+		want PopupAction
+	}{
+		{"default", config.AppConfig{}, "https://app.example.com/a", PopupSame},
+		{"window", config.AppConfig{PopupPolicy: "window"}, "https://app.example.com/a", PopupWindow},
+		{"browser", config.AppConfig{PopupPolicy: "browser"}, "https://app.example.com/a", PopupBrowser},
+		{"blank", config.AppConfig{PopupPolicy: "window"}, "about:blank", PopupBlock},
+		{"external", config.AppConfig{PopupPolicy: "window", Whitelist: []string{"docs.com"}}, "https://other.com/", PopupBrowser},
+		{"blocked", config.AppConfig{BlockExternalNav: true}, "https://other.com/", PopupBlock},
+		{"auth", config.AppConfig{PopupPolicy: "block", AuthDomains: []string{"login.example.org"}}, "https://login.example.org/", PopupWindow},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := tt.cfg
+			cfg.URL = "https://app.example.com/"
+			p, err := New(&cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := p.Popup(Request{URL: tt.url, UserInitiated: true}); got != tt.want {
+				t.Errorf("Popup(%q) = %q, want %q", tt.url, got, tt.want)
+			}
+		})
+	}
+}
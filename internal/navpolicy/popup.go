@@ -0,0 +1,97 @@
+package navpolicy
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// PopupAction adalah keputusan untuk URL yang dibuka halaman di window baru
+// (window.open, link target=_blank)
+type PopupAction string
+
+const (
+	PopupSame    PopupAction = "same"    // Buka di window utama (default, perilaku lama)
+	PopupWindow  PopupAction = "window"  // Buka di window kedua yang memakai profil yang sama
+	PopupBrowser PopupAction = "browser" // Buka di browser default
+	PopupBlock   PopupAction = "block"   // Jangan dibuka
+)
+
+// ParsePopupAction mengubah popup_policy dari config menjadi PopupAction.
+// String kosong berarti PopupSame.
+func ParsePopupAction(s string) (PopupAction, error) {
+	switch a := PopupAction(strings.ToLower(strings.TrimSpace(s))); a {
+	case "":
+		return PopupSame, nil
+	case PopupSame, PopupWindow, PopupBrowser, PopupBlock:
+		return a, nil
+	}
+	return "", fmt.Errorf("popup_policy %q tidak dikenal (same, window, browser, block)", s)
+}
+
+// parseAuthDomains meng-compile auth_domains. Formatnya sama dengan whitelist:
+// domain polos juga mencakup subdomain-nya.
+func parseAuthDomains(entries []string) ([]*Pattern, error) {
+	var patterns []*Pattern
+	for _, entry := range entries {
+		pattern, err := ParsePattern(whitelistPattern(entry))
+		if err != nil {
+			return nil, fmt.Errorf("auth_domains: %w", err)
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns, nil
+}
+
+// IsAuth mengecek apakah URL ada di salah satu auth_domains
+func (p *Policy) IsAuth(rawURL string) bool {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		return false
+	}
+	for _, a := range p.auth {
+		if a.Match(u) {
+			return true
+		}
+	}
+	return false
+}
+
+// Popup mengembalikan apa yang dilakukan untuk window baru ke req.URL.
+//
+// URL di auth_domains selalu dibuka di window kedua supaya login OAuth bisa
+// kembali ke app. Untuk PopupSame dan PopupWindow, kebijakan navigasi tetap
+// berlaku: URL yang External dibuka di browser dan yang Block diblokir.
+func (p *Policy) Popup(req Request) PopupAction {
+	if p.IsAuth(req.URL) {
+		return PopupWindow
+	}
+
+	// window.open() tanpa URL (about:blank) diisi lewat window.opener, yang
+	// tidak tersedia di window yang dibuka app
+	if u, err := url.Parse(req.URL); err != nil || isInternalScheme(u.Scheme) {
+		return PopupBlock
+	}
+
+	action := p.popup
+	if action == PopupBrowser || action == PopupBlock {
+		return action
+	}
+
+	switch p.Decide(req) {
+	case External:
+		return PopupBrowser
+	case Block:
+		return PopupBlock
+	}
+	return action
+}
+
+// isInternalScheme mengecek scheme halaman internal browser
+func isInternalScheme(scheme string) bool {
+	switch strings.ToLower(scheme) {
+	case "about", "data", "blob", "javascript":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,499 @@
+package netfilter
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+)
+
+// List adalah filter list bergaya Adblock (EasyList, uBlock Origin) yang
+// sudah di-parse.
+//
+// Yang didukung: aturan URL dengan anchor ||, | dan separator ^, wildcard *,
+// regex /.../, exception @@, dan opsi tipe resource (script, image,
+// stylesheet, xmlhttprequest, subdocument, document, media, font,
+// websocket, ping, other), third-party, domain=, match-case, dan important.
+// Aturan kosmetik (##, #@#, ...) dan aturan dengan opsi lain dilewati.
+//
+// WebView2 tidak membedakan halaman dan iframe, jadi request Document hanya
+// diblokir oleh aturan dengan opsi document atau subdocument.
+type List struct {
+	block     index
+	important index
+	allow     index
+	pages     []*filter // @@...$document: semua request dari halaman ini diizinkan
+
+	Rules   int // Jumlah aturan yang dipakai
+	Skipped int // Jumlah aturan yang tidak didukung
+}
+
+type filter struct {
+	pattern      string // huruf kecil kecuali matchCase
+	regex        *regexp.Regexp
+	anchorStart  bool // |
+	anchorDomain bool // ||
+	anchorEnd    bool // | di akhir
+	matchCase    bool
+	important    bool
+	types        map[string]bool // nil berarti semua tipe kecuali document
+	thirdParty   int             // 1: hanya third-party, -1: hanya first-party
+	domains      []string
+	notDomains   []string
+}
+
+// typeNames memetakan opsi tipe (termasuk alias uBlock Origin) ke Type
+var typeNames = map[string]string{
+	"document":          TypeDocument,
+	"doc":               TypeDocument,
+	"subdocument":       TypeSubdocument,
+	"frame":             TypeSubdocument,
+	"stylesheet":        TypeStylesheet,
+	"css":               TypeStylesheet,
+	"image":             TypeImage,
+	"media":             TypeMedia,
+	"font":              TypeFont,
+	"script":            TypeScript,
+	"xmlhttprequest":    TypeXMLHTTPRequest,
+	"xhr":               TypeXMLHTTPRequest,
+	"websocket":         TypeWebSocket,
+	"ping":              TypePing,
+	"other":             TypeOther,
+	"object":            TypeOther,
+	"object-subrequest": TypeOther,
+}
+
+// cosmeticRe mengenali aturan kosmetik dan scriptlet: ##, #@#, #?#, #$#, #%#
+var cosmeticRe = regexp.MustCompile(`#[@?$%]*#`)
+
+// ParseList mem-parse isi filter list. Baris yang tidak didukung dihitung di
+// Skipped, bukan error, karena filter list umum selalu berisi aturan
+// kosmetik.
+func ParseList(text string) *List {
+	l := &List{}
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == '!' || line[0] == '[' {
+			continue
+		}
+		if strings.Contains(line, "$$") || strings.Contains(line, "#") && cosmeticRe.MatchString(line) {
+			l.Skipped++
+			continue
+		}
+
+		exception := strings.HasPrefix(line, "@@")
+		f, err := parseFilter(strings.TrimPrefix(line, "@@"))
+		if err != nil {
+			l.Skipped++
+			continue
+		}
+		l.Rules++
+
+		switch {
+		case exception && f.types[TypeDocument]:
+			l.pages = append(l.pages, f)
+			l.allow.add(f)
+		case exception:
+			l.allow.add(f)
+		case f.important:
+			l.important.add(f)
+		default:
+			l.block.add(f)
+		}
+	}
+	return l
+}
+
+func parseFilter(line string) (*filter, error) {
+	f := &filter{}
+	pattern := line
+	regex := len(line) > 2 && line[0] == '/'
+	if i := strings.LastIndex(line, "$"); i >= 0 && (!regex || i > strings.LastIndex(line, "/")) {
+		pattern = line[:i]
+		if err := f.parseOptions(line[i+1:]); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(pattern) > 2 && pattern[0] == '/' && pattern[len(pattern)-1] == '/' {
+		expr := pattern[1 : len(pattern)-1]
+		if !f.matchCase {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, err
+		}
+		f.regex = re
+		return f, nil
+	}
+
+	if strings.HasPrefix(pattern, "||") {
+		f.anchorDomain = true
+		pattern = pattern[2:]
+	} else if strings.HasPrefix(pattern, "|") {
+		f.anchorStart = true
+		pattern = pattern[1:]
+	}
+	if strings.HasSuffix(pattern, "|") {
+		f.anchorEnd = true
+		pattern = pattern[:len(pattern)-1]
+	}
+	if !f.matchCase {
+		pattern = strings.ToLower(pattern)
+	}
+	f.pattern = pattern
+	return f, nil
+}
+
+func (f *filter) parseOptions(options string) error {
+	var include, exclude []string
+	for _, opt := range strings.Split(options, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		negate := strings.HasPrefix(name, "~")
+		name = strings.ToLower(strings.TrimPrefix(name, "~"))
+
+		switch name {
+		case "third-party", "3p":
+			f.thirdParty = 1
+			if negate {
+				f.thirdParty = -1
+			}
+		case "first-party", "1p":
+			f.thirdParty = -1
+			if negate {
+				f.thirdParty = 1
+			}
+		case "domain", "from":
+			for _, d := range strings.Split(strings.ToLower(value), "|") {
+				if strings.HasPrefix(d, "~") {
+					f.notDomains = append(f.notDomains, d[1:])
+				} else if d != "" {
+					f.domains = append(f.domains, d)
+				}
+			}
+		case "match-case":
+			f.matchCase = true
+		case "important":
+			f.important = true
+		default:
+			t, ok := typeNames[name]
+			if !ok {
+				return fmt.Errorf("opsi %q tidak didukung", name)
+			}
+			if negate {
+				exclude = append(exclude, t)
+			} else {
+				include = append(include, t)
+			}
+		}
+	}
+
+	switch {
+	case len(include) > 0:
+		f.types = map[string]bool{}
+		for _, t := range include {
+			f.types[t] = true
+		}
+	case len(exclude) > 0:
+		f.types = map[string]bool{}
+		for _, t := range typeNames {
+			if t != TypeDocument {
+				f.types[t] = true
+			}
+		}
+		for _, t := range exclude {
+			delete(f.types, t)
+		}
+	}
+	return nil
+}
+
+// Match melaporkan apakah req diblokir oleh filter list
+func (l *List) Match(req Request) bool {
+	if len(l.pages) > 0 && req.Page != "" {
+		page := newRequest(Request{URL: req.Page, Type: TypeDocument})
+		for _, f := range l.pages {
+			if f.match(page) {
+				return false
+			}
+		}
+	}
+	r := newRequest(req)
+	if l.important.find(r) != nil {
+		return true
+	}
+	return l.block.find(r) != nil && l.allow.find(r) == nil
+}
+
+// request adalah Request yang sudah disiapkan untuk dicocokkan dengan banyak
+// aturan
+type request struct {
+	url        string // huruf kecil
+	rawURL     string
+	typ        string
+	host       string
+	pageHost   string
+	thirdParty bool
+	tokens     []string
+}
+
+func newRequest(req Request) *request {
+	r := &request{
+		url:      strings.ToLower(req.URL),
+		rawURL:   req.URL,
+		typ:      req.Type,
+		host:     hostOf(req.URL),
+		pageHost: hostOf(req.Page),
+	}
+	if r.typ == "" {
+		r.typ = TypeOther
+	}
+	r.thirdParty = r.pageHost != "" && baseDomain(r.host) != baseDomain(r.pageHost)
+	seen := map[string]bool{}
+	for _, t := range tokenize(r.url) {
+		if !seen[t] {
+			seen[t] = true
+			r.tokens = append(r.tokens, t)
+		}
+	}
+	return r
+}
+
+func (f *filter) match(r *request) bool {
+	switch {
+	case r.typ == TypeDocument:
+		if !f.types[TypeDocument] && !f.types[TypeSubdocument] {
+			return false
+		}
+	case f.types != nil && !f.types[r.typ]:
+		return false
+	}
+	if f.thirdParty == 1 && !r.thirdParty || f.thirdParty == -1 && r.thirdParty {
+		return false
+	}
+	if len(f.domains) > 0 && !matchDomains(r.pageHost, f.domains) {
+		return false
+	}
+	if matchDomains(r.pageHost, f.notDomains) {
+		return false
+	}
+
+	url := r.url
+	if f.matchCase {
+		url = r.rawURL
+	}
+	if f.regex != nil {
+		return f.regex.MatchString(url)
+	}
+	switch {
+	case f.anchorStart:
+		return matchAt(f.pattern, url, f.anchorEnd)
+	case f.anchorDomain:
+		start := strings.Index(url, "://")
+		if start < 0 {
+			return false
+		}
+		start += 3
+		end := start + len(hostPart(url[start:]))
+		for i := start; i < end; i++ {
+			if (i == start || url[i-1] == '.') && matchAt(f.pattern, url[i:], f.anchorEnd) {
+				return true
+			}
+		}
+		return false
+	}
+	for i := 0; i <= len(url); i++ {
+		if matchAt(f.pattern, url[i:], f.anchorEnd) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchAt mencocokkan pola dengan awal s. "*" cocok dengan apa saja dan "^"
+// cocok dengan satu karakter separator atau akhir URL. Jika end, s harus
+// habis.
+func matchAt(pattern, s string, end bool) bool {
+	for len(pattern) > 0 {
+		switch c := pattern[0]; c {
+		case '*':
+			pattern = strings.TrimLeft(pattern, "*")
+			if pattern == "" {
+				return true
+			}
+			for i := 0; i <= len(s); i++ {
+				if (pattern[0] == '^' || i < len(s) && s[i] == pattern[0]) && matchAt(pattern, s[i:], end) {
+					return true
+				}
+			}
+			return false
+		case '^':
+			if s == "" {
+				pattern = pattern[1:]
+				continue
+			}
+			if !isSeparator(s[0]) {
+				return false
+			}
+		default:
+			if s == "" || s[0] != c {
+				return false
+			}
+		}
+		pattern, s = pattern[1:], s[1:]
+	}
+	return !end || s == ""
+}
+
+// isSeparator adalah karakter yang cocok dengan "^": semua kecuali huruf,
+// angka, dan _ - . %
+func isSeparator(c byte) bool {
+	return !isTokenChar(c) && c != '_' && c != '-' && c != '.' && c != '%'
+}
+
+func isTokenChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
+// index mengelompokkan aturan berdasarkan token, potongan huruf dan angka
+// yang pasti muncul utuh di URL yang cocok. Satu request hanya dicek dengan
+// aturan yang tokennya ada di URL, ditambah aturan tanpa token.
+type index struct {
+	tokens  map[string][]*filter
+	generic []*filter
+}
+
+func (x *index) add(f *filter) {
+	token := f.token()
+	if token == "" {
+		x.generic = append(x.generic, f)
+		return
+	}
+	if x.tokens == nil {
+		x.tokens = map[string][]*filter{}
+	}
+	x.tokens[token] = append(x.tokens[token], f)
+}
+
+func (x *index) find(r *request) *filter {
+	for _, t := range r.tokens {
+		for _, f := range x.tokens[t] {
+			if f.match(r) {
+				return f
+			}
+		}
+	}
+	for _, f := range x.generic {
+		if f.match(r) {
+			return f
+		}
+	}
+	return nil
+}
+
+// token memilih token terpanjang di pola yang pasti dibatasi separator atau
+// ujung URL, bukan wildcard
+func (f *filter) token() string {
+	if f.regex != nil {
+		return ""
+	}
+	p := f.pattern
+	best := ""
+	for i := 0; i < len(p); {
+		if !isTokenChar(p[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(p) && isTokenChar(p[j]) {
+			j++
+		}
+		startOK := i > 0 && p[i-1] != '*' || i == 0 && (f.anchorStart || f.anchorDomain)
+		endOK := j < len(p) && p[j] != '*' || j == len(p) && f.anchorEnd
+		if startOK && endOK && j-i >= 2 && j-i > len(best) {
+			best = p[i:j]
+		}
+		i = j
+	}
+	return strings.ToLower(best)
+}
+
+func tokenize(s string) []string {
+	var tokens []string
+	for i := 0; i < len(s); {
+		if !isTokenChar(s[i]) {
+			i++
+			continue
+		}
+		j := i
+		for j < len(s) && isTokenChar(s[j]) {
+			j++
+		}
+		if j-i >= 2 {
+			tokens = append(tokens, s[i:j])
+		}
+		i = j
+	}
+	return tokens
+}
+
+// hostOf mengembalikan host URL dalam huruf kecil, tanpa port
+func hostOf(rawURL string) string {
+	i := strings.Index(rawURL, "://")
+	if i < 0 {
+		return ""
+	}
+	host := strings.ToLower(hostPart(rawURL[i+3:]))
+	if at := strings.LastIndex(host, "@"); at >= 0 {
+		host = host[at+1:]
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.Trim(host, "[]")
+}
+
+// hostPart memotong s di awal path, query, atau fragment
+func hostPart(s string) string {
+	if i := strings.IndexAny(s, "/?#"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
+
+// secondLevel adalah label kedua yang umum di domain negara (co.id, co.uk,
+// com.au, ...). Tanpa daftar public suffix, domain dasar cukup ditebak.
+var secondLevel = map[string]bool{
+	"ac": true, "co": true, "com": true, "edu": true, "go": true, "gov": true,
+	"net": true, "or": true, "org": true, "sch": true, "web": true, "my": true,
+}
+
+// baseDomain menebak domain yang didaftarkan dari host, untuk opsi
+// third-party: example.com untuk a.b.example.com, example.co.id untuk
+// www.example.co.id
+func baseDomain(host string) string {
+	if net.ParseIP(host) != nil {
+		return host
+	}
+	labels := strings.Split(host, ".")
+	n := 2
+	if len(labels) > 2 && len(labels[len(labels)-1]) == 2 && secondLevel[labels[len(labels)-2]] {
+		n = 3
+	}
+	if len(labels) <= n {
+		return host
+	}
+	return strings.Join(labels[len(labels)-n:], ".")
+}
+
+// matchDomains melaporkan apakah host sama dengan atau subdomain dari salah
+// satu domain
+func matchDomains(host string, domains []string) bool {
+	for _, d := range domains {
+		if host == d || strings.HasSuffix(host, "."+d) {
+			return true
+		}
+	}
+	return false
+}
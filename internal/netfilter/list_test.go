@@ -0,0 +1,307 @@
+package netfilter
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseList(t *testing.T) {
+	text := `[Adblock Plus 2.0]
+! Title: test list
+! komentar
+
+||ads.example.com^
+@@||ads.example.com/ok^
+/banner\d+$/
+/ads\d+\.js$/$script
+|https://tracker.
+swf|
+||cdn.net^$third-party,image
+
+example.com##.ad
+##.banner
+example.com#@#.ad
+example.com#?#div:has(.ad)
+example.com#$#abort-on-property-read x
+example.com#%#//scriptlet('x')
+example.com$$script[src*="ad"]
+||popup.com^$popup
+||rewrite.com^$redirect=noop.js
+/([/
+`
+	l := ParseList(text)
+	if l.Rules != 7 {
+		t.Errorf("Rules = %d, want 7", l.Rules)
+	}
+	if l.Skipped != 10 {
+		t.Errorf("Skipped = %d, want 10", l.Skipped)
+	}
+
+	// CRLF dan spasi di awal baris
+	l = ParseList("  ||a.com^  \r\n\r\n||b.com^\r\n")
+	if l.Rules != 2 || l.Skipped != 0 {
+		t.Errorf("CRLF: Rules = %d, Skipped = %d", l.Rules, l.Skipped)
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	tests := []struct {
+		line  string
+		check func(*filter) bool
+	}{
+		{"||ads.com^", func(f *filter) bool { return f.anchorDomain && f.pattern == "ads.com^" && f.types == nil }},
+		{"|https://x.com/", func(f *filter) bool { return f.anchorStart && !f.anchorDomain && f.pattern == "https://x.com/" }},
+		{".swf|", func(f *filter) bool { return f.anchorEnd && f.pattern == ".swf" }},
+		{"/Ads/", func(f *filter) bool { return f.regex != nil && f.regex.String() == "(?i)Ads" }},
+		{"/Ads/$match-case", func(f *filter) bool { return f.regex != nil && f.regex.String() == "Ads" }},
+		{"/Banner/Img$match-case", func(f *filter) bool { return f.regex == nil && f.matchCase && f.pattern == "/Banner/Img" }},
+		{"/Banner/Img", func(f *filter) bool { return f.pattern == "/banner/img" }},
+		// $ di dalam regex bukan pemisah opsi
+		{`/track\.js$/`, func(f *filter) bool { return f.regex != nil && f.regex.String() == `(?i)track\.js$` }},
+		{`/a|b$/$image`, func(f *filter) bool { return f.regex != nil && f.regex.String() == `(?i)a|b$` && f.types[TypeImage] }},
+		{"||a.com^$script,image", func(f *filter) bool {
+			return len(f.types) == 2 && f.types[TypeScript] && f.types[TypeImage]
+		}},
+		{"||a.com^$xhr,css,frame,doc", func(f *filter) bool {
+			return f.types[TypeXMLHTTPRequest] && f.types[TypeStylesheet] && f.types[TypeSubdocument] && f.types[TypeDocument]
+		}},
+		{"||a.com^$~script", func(f *filter) bool {
+			return !f.types[TypeScript] && f.types[TypeImage] && f.types[TypeOther] && !f.types[TypeDocument]
+		}},
+		{"||a.com^$third-party", func(f *filter) bool { return f.thirdParty == 1 }},
+		{"||a.com^$3p", func(f *filter) bool { return f.thirdParty == 1 }},
+		{"||a.com^$~third-party", func(f *filter) bool { return f.thirdParty == -1 }},
+		{"||a.com^$1p", func(f *filter) bool { return f.thirdParty == -1 }},
+		{"||a.com^$important", func(f *filter) bool { return f.important }},
+		{"||a.com^$domain=X.com|~b.x.com|y.org", func(f *filter) bool {
+			return slices.Equal(f.domains, []string{"x.com", "y.org"}) && slices.Equal(f.notDomains, []string{"b.x.com"})
+		}},
+		{"||a.com^$Script, Third-Party", func(f *filter) bool { return f.types[TypeScript] && f.thirdParty == 1 }},
+	}
+	for _, tt := range tests {
+		f, err := parseFilter(tt.line)
+		if err != nil {
+			t.Errorf("parseFilter(%q): %v", tt.line, err)
+			continue
+		}
+		if !tt.check(f) {
+			t.Errorf("parseFilter(%q) = %+v", tt.line, f)
+		}
+	}
+
+	for _, line := range []string{"||a.com^$popup", "||a.com^$csp=script-src", "/([/", "/a/$redirect=x"} {
+		if _, err := parseFilter(line); err == nil {
+			t.Errorf("parseFilter(%q): error nil", line)
+		}
+	}
+}
+
+func TestListMatch(t *testing.T) {
+	tests := []struct {
+		name string
+		list string
+		req  Request
+		want bool
+	}{
+		// ||: awal host atau subdomain, bukan di tengah label atau di path
+		{"domain", "||ads.example.com^", Request{URL: "https://ads.example.com/x.js"}, true},
+		{"domain subdomain", "||ads.example.com^", Request{URL: "https://cdn.ads.example.com/"}, true},
+		{"domain scheme", "||ads.example.com^", Request{URL: "wss://ads.example.com/socket"}, true},
+		{"domain case", "||ads.example.com^", Request{URL: "HTTPS://ADS.Example.COM/"}, true},
+		{"domain label", "||ads.example.com^", Request{URL: "https://badads.example.com/"}, false},
+		{"domain in path", "||ads.example.com^", Request{URL: "https://example.org/?u=ads.example.com"}, false},
+		{"domain suffix", "||ads.example.com^", Request{URL: "https://ads.example.com.evil.org/"}, false},
+		{"domain port", "||ads.example.com^", Request{URL: "https://ads.example.com:8443/"}, true},
+		{"domain end", "||ads.example.com^", Request{URL: "https://ads.example.com"}, true},
+		{"domain path", "||example.com/ads/", Request{URL: "https://www.example.com/ads/1.png"}, true},
+
+		// | di awal dan akhir
+		{"start", "|https://tracker.", Request{URL: "https://tracker.net/p"}, true},
+		{"start middle", "|https://tracker.", Request{URL: "http://x.com/?r=https://tracker.net"}, false},
+		{"end", ".swf|", Request{URL: "https://x.com/movie.swf"}, true},
+		{"end query", ".swf|", Request{URL: "https://x.com/movie.swf?v=1"}, false},
+		{"exact", "|https://x.com/a|", Request{URL: "https://x.com/a"}, true},
+		{"exact longer", "|https://x.com/a|", Request{URL: "https://x.com/ab"}, false},
+
+		// ^ dan *
+		{"separator", "/ads^", Request{URL: "https://x.com/ads?x=1"}, true},
+		{"separator slash", "/ads^", Request{URL: "https://x.com/ads/1"}, true},
+		{"separator not char", "/ads^", Request{URL: "https://x.com/ads-1"}, false},
+		{"separator dot", "/ads^", Request{URL: "https://x.com/ads.js"}, false},
+		{"wildcard", "/banner/*/img^", Request{URL: "https://x.com/banner/300x250/img?x"}, true},
+		{"wildcard too short", "/banner/*/img^", Request{URL: "https://x.com/banner/img"}, false},
+		{"wildcard separator", "/track*^id=", Request{URL: "https://x.com/track/pixel?id=1"}, true},
+		{"substring", "adserver", Request{URL: "https://x.com/js/adserver.js"}, true},
+
+		// Regex
+		{"regex", `/\/ads\d+\.js$/`, Request{URL: "https://x.com/ads123.js"}, true},
+		{"regex end", `/\/ads\d+\.js$/`, Request{URL: "https://x.com/ads123.js?v=2"}, false},
+		{"regex case", `/\/ADS\d+/`, Request{URL: "https://x.com/ads1"}, true},
+		{"regex type", `/\/ads\d+/$script`, Request{URL: "https://x.com/ads1", Type: TypeImage}, false},
+
+		// match-case
+		{"match-case", "/Ads/$match-case", Request{URL: "https://x.com/Ads/1"}, true},
+		{"match-case other", "/Ads/$match-case", Request{URL: "https://x.com/ads/1"}, false},
+		{"case-insensitive", "/Ads/", Request{URL: "https://x.com/aDs/1"}, true},
+
+		// @@ dan $important
+		{"exception", "||ads.com^\n@@||ads.com/allowed^", Request{URL: "https://ads.com/allowed/x"}, false},
+		{"exception other", "||ads.com^\n@@||ads.com/allowed^", Request{URL: "https://ads.com/other"}, true},
+		{"exception type", "||ads.com^\n@@||ads.com^$image", Request{URL: "https://ads.com/a", Type: TypeScript}, true},
+		{"important", "||track.com^$important\n@@||track.com^", Request{URL: "https://track.com/p"}, true},
+		{"important type", "||track.com^$important,image\n@@||track.com^", Request{URL: "https://track.com/p", Type: TypeScript}, false},
+
+		// third-party
+		{"third-party", "||cdn.net^$third-party", Request{URL: "https://cdn.net/a.js", Page: "https://news.com/"}, true},
+		{"third-party first", "||cdn.net^$third-party", Request{URL: "https://cdn.net/a.js", Page: "https://www.cdn.net/"}, false},
+		{"third-party no page", "||cdn.net^$third-party", Request{URL: "https://cdn.net/a.js"}, false},
+		{"third-party cctld", "||static.example.co.id^$third-party", Request{URL: "https://static.example.co.id/", Page: "https://www.example.co.id/"}, false},
+		{"third-party cctld other", "||static.example.co.id^$third-party", Request{URL: "https://static.example.co.id/", Page: "https://other.co.id/"}, true},
+		{"first-party", "/ads/$~third-party", Request{URL: "https://a.news.com/ads/1", Page: "https://news.com/"}, true},
+		{"first-party other", "/ads/$~third-party", Request{URL: "https://cdn.net/ads/1", Page: "https://news.com/"}, false},
+
+		// domain=
+		{"domain=", "||widget.com^$domain=news.com|~sports.news.com", Request{URL: "https://widget.com/w.js", Page: "https://news.com/"}, true},
+		{"domain= subdomain", "||widget.com^$domain=news.com|~sports.news.com", Request{URL: "https://widget.com/w.js", Page: "https://a.news.com/"}, true},
+		{"domain= excluded", "||widget.com^$domain=news.com|~sports.news.com", Request{URL: "https://widget.com/w.js", Page: "https://live.sports.news.com/"}, false},
+		{"domain= other", "||widget.com^$domain=news.com|~sports.news.com", Request{URL: "https://widget.com/w.js", Page: "https://other.com/"}, false},
+		{"domain= no page", "||widget.com^$domain=news.com", Request{URL: "https://widget.com/w.js"}, false},
+		{"~domain only", "/ads.js$domain=~safe.com", Request{URL: "https://x.com/ads.js", Page: "https://other.com/"}, true},
+		{"~domain only excluded", "/ads.js$domain=~safe.com", Request{URL: "https://x.com/ads.js", Page: "https://safe.com/"}, false},
+
+		// Tipe resource
+		{"type", "||img.com^$image", Request{URL: "https://img.com/a.png", Type: TypeImage}, true},
+		{"type other", "||img.com^$image", Request{URL: "https://img.com/a.js", Type: TypeScript}, false},
+		{"type empty is other", "||img.com^$other", Request{URL: "https://img.com/a"}, true},
+		{"~type", "||s.com^$~script", Request{URL: "https://s.com/a.png", Type: TypeImage}, true},
+		{"~type excluded", "||s.com^$~script", Request{URL: "https://s.com/a.js", Type: TypeScript}, false},
+
+		// Halaman hanya diblokir oleh aturan document/subdocument
+		{"document", "||ads.com^", Request{URL: "https://ads.com/", Type: TypeDocument}, false},
+		{"document option", "||bad.com^$document", Request{URL: "https://bad.com/", Type: TypeDocument}, true},
+		{"subdocument option", "||frame.com^$subdocument", Request{URL: "https://frame.com/", Type: TypeDocument}, true},
+		// Dokumen juga mencakup iframe, jadi ~script ikut memblokir subdocument
+		{"~type document", "||s.com^$~script", Request{URL: "https://s.com/", Type: TypeDocument}, true},
+		{"~subdocument document", "||s.com^$~subdocument", Request{URL: "https://s.com/", Type: TypeDocument}, false},
+
+		// @@...$document mengizinkan semua request dari halaman tersebut
+		{"page exception", "||ads.com^\n@@||trusted.com^$document", Request{URL: "https://ads.com/a.js", Page: "https://trusted.com/p"}, false},
+		{"page exception other", "||ads.com^\n@@||trusted.com^$document", Request{URL: "https://ads.com/a.js", Page: "https://news.com/"}, true},
+		{"page exception important", "||ads.com^$important\n@@||trusted.com^$document", Request{URL: "https://ads.com/a.js", Page: "https://trusted.com/"}, false},
+		{"page exception self", "||trusted.com^$document\n@@||trusted.com^$document", Request{URL: "https://trusted.com/", Type: TypeDocument}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := ParseList(tt.list)
+			if l.Skipped != 0 {
+				t.Fatalf("%d aturan dilewati", l.Skipped)
+			}
+			if got := l.Match(tt.req); got != tt.want {
+				t.Errorf("Match(%+v) = %v, want %v", tt.req, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterToken(t *testing.T) {
+	tests := []struct {
+		line string
+		want string
+	}{
+		{"||ads.example.com^", "example"},
+		{"||ads^", "ads"},
+		{"*/ads/*", "ads"},
+		{"banner*", ""},
+		{"banner", ""},
+		{"|https://x", "https"},
+		{".swf|", "swf"},
+		{"/adserver*.js", ""},
+		{"/adserver/*.js", "adserver"},
+		{"/a/b/", ""},
+		{"/Banner/x$match-case", "banner"},
+		// /.../ adalah regex, tidak diindeks
+		{"/ads/", ""},
+		{`/\/ads\//`, ""},
+	}
+	for _, tt := range tests {
+		f, err := parseFilter(tt.line)
+		if err != nil {
+			t.Fatalf("parseFilter(%q): %v", tt.line, err)
+		}
+		if got := f.token(); got != tt.want {
+			t.Errorf("token(%q) = %q, want %q", tt.line, got, tt.want)
+		}
+	}
+}
+
+func TestIndex(t *testing.T) {
+	l := ParseList("||ads.example.com^\n/banner/img\nbanner*\n/\\/track\\d/")
+	if n := len(l.block.tokens["example"]); n != 1 {
+		t.Errorf("aturan dengan token example = %d, want 1", n)
+	}
+	if n := len(l.block.tokens["banner"]); n != 1 {
+		t.Errorf("aturan dengan token banner = %d, want 1", n)
+	}
+	if n := len(l.block.generic); n != 2 {
+		t.Errorf("aturan tanpa token = %d, want 2", n)
+	}
+
+	// Aturan berindeks dan tanpa token sama-sama ditemukan
+	for url, want := range map[string]bool{
+		"https://ads.example.com/a": true,
+		"https://x.com/banner/img":  true,
+		"https://x.com/bannerad":    true,
+		"https://x.com/track9":      true,
+		"https://x.com/tracking":    false,
+		"https://example.com/ads":   false,
+	} {
+		if got := l.Match(Request{URL: url}); got != want {
+			t.Errorf("Match(%s) = %v, want %v", url, got, want)
+		}
+	}
+}
+
+func TestBaseDomain(t *testing.T) {
+	for host, want := range map[string]string{
+		"example.com":           "example.com",
+		"a.b.example.com":       "example.com",
+		"www.example.co.id":     "example.co.id",
+		"example.co.uk":         "example.co.uk",
+		"a.example.com.au":      "example.com.au",
+		"cdn.example.io":        "example.io",
+		"localhost":             "localhost",
+		"192.168.1.10":          "192.168.1.10",
+		"::1":                   "::1",
+		"sub.example.web.id":    "example.web.id",
+		"static.example.go.id":  "example.go.id",
+		"x.example.de":          "example.de",
+		"deep.a.b.example.org":  "example.org",
+		"www.tokopedia.co.id":   "tokopedia.co.id",
+		"api.service.gov.uk":    "service.gov.uk",
+		"news.example.or.id":    "example.or.id",
+		"example.ac.id":         "example.ac.id",
+		"www.something.net.id":  "something.net.id",
+		"host.example.my.id":    "example.my.id",
+		"school.example.sch.id": "example.sch.id",
+	} {
+		if got := baseDomain(host); got != want {
+			t.Errorf("baseDomain(%s) = %s, want %s", host, got, want)
+		}
+	}
+}
+
+func TestHostOf(t *testing.T) {
+	for url, want := range map[string]string{
+		"https://Example.com/a":        "example.com",
+		"https://user:pw@example.com/": "example.com",
+		"https://example.com:8080":     "example.com",
+		"http://[::1]:3000/x":          "::1",
+		"https://example.com?q=a/b":    "example.com",
+		"about:blank":                  "",
+		"":                             "",
+	} {
+		if got := hostOf(url); got != want {
+			t.Errorf("hostOf(%q) = %q, want %q", url, got, want)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+// Package netfilter memeriksa request yang dibuat halaman: menambah header
+// untuk URL tertentu, dan memblokir URL dari daftar blokir maupun filter
+// list bergaya Adblock. Package ini tidak bergantung pada WebView2.
+package netfilter
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/user/w2app/internal/config"
+	"github.com/user/w2app/internal/navpolicy"
+)
+
+// Jenis resource sebuah request, sama dengan opsi tipe di filter list
+const (
+	TypeDocument       = "document"
+	TypeSubdocument    = "subdocument"
+	TypeStylesheet     = "stylesheet"
+	TypeImage          = "image"
+	TypeMedia          = "media"
+	TypeFont           = "font"
+	TypeScript         = "script"
+	TypeXMLHTTPRequest = "xmlhttprequest"
+	TypeWebSocket      = "websocket"
+	TypePing           = "ping"
+	TypeOther          = "other"
+)
+
+// Request adalah satu request dari halaman
+type Request struct {
+	URL  string // URL yang diminta
+	Page string // URL halaman yang membuat request, boleh kosong
+	Type string // Jenis resource, kosong berarti TypeOther
+}
+
+// Header adalah satu header yang ditambahkan ke request
+type Header struct {
+	Name  string
+	Value string
+}
+
+type headerRule struct {
+	pattern *navpolicy.Pattern
+	headers []Header
+}
+
+// Filter adalah config network yang sudah di-compile
+type Filter struct {
+	headers []headerRule
+	block   []*navpolicy.Pattern
+	list    *List
+}
+
+// headerNameRe adalah token nama header HTTP (RFC 9110)
+var headerNameRe = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// New membuat Filter dari config network. Config nil menghasilkan Filter
+// yang tidak melakukan apa-apa.
+func New(cfg *config.Network) (*Filter, error) {
+	f := &Filter{}
+	if cfg == nil {
+		return f, nil
+	}
+
+	for i, h := range cfg.Headers {
+		pattern, err := navpolicy.ParsePattern(h.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("network.headers[%d]: %w", i, err)
+		}
+		if len(h.Headers) == 0 {
+			return nil, fmt.Errorf("network.headers[%d]: headers kosong", i)
+		}
+		rule := headerRule{pattern: pattern}
+		for name, value := range h.Headers {
+			if !headerNameRe.MatchString(name) {
+				return nil, fmt.Errorf("network.headers[%d]: nama header %q tidak valid", i, name)
+			}
+			if strings.ContainsAny(value, "\r\n") {
+				return nil, fmt.Errorf("network.headers[%d]: nilai header %s tidak boleh berisi baris baru", i, name)
+			}
+			rule.headers = append(rule.headers, Header{Name: name, Value: value})
+		}
+		sort.Slice(rule.headers, func(a, b int) bool { return rule.headers[a].Name < rule.headers[b].Name })
+		f.headers = append(f.headers, rule)
+	}
+
+	for i, b := range cfg.Block {
+		pattern, err := navpolicy.ParsePattern(b)
+		if err != nil {
+			return nil, fmt.Errorf("network.block[%d]: %w", i, err)
+		}
+		f.block = append(f.block, pattern)
+	}
+
+	if strings.TrimSpace(cfg.FilterList) != "" {
+		f.list = ParseList(cfg.FilterList)
+		if f.list.Rules == 0 {
+			return nil, fmt.Errorf("network.filter_list: tidak ada aturan yang didukung (%d dilewati)", f.list.Skipped)
+		}
+	}
+	return f, nil
+}
+
+// Active melaporkan apakah ada aturan, supaya request tidak perlu diperiksa
+// sama sekali jika tidak ada
+func (f *Filter) Active() bool {
+	return f != nil && (len(f.headers) > 0 || len(f.block) > 0 || f.list != nil)
+}
+
+// Block melaporkan apakah req diblokir. Daftar blokir berlaku untuk semua
+// jenis request, termasuk halaman; filter list mengikuti aturan List.
+func (f *Filter) Block(req Request) bool {
+	if len(f.block) > 0 {
+		if u, err := url.Parse(req.URL); err == nil {
+			for _, p := range f.block {
+				if p.Match(u) {
+					return true
+				}
+			}
+		}
+	}
+	return f.list != nil && f.list.Match(req)
+}
+
+// Headers mengembalikan header tambahan untuk rawURL. Semua aturan yang
+// cocok dipakai berurutan, jadi header yang sama dari aturan berikutnya
+// menimpa yang sebelumnya.
+func (f *Filter) Headers(rawURL string) []Header {
+	if len(f.headers) == 0 {
+		return nil
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil
+	}
+	var headers []Header
+	for _, r := range f.headers {
+		if r.pattern.Match(u) {
+			headers = append(headers, r.headers...)
+		}
+	}
+	return headers
+}
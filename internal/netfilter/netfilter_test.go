@@ -0,0 +1,122 @@
+package netfilter
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/user/w2app/internal/config"
+)
+
+func TestNewError(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  config.Network
+		want string
+	}{
+		{"pattern", config.Network{Headers: []config.HeaderRule{{Pattern: "", Headers: map[string]string{"X-A": "1"}}}}, "network.headers[0]"},
+		{"headers kosong", config.Network{Headers: []config.HeaderRule{{Pattern: "https://a.com/*"}}}, "headers kosong"},
+		{"nama header", config.Network{Headers: []config.HeaderRule{{Pattern: "https://a.com/*", Headers: map[string]string{"X A": "1"}}}}, "tidak valid"},
+		{"baris baru", config.Network{Headers: []config.HeaderRule{{Pattern: "https://a.com/*", Headers: map[string]string{"X-A": "1\r\nX-B: 2"}}}}, "baris baru"},
+		{"block", config.Network{Block: []string{"https://a.com/*", ""}}, "network.block[1]"},
+		{"filter list", config.Network{FilterList: "##.ad\n! komentar"}, "network.filter_list"},
+	}
+	for _, tt := range tests {
+		if _, err := New(&tt.cfg); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestActive(t *testing.T) {
+	var nilFilter *Filter
+	if nilFilter.Active() {
+		t.Error("Filter nil aktif")
+	}
+	for _, cfg := range []*config.Network{nil, {}, {FilterList: "  \n"}} {
+		f, err := New(cfg)
+		if err != nil {
+			t.Fatalf("New(%+v): %v", cfg, err)
+		}
+		if f.Active() {
+			t.Errorf("New(%+v) aktif", cfg)
+		}
+	}
+	f, err := New(&config.Network{Block: []string{"*://ads.com/*"}})
+	if err != nil || !f.Active() {
+		t.Errorf("Filter dengan block: %v, aktif = %v", err, f.Active())
+	}
+}
+
+func TestFilterBlock(t *testing.T) {
+	f, err := New(&config.Network{
+		Block:      []string{"*://*.tracker.com/*", "https://example.com/ads/*"},
+		FilterList: "||adserver.net^\n@@||adserver.net/ok^",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		req  Request
+		want bool
+	}{
+		{Request{URL: "https://cdn.tracker.com/p.gif", Type: TypeImage}, true},
+		{Request{URL: "http://a.b.tracker.com/p.gif"}, true},
+		{Request{URL: "https://tracker.net/p.gif"}, false},
+		// Daftar blokir juga berlaku untuk halaman
+		{Request{URL: "https://www.tracker.com/", Type: TypeDocument}, true},
+		{Request{URL: "https://example.com/ads/1.js", Type: TypeScript}, true},
+		{Request{URL: "http://example.com/ads/1.js"}, false},
+		{Request{URL: "https://example.com/app.js"}, false},
+		// Filter list
+		{Request{URL: "https://adserver.net/a.js", Type: TypeScript}, true},
+		{Request{URL: "https://adserver.net/ok/a.js", Type: TypeScript}, false},
+		{Request{URL: "https://adserver.net/", Type: TypeDocument}, false},
+		{Request{URL: "://rusak"}, false},
+	}
+	for _, tt := range tests {
+		if got := f.Block(tt.req); got != tt.want {
+			t.Errorf("Block(%+v) = %v, want %v", tt.req, got, tt.want)
+		}
+	}
+}
+
+func TestFilterHeaders(t *testing.T) {
+	f, err := New(&config.Network{Headers: []config.HeaderRule{
+		{Pattern: "https://api.example.com/*", Headers: map[string]string{"X-Client": "w2app", "Authorization": "Bearer a"}},
+		{Pattern: "https://*.example.com/*", Headers: map[string]string{"X-Trace": "1"}},
+		{Pattern: "https://api.example.com/v2/*", Headers: map[string]string{"Authorization": "Bearer b"}},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		url  string
+		want []Header
+	}{
+		{"https://api.example.com/v1/me", []Header{
+			{"Authorization", "Bearer a"}, {"X-Client", "w2app"},
+			{"X-Trace", "1"},
+		}},
+		// Header dari aturan berikutnya ditambahkan setelahnya, jadi menimpa
+		// nilai sebelumnya saat dipasang berurutan
+		{"https://api.example.com/v2/me", []Header{
+			{"Authorization", "Bearer a"}, {"X-Client", "w2app"},
+			{"X-Trace", "1"},
+			{"Authorization", "Bearer b"},
+		}},
+		{"https://www.example.com/", []Header{{"X-Trace", "1"}}},
+		{"https://other.com/", nil},
+		{"://rusak", nil},
+	}
+	for _, tt := range tests {
+		if got := f.Headers(tt.url); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Headers(%s) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+
+	empty, _ := New(nil)
+	if got := empty.Headers("https://api.example.com/"); got != nil {
+		t.Errorf("Headers tanpa aturan = %v", got)
+	}
+}
@@ -0,0 +1,72 @@
+package permission
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// answersFile menyimpan jawaban prompt di direktori data profil. Hapus file
+// ini untuk bertanya lagi.
+const answersFile = "permissions.json"
+
+// Answers adalah jawaban user untuk izin bernilai prompt, per origin lalu
+// per jenis izin
+type Answers struct {
+	mu      sync.Mutex
+	path    string
+	answers map[string]map[string]bool
+}
+
+// LoadAnswers membaca jawaban yang tersimpan di direktori data dir. File
+// yang rusak diperlakukan seperti belum ada jawaban.
+func LoadAnswers(dir string) (*Answers, error) {
+	a := &Answers{path: filepath.Join(dir, answersFile), answers: map[string]map[string]bool{}}
+	data, err := os.ReadFile(a.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return a, nil
+	}
+	if err != nil {
+		return a, err
+	}
+	if err := json.Unmarshal(data, &a.answers); err != nil || a.answers == nil {
+		a.answers = map[string]map[string]bool{}
+		return a, err
+	}
+	return a, nil
+}
+
+// Get mengembalikan jawaban untuk izin kind dari origin, dan apakah sudah
+// pernah dijawab
+func (a *Answers) Get(origin, kind string) (allowed, ok bool) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	allowed, ok = a.answers[origin][kind]
+	return allowed, ok
+}
+
+// Set mengingat jawaban untuk izin kind dari origin dan menyimpannya
+func (a *Answers) Set(origin, kind string, allowed bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.answers[origin] == nil {
+		a.answers[origin] = map[string]bool{}
+	}
+	a.answers[origin][kind] = allowed
+
+	data, err := json.MarshalIndent(a.answers, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := a.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, a.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
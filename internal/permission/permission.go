@@ -0,0 +1,133 @@
+// Package permission memutuskan izin yang diminta halaman (kamera, mikrofon,
+// lokasi, ...) dari map permissions di AppConfig, dan mengingat jawaban user
+// untuk izin yang bernilai prompt.
+package permission
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/user/w2app/internal/navpolicy"
+)
+
+// Jenis izin yang bisa diatur
+const (
+	Camera            = "camera"
+	Microphone        = "microphone"
+	Geolocation       = "geolocation"
+	Clipboard         = "clipboard"
+	Notifications     = "notifications"
+	MultipleDownloads = "multiple_downloads"
+)
+
+// Kinds adalah semua jenis izin yang bisa diatur
+var Kinds = []string{Camera, Microphone, Geolocation, Clipboard, Notifications, MultipleDownloads}
+
+// Action adalah keputusan untuk satu permintaan izin
+type Action string
+
+const (
+	Allow  Action = "allow"  // Izinkan tanpa bertanya
+	Deny   Action = "deny"   // Tolak tanpa bertanya
+	Prompt Action = "prompt" // Tanya user, jawabannya diingat per origin
+)
+
+// defaults adalah keputusan untuk jenis izin yang tidak diatur. Clipboard
+// dan notifikasi selalu diizinkan sebelum permissions bisa diatur.
+var defaults = map[string]Action{
+	Clipboard:     Allow,
+	Notifications: Allow,
+}
+
+// ParseAction mem-parse nilai izin dari config
+func ParseAction(s string) (Action, error) {
+	switch a := Action(strings.ToLower(strings.TrimSpace(s))); a {
+	case Allow, Deny, Prompt:
+		return a, nil
+	}
+	return "", fmt.Errorf("nilai %q tidak dikenal (allow, deny, prompt)", s)
+}
+
+type rule struct {
+	pattern *navpolicy.Pattern
+	action  Action
+}
+
+// Policy adalah map permissions yang sudah di-compile
+type Policy struct {
+	kinds map[string]Action
+	rules map[string][]rule
+}
+
+// New membuat Policy dari map permissions. Key-nya adalah jenis izin
+// ("camera") untuk semua origin, atau jenis@pola ("camera@meet.example.com")
+// untuk origin tertentu dengan format pola yang sama seperti nav_rules.
+func New(cfg map[string]string) (*Policy, error) {
+	p := &Policy{kinds: map[string]Action{}, rules: map[string][]rule{}}
+	for key, value := range cfg {
+		kind, pat, hasPattern := strings.Cut(strings.TrimSpace(key), "@")
+		kind = strings.ToLower(strings.TrimSpace(kind))
+		if !slices.Contains(Kinds, kind) {
+			return nil, fmt.Errorf("permissions: izin %q tidak dikenal (%s)", kind, strings.Join(Kinds, ", "))
+		}
+		action, err := ParseAction(value)
+		if err != nil {
+			return nil, fmt.Errorf("permissions[%s]: %w", key, err)
+		}
+		if !hasPattern {
+			p.kinds[kind] = action
+			continue
+		}
+		pattern, err := navpolicy.ParsePattern(pat)
+		if err != nil {
+			return nil, fmt.Errorf("permissions[%s]: %w", key, err)
+		}
+		p.rules[kind] = append(p.rules[kind], rule{pattern: pattern, action: action})
+	}
+
+	// Map tidak punya urutan: pola yang lebih panjang (lebih spesifik) dicek
+	// lebih dulu
+	for _, rules := range p.rules {
+		sort.Slice(rules, func(i, j int) bool {
+			a, b := rules[i].pattern.String(), rules[j].pattern.String()
+			if len(a) != len(b) {
+				return len(a) > len(b)
+			}
+			return a < b
+		})
+	}
+	return p, nil
+}
+
+// Decide mengembalikan keputusan untuk izin kind yang diminta halaman
+// rawURL. Urutan evaluasi: jenis@pola yang cocok, jenis saja, lalu default
+// (clipboard dan notifications allow, sisanya prompt).
+func (p *Policy) Decide(kind, rawURL string) Action {
+	if u, err := url.Parse(rawURL); err == nil {
+		for _, r := range p.rules[kind] {
+			if r.pattern.Match(u) {
+				return r.action
+			}
+		}
+	}
+	if a, ok := p.kinds[kind]; ok {
+		return a
+	}
+	if a, ok := defaults[kind]; ok {
+		return a
+	}
+	return Prompt
+}
+
+// Origin mengembalikan scheme://host[:port] dari rawURL, kunci untuk
+// jawaban yang diingat. URL tanpa host dikembalikan apa adanya.
+func Origin(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" {
+		return rawURL
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host)
+}
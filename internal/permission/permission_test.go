@@ -0,0 +1,207 @@
+package permission
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDecide(t *testing.T) {
+	p, err := New(map[string]string{
+		"camera":                          "deny",
+		"camera@.example.com":             "prompt",
+		"camera@meet.example.com":         "allow",
+		"camera@meet.example.com/rooms/*": "deny",
+		"microphone@meet.example.com":     "Allow",
+		" Geolocation ":                   "allow",
+		"notifications":                   "deny",
+		"clipboard@*.untrusted.com":       "deny",
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	tests := []struct {
+		kind string
+		url  string
+		want Action
+	}{
+		// Pola yang paling spesifik menang atas pola yang lebih umum
+		{Camera, "https://meet.example.com/rooms/abc", Deny},
+		{Camera, "https://meet.example.com/", Allow},
+		{Camera, "https://docs.example.com/", Prompt},
+		{Camera, "https://example.com/", Prompt},
+		// Jenis saja jika tidak ada pola yang cocok
+		{Camera, "https://other.com/", Deny},
+		{Camera, "bukan url %%", Deny},
+		{Microphone, "https://meet.example.com/", Allow},
+		{Geolocation, "https://other.com/", Allow},
+		// Jenis menimpa default
+		{Notifications, "https://other.com/", Deny},
+		// Pola menimpa default
+		{Clipboard, "https://a.untrusted.com/", Deny},
+		// Default
+		{Clipboard, "https://other.com/", Allow},
+		{Microphone, "https://other.com/", Prompt},
+		{MultipleDownloads, "https://other.com/", Prompt},
+		{"midi", "https://other.com/", Prompt},
+	}
+	for _, tt := range tests {
+		if got := p.Decide(tt.kind, tt.url); got != tt.want {
+			t.Errorf("Decide(%s, %s) = %s, want %s", tt.kind, tt.url, got, tt.want)
+		}
+	}
+
+	empty, err := New(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for kind, want := range map[string]Action{Clipboard: Allow, Notifications: Allow, Camera: Prompt, Geolocation: Prompt} {
+		if got := empty.Decide(kind, "https://a.com/"); got != want {
+			t.Errorf("Decide default %s = %s, want %s", kind, got, want)
+		}
+	}
+}
+
+func TestNewSort(t *testing.T) {
+	cfg := map[string]string{
+		"camera@a.com":             "allow",
+		"camera@*.b.com":           "deny",
+		"camera@c.com":             "prompt",
+		"camera@meet.a.com/room/*": "deny",
+		"camera@meet.a.com":        "allow",
+	}
+	want := []string{"meet.a.com/room/*", "meet.a.com", "*.b.com", "a.com", "c.com"}
+	// Urutan iterasi map acak, jadi hasilnya harus sama setiap kali
+	for i := 0; i < 20; i++ {
+		p, err := New(cfg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, r := range p.rules[Camera] {
+			got = append(got, r.pattern.String())
+		}
+		if !slices.Equal(got, want) {
+			t.Fatalf("urutan aturan = %q, want %q", got, want)
+		}
+	}
+}
+
+func TestNewError(t *testing.T) {
+	tests := []struct {
+		cfg  map[string]string
+		want string
+	}{
+		{map[string]string{"webcam": "allow"}, `izin "webcam" tidak dikenal`},
+		{map[string]string{"@a.com": "allow"}, `izin "" tidak dikenal`},
+		{map[string]string{"camera": "ask"}, `permissions[camera]: nilai "ask" tidak dikenal`},
+		{map[string]string{"camera@": "allow"}, "permissions[camera@]"},
+		{map[string]string{"camera@a.com": ""}, "permissions[camera@a.com]"},
+	}
+	for _, tt := range tests {
+		if _, err := New(tt.cfg); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("New(%v) = %v, want %q", tt.cfg, err, tt.want)
+		}
+	}
+}
+
+func TestOrigin(t *testing.T) {
+	for raw, want := range map[string]string{
+		"https://Meet.Example.com/rooms/1?x=y": "https://meet.example.com",
+		"http://localhost:3000/a":              "http://localhost:3000",
+		"HTTPS://A.com":                        "https://a.com",
+		"file:///C:/a.html":                    "file:///C:/a.html",
+		"about:blank":                          "about:blank",
+	} {
+		if got := Origin(raw); got != want {
+			t.Errorf("Origin(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestAnswers(t *testing.T) {
+	dir := t.TempDir()
+	a, err := LoadAnswers(dir)
+	if err != nil {
+		t.Fatalf("LoadAnswers tanpa file: %v", err)
+	}
+	if _, ok := a.Get("https://meet.example.com", Camera); ok {
+		t.Error("Get sebelum dijawab: ok = true")
+	}
+
+	for _, s := range []struct {
+		origin, kind string
+		allowed      bool
+	}{
+		{"https://meet.example.com", Camera, true},
+		{"https://meet.example.com", Microphone, false},
+		{"https://maps.example.com", Geolocation, true},
+		{"https://meet.example.com", Camera, false}, // Jawaban baru menimpa
+	} {
+		if err := a.Set(s.origin, s.kind, s.allowed); err != nil {
+			t.Fatalf("Set: %v", err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, answersFile+".tmp")); !os.IsNotExist(err) {
+		t.Errorf("file temporary tertinggal: %v", err)
+	}
+
+	// Jawaban tetap ada setelah app dibuka lagi
+	b, err := LoadAnswers(dir)
+	if err != nil {
+		t.Fatalf("LoadAnswers: %v", err)
+	}
+	tests := []struct {
+		origin, kind string
+		allowed, ok  bool
+	}{
+		{"https://meet.example.com", Camera, false, true},
+		{"https://meet.example.com", Microphone, false, true},
+		{"https://maps.example.com", Geolocation, true, true},
+		{"https://maps.example.com", Camera, false, false},
+		{"https://other.com", Camera, false, false},
+	}
+	for _, tt := range tests {
+		if allowed, ok := b.Get(tt.origin, tt.kind); allowed != tt.allowed || ok != tt.ok {
+			t.Errorf("Get(%s, %s) = %v, %v, want %v, %v", tt.origin, tt.kind, allowed, ok, tt.allowed, tt.ok)
+		}
+	}
+}
+
+func TestAnswersCorrupt(t *testing.T) {
+	dir := t.TempDir()
+	for _, tt := range []struct {
+		data    string
+		wantErr bool
+	}{
+		{`{"https://a.com": {"camera": false`, true},
+		{"null", false},
+	} {
+		data := tt.data
+		if err := os.WriteFile(filepath.Join(dir, answersFile), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		a, err := LoadAnswers(dir)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("LoadAnswers(%q) error = %v, want error %v", data, err, tt.wantErr)
+		}
+		if a == nil {
+			t.Fatalf("LoadAnswers(%q) = nil", data)
+		}
+		if _, ok := a.Get("https://a.com", Camera); ok {
+			t.Errorf("Get setelah file %q: jawaban rusak dipakai", data)
+		}
+		// File rusak diperlakukan seperti belum ada jawaban dan bisa ditimpa
+		if err := a.Set("https://a.com", Camera, true); err != nil {
+			t.Fatalf("Set setelah file %q: %v", data, err)
+		}
+		if allowed, ok := a.Get("https://a.com", Camera); !allowed || !ok {
+			t.Errorf("Get setelah file %q = %v, %v", data, allowed, ok)
+		}
+	}
+	if _, err := LoadAnswers(filepath.Join(dir, "tidak-ada")); err != nil {
+		t.Errorf("LoadAnswers direktori baru: %v", err)
+	}
+}
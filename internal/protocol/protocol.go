@@ -0,0 +1,75 @@
+// Package protocol menentukan scheme URL yang didaftarkan app ke Windows:
+// scheme milik app sendiri untuk aktivasi notifikasi, dan scheme yang diklaim
+// lewat config (web+ourtool:, mailto:).
+package protocol
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// LegacyScheme adalah scheme bersama yang dipakai app lama untuk notifikasi
+const LegacyScheme = "w2app"
+
+// reserved adalah scheme yang tidak boleh diklaim app: scheme web biasa dan
+// scheme internal browser
+var reserved = []string{"http", "https", "file", "ftp", "about", "blob", "data", "javascript", "edge", "microsoft-edge"}
+
+var (
+	schemeRe    = regexp.MustCompile(`^[a-z][a-z0-9+.-]*$`)
+	webSchemeRe = regexp.MustCompile(`^web\+[a-z]+$`)
+)
+
+// AppScheme mengembalikan scheme milik app, diturunkan dari app ID. Scheme
+// selalu diawali "w2app" sehingga tidak bentrok dengan scheme lain.
+func AppScheme(appID string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(appID) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '.', r == '-':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	s := strings.Trim(b.String(), "-.")
+	if !strings.HasPrefix(s, LegacyScheme+".") && !strings.HasPrefix(s, LegacyScheme+"-") {
+		s = LegacyScheme + "-" + s
+	}
+	return s
+}
+
+// IsAppScheme mengecek apakah scheme adalah scheme milik app w2app, termasuk
+// scheme bersama app lama
+func IsAppScheme(scheme string) bool {
+	scheme = Normalize(scheme)
+	return scheme == LegacyScheme || strings.HasPrefix(scheme, LegacyScheme+".") || strings.HasPrefix(scheme, LegacyScheme+"-")
+}
+
+// Normalize mengubah scheme dari config ke bentuk baku: huruf kecil, tanpa
+// ":" atau "://" di akhir
+func Normalize(scheme string) string {
+	scheme = strings.ToLower(strings.TrimSpace(scheme))
+	scheme = strings.TrimSuffix(scheme, "://")
+	return strings.TrimSuffix(scheme, ":")
+}
+
+// Check mengecek scheme yang diklaim lewat config
+func Check(scheme string) error {
+	s := Normalize(scheme)
+	switch {
+	case s == "":
+		return fmt.Errorf("scheme kosong")
+	case !schemeRe.MatchString(s):
+		return fmt.Errorf("scheme %q tidak valid: harus diawali huruf, lalu huruf, angka, +, -, atau .", scheme)
+	case strings.HasPrefix(s, "web+") && !webSchemeRe.MatchString(s):
+		return fmt.Errorf("scheme %q tidak valid: setelah web+ hanya boleh huruf a-z", scheme)
+	case slices.Contains(reserved, s):
+		return fmt.Errorf("scheme %q tidak boleh diklaim app", scheme)
+	case IsAppScheme(s):
+		return fmt.Errorf("scheme %q dipakai w2app untuk notifikasi", scheme)
+	}
+	return nil
+}
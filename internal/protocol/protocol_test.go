@@ -0,0 +1,155 @@
+package protocol_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/user/w2app/internal/config"
+	"github.com/user/w2app/internal/deeplink"
+	"github.com/user/w2app/internal/protocol"
+)
+
+func TestAppScheme(t *testing.T) {
+	tests := []struct {
+		appID string
+		want  string
+	}{
+		{"w2app.whatsapp.3f9a2c1e4b7d", "w2app.whatsapp.3f9a2c1e4b7d"},
+		{"W2App.Chat.ABC", "w2app.chat.abc"},
+		{"com.example.chat", "w2app-com.example.chat"},
+		{"My App_1", "w2app-my-app-1"},
+		{"-.chat.-", "w2app-chat"},
+		{"w2appchat", "w2app-w2appchat"},
+	}
+	for _, tt := range tests {
+		got := protocol.AppScheme(tt.appID)
+		if got != tt.want {
+			t.Errorf("AppScheme(%q) = %q, want %q", tt.appID, got, tt.want)
+		}
+		if !protocol.IsAppScheme(got) {
+			t.Errorf("IsAppScheme(%q) = false", got)
+		}
+		// Scheme app tidak pernah lolos Check, jadi tidak bisa diklaim app lain
+		if err := protocol.Check(got); err == nil {
+			t.Errorf("Check(%q): error nil", got)
+		}
+	}
+	if protocol.AppScheme("com.example.chat") == protocol.AppScheme("com.example.mail") {
+		t.Error("AppScheme sama untuk app ID berbeda")
+	}
+}
+
+func TestIsAppScheme(t *testing.T) {
+	for scheme, want := range map[string]bool{
+		"w2app":          true,
+		"W2APP:":         true,
+		"w2app.chat.abc": true,
+		"w2app-chat":     true,
+		"w2apps":         false,
+		"myapp":          false,
+	} {
+		if got := protocol.IsAppScheme(scheme); got != want {
+			t.Errorf("IsAppScheme(%q) = %v, want %v", scheme, got, want)
+		}
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	for in, want := range map[string]string{
+		"mailto":         "mailto",
+		" MailTo: ":      "mailto",
+		"web+OurTool://": "web+ourtool",
+		"myapp:":         "myapp",
+	} {
+		if got := protocol.Normalize(in); got != want {
+			t.Errorf("Normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCheck(t *testing.T) {
+	for _, scheme := range []string{"mailto", "MAILTO:", "web+ourtool", "web+OurTool://", "myapp", "my-app.v2+x", "ms-teams"} {
+		if err := protocol.Check(scheme); err != nil {
+			t.Errorf("Check(%q): %v", scheme, err)
+		}
+	}
+
+	tests := []struct {
+		scheme string
+		want   string
+	}{
+		{"", "kosong"},
+		{"  :", "kosong"},
+		{"1app", "tidak valid"},
+		{"my app", "tidak valid"},
+		{"my_app", "tidak valid"},
+		{"+app", "tidak valid"},
+		{"web+", "web+"},
+		{"web+our-tool", "web+"},
+		{"web+tool2", "web+"},
+		{"http", "tidak boleh"},
+		{"HTTPS://", "tidak boleh"},
+		{"file", "tidak boleh"},
+		{"javascript", "tidak boleh"},
+		{"microsoft-edge", "tidak boleh"},
+		{"w2app", "notifikasi"},
+		{"w2app.other.123", "notifikasi"},
+		{"w2app-other", "notifikasi"},
+	}
+	for _, tt := range tests {
+		if err := protocol.Check(tt.scheme); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Check(%q) = %v, want %q", tt.scheme, err, tt.want)
+		}
+	}
+}
+
+func TestValidateProtocols(t *testing.T) {
+	valid := func(protocols ...config.Protocol) *config.AppConfig {
+		return &config.AppConfig{URL: "https://app.example.com", Title: "App", Width: 800, Height: 600, Protocols: protocols}
+	}
+	if err := valid(
+		config.Protocol{Scheme: "mailto", Target: "https://app.example.com/compose?to={path}"},
+		config.Protocol{Scheme: "web+ourtool", Target: "https://app.example.com/open?uri=%s"},
+	).Validate(); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+
+	tests := []struct {
+		cfg  *config.AppConfig
+		want string
+	}{
+		{valid(config.Protocol{Scheme: "mailto", Target: "https://a.com/%s"}, config.Protocol{Scheme: "MailTo:", Target: "https://b.com/%s"}), `"mailto" disebut lebih dari sekali`},
+		{valid(config.Protocol{Scheme: "web+x", Target: "https://a.com/%s"}, config.Protocol{Scheme: "web+x://", Target: "https://a.com/%s"}), "lebih dari sekali"},
+		{valid(config.Protocol{Scheme: "https", Target: "https://a.com/%s"}), "protocols[0]"},
+		{valid(config.Protocol{Scheme: "mailto", Target: "https://a.com/%s"}, config.Protocol{Scheme: "myapp"}), "protocols[1]: target wajib diisi"},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.Validate(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Validate(%+v) = %v, want %q", tt.cfg.Protocols, err, tt.want)
+		}
+	}
+}
+
+// Scheme web+ dipakai tanpa "//", seperti registerProtocolHandler
+func TestOpaqueWebScheme(t *testing.T) {
+	m, err := deeplink.New(&config.AppConfig{Protocols: []config.Protocol{
+		{Scheme: "web+ourtool", Target: "https://app.example.com/open?uri=%s"},
+		{Scheme: "web+item", Target: "https://app.example.com/item/{path}"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for raw, want := range map[string]string{
+		"web+ourtool:doc/1":   "https://app.example.com/open?uri=web%2Bourtool%3Adoc%2F1",
+		"web+ourtool://doc/1": "https://app.example.com/open?uri=web%2Bourtool%3A%2F%2Fdoc%2F1",
+		"WEB+OURTOOL:x":       "https://app.example.com/open?uri=web%2Bourtool%3Ax",
+		"web+item:42":         "https://app.example.com/item/42",
+	} {
+		if got, ok := m.Map(raw); !ok || got != want {
+			t.Errorf("Map(%q) = %q, %v, want %q", raw, got, ok, want)
+		}
+	}
+	if got, ok := m.Map("web+other:x"); ok {
+		t.Errorf("Map(web+other:x) = %q, want tidak dipetakan", got)
+	}
+}
@@ -0,0 +1,314 @@
+// Package userscript menjalankan userscript bergaya Greasemonkey di app:
+// membaca blok metadata file .user.js dan membuat init script WebView2 yang
+// hanya berjalan di halaman yang cocok, pada waktu run_at, dan melaporkan
+// exception ke log app.
+package userscript
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/user/w2app/internal/config"
+)
+
+// Waktu menjalankan userscript
+const (
+	RunAtStart = "document-start" // Sebelum halaman di-parse
+	RunAtEnd   = "document-end"   // Setelah DOMContentLoaded (default)
+	RunAtIdle  = "idle"           // Setelah halaman selesai dimuat dan browser idle
+)
+
+// RunAts adalah semua nilai run_at yang valid
+var RunAts = []string{RunAtStart, RunAtEnd, RunAtIdle}
+
+// ErrorFunc adalah fungsi yang di-bind app untuk mencatat exception
+// userscript: ErrorFunc(nama, pesan)
+const ErrorFunc = "w2appScriptError"
+
+// Ext adalah ekstensi file userscript
+const Ext = ".user.js"
+
+// NormalizeRunAt mengembalikan run_at baku. Kosong berarti RunAtEnd, dan
+// "document-idle" dari Greasemonkey sama dengan RunAtIdle.
+func NormalizeRunAt(runAt string) (string, error) {
+	switch r := strings.ToLower(strings.TrimSpace(runAt)); r {
+	case "":
+		return RunAtEnd, nil
+	case "document-idle":
+		return RunAtIdle, nil
+	case RunAtStart, RunAtEnd, RunAtIdle:
+		return r, nil
+	}
+	return "", fmt.Errorf("run_at %q tidak dikenal (%s)", runAt, strings.Join(RunAts, ", "))
+}
+
+// Check mengecek daftar userscript dari config
+func Check(scripts []config.UserScript) error {
+	for i, s := range scripts {
+		if _, err := Script(s); err != nil {
+			return fmt.Errorf("userscripts[%d] (%s): %w", i, s.Name, err)
+		}
+	}
+	return nil
+}
+
+// Script mengembalikan JavaScript yang menjalankan s, untuk didaftarkan
+// sebagai init script tersendiri supaya syntax error di satu userscript
+// tidak menghentikan script lain.
+//
+// Script isolated dijalankan di dalam function sendiri. Script lain berjalan
+// di scope global: langsung di top level untuk document-start, atau lewat
+// eval global untuk waktu lain, yang butuh CSP halaman mengizinkan eval.
+func Script(s config.UserScript) (string, error) {
+	if strings.TrimSpace(s.Code) == "" {
+		return "", fmt.Errorf("code kosong")
+	}
+	runAt, err := NormalizeRunAt(s.RunAt)
+	if err != nil {
+		return "", err
+	}
+	match, err := patterns(s.Match)
+	if err != nil {
+		return "", fmt.Errorf("match: %w", err)
+	}
+	exclude, err := patterns(s.Exclude)
+	if err != nil {
+		return "", fmt.Errorf("exclude: %w", err)
+	}
+
+	guard := fmt.Sprintf(`(function(match, exclude) {
+	var test = function(list) {
+		return list.some(function(re) { return new RegExp(re).test(location.href); });
+	};
+	return (match.length === 0 || test(match)) && !test(exclude);
+})(%s, %s)`, jsValue(match), jsValue(exclude))
+	report := fmt.Sprintf(`function(e) {
+	if (typeof window.%[1]s === 'function') window.%[1]s(%[2]s, String(e && e.stack || e));
+}`, ErrorFunc, jsValue(s.Name))
+
+	// Guard ikut di dalam try: regex /.../ yang tidak valid di JavaScript
+	// baru gagal saat new RegExp dan harus dilaporkan seperti error lain
+	if !s.Isolated && runAt == RunAtStart {
+		return fmt.Sprintf("try {\nif (%s) {\n%s\n}\n} catch (e) { (%s)(e); }\n", guard, s.Code, report), nil
+	}
+
+	body := fmt.Sprintf("(0, eval)(%s);", jsValue(s.Code))
+	if s.Isolated {
+		body = fmt.Sprintf("(function() {\n%s\n}).call(window);", s.Code)
+	}
+	var timing string
+	switch runAt {
+	case RunAtStart:
+		timing = "run();"
+	case RunAtEnd:
+		timing = `if (document.readyState === 'loading') {
+		document.addEventListener('DOMContentLoaded', run, { once: true });
+	} else {
+		run();
+	}`
+	case RunAtIdle:
+		timing = `var idle = function() { (window.requestIdleCallback || setTimeout)(run); };
+	if (document.readyState === 'complete') {
+		idle();
+	} else {
+		window.addEventListener('load', idle, { once: true });
+	}`
+	}
+	return fmt.Sprintf(`(function() {
+	try {
+		if (!%s) return;
+	} catch (e) { (%s)(e); return; }
+	var run = function() {
+		try {
+%s
+		} catch (e) { (%s)(e); }
+	};
+	%s
+})();
+`, guard, report, body, report, timing), nil
+}
+
+func jsValue(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
+
+func patterns(list []string) ([]string, error) {
+	res := []string{}
+	for _, p := range list {
+		re, err := PatternRegexp(p)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, re)
+	}
+	return res, nil
+}
+
+// matchPatternRe memecah pola @match: scheme://host/path
+var matchPatternRe = regexp.MustCompile(`^(\*|https?|wss?|file|ftp)://([^/]*)(/.*)$`)
+
+// PatternRegexp mengubah pola @match, @include, atau @exclude menjadi regex
+// yang dicocokkan dengan URL halaman di JavaScript.
+//
+// Format yang didukung:
+//
+//	*://*.example.com/*     pola @match: scheme * berarti http dan https,
+//	                        host *.example.com termasuk example.com
+//	https://example.com/a*  glob @include: * cocok dengan apa saja
+//	/^https:\/\/x\.com/     regex, dipakai apa adanya
+//	* atau <all_urls>       semua halaman
+//
+// Regex memakai sintaks JavaScript, jadi tidak dicek di sini; regex yang
+// tidak valid dilaporkan lewat ErrorFunc saat halaman dimuat.
+func PatternRegexp(pattern string) (string, error) {
+	p := strings.TrimSpace(pattern)
+	switch {
+	case p == "":
+		return "", fmt.Errorf("pola kosong")
+	case p == "*" || p == "<all_urls>":
+		return "^", nil
+	case len(p) > 2 && p[0] == '/' && p[len(p)-1] == '/':
+		return p[1 : len(p)-1], nil
+	}
+
+	if m := matchPatternRe.FindStringSubmatch(p); m != nil && validMatchHost(m[2]) {
+		scheme, host, path := m[1], m[2], m[3]
+		var b strings.Builder
+		b.WriteString("^")
+		if scheme == "*" {
+			b.WriteString("https?")
+		} else {
+			b.WriteString(regexp.QuoteMeta(scheme))
+		}
+		b.WriteString("://")
+		switch {
+		case host == "*":
+			b.WriteString("[^/]*")
+		case strings.HasPrefix(host, "*."):
+			b.WriteString(`(?:[^/]*\.)?` + regexp.QuoteMeta(host[2:]) + `(?::\d+)?`)
+		case host != "":
+			b.WriteString(regexp.QuoteMeta(host) + `(?::\d+)?`)
+		}
+		b.WriteString(globRegexp(path) + "$")
+		return b.String(), nil
+	}
+	return "^" + globRegexp(p) + "$", nil
+}
+
+// validMatchHost melaporkan apakah host mengikuti aturan @match: "*",
+// "*." di depan, atau tanpa wildcard
+func validMatchHost(host string) bool {
+	return host == "*" || !strings.Contains(strings.TrimPrefix(host, "*."), "*")
+}
+
+func globRegexp(glob string) string {
+	parts := strings.Split(glob, "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	return strings.Join(parts, ".*")
+}
+
+// LoadDir membaca semua file .user.js di dir, urut berdasarkan nama file
+func LoadDir(dir string) ([]config.UserScript, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var scripts []config.UserScript
+	for _, e := range entries {
+		if e.IsDir() || !strings.HasSuffix(strings.ToLower(e.Name()), Ext) {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		s, err := Parse(e.Name(), string(data))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", e.Name(), err)
+		}
+		scripts = append(scripts, s)
+	}
+	if len(scripts) == 0 {
+		return nil, fmt.Errorf("tidak ada file %s di %s", Ext, dir)
+	}
+	return scripts, nil
+}
+
+// Parse membaca userscript dari isi file bernama file. Blok metadata
+// "// ==UserScript==" ... "// ==/UserScript==" wajib ada; key yang dipakai
+// adalah @name, @match, @include, @exclude, @exclude-match, @run-at, dan
+// @unwrap. Key lain (@grant, @version, ...) diabaikan.
+//
+// Seperti Greasemonkey, script dijalankan di scope sendiri kecuali ada
+// @unwrap.
+func Parse(file, source string) (config.UserScript, error) {
+	s := config.UserScript{
+		Name:     strings.TrimSuffix(filepath.Base(file), Ext),
+		Isolated: true,
+	}
+
+	lines := strings.Split(strings.ReplaceAll(source, "\r\n", "\n"), "\n")
+	start := slices.IndexFunc(lines, func(l string) bool { return metaLine(l) == "==UserScript==" })
+	if start < 0 {
+		return s, fmt.Errorf("blok metadata ==UserScript== tidak ada")
+	}
+	end := -1
+	for i := start + 1; i < len(lines); i++ {
+		line := metaLine(lines[i])
+		if line == "==/UserScript==" {
+			end = i
+			break
+		}
+		if !strings.HasPrefix(line, "@") {
+			continue
+		}
+		key, value := line[1:], ""
+		if j := strings.IndexAny(key, " \t"); j >= 0 {
+			key, value = key[:j], strings.TrimSpace(key[j:])
+		}
+		switch key {
+		case "name":
+			if value != "" {
+				s.Name = value
+			}
+		case "match", "include":
+			s.Match = append(s.Match, value)
+		case "exclude", "exclude-match":
+			s.Exclude = append(s.Exclude, value)
+		case "run-at":
+			runAt, err := NormalizeRunAt(value)
+			if err != nil {
+				return s, fmt.Errorf("baris %d: %w", i+1, err)
+			}
+			s.RunAt = runAt
+		case "unwrap":
+			s.Isolated = false
+		}
+	}
+	if end < 0 {
+		return s, fmt.Errorf("blok metadata tidak ditutup dengan ==/UserScript==")
+	}
+
+	s.Code = strings.TrimSpace(strings.Join(lines[end+1:], "\n"))
+	if s.Code == "" {
+		return s, fmt.Errorf("code kosong")
+	}
+	return s, nil
+}
+
+// metaLine mengembalikan isi baris komentar metadata tanpa "//"
+func metaLine(line string) string {
+	line = strings.TrimSpace(line)
+	if !strings.HasPrefix(line, "//") {
+		return ""
+	}
+	return strings.TrimSpace(strings.TrimPrefix(line, "//"))
+}
@@ -0,0 +1,286 @@
+package userscript
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/user/w2app/internal/config"
+)
+
+func TestParse(t *testing.T) {
+	source := `// ==UserScript==
+// @name        Dark Mode
+// @namespace   https://example.com
+// @version     1.2
+// @match       *://*.example.com/*
+// @include     https://other.com/app*
+// @exclude     https://example.com/admin/*
+// @exclude-match *://*.example.com/login*
+// @run-at      document-start
+// @grant       none
+// @unwrap
+// ==/UserScript==
+
+document.documentElement.classList.add('dark');
+`
+	s, err := Parse("dark.user.js", source)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	want := config.UserScript{
+		Name:    "Dark Mode",
+		Match:   []string{"*://*.example.com/*", "https://other.com/app*"},
+		Exclude: []string{"https://example.com/admin/*", "*://*.example.com/login*"},
+		RunAt:   RunAtStart,
+		Code:    "document.documentElement.classList.add('dark');",
+	}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("Parse = %+v, want %+v", s, want)
+	}
+}
+
+func TestParseDefaults(t *testing.T) {
+	source := "'use strict';\r\n// ==UserScript==\r\n//@match https://a.com/*\r\n// @run-at document-idle\r\n// ==/UserScript==\r\n\r\nconsole.log(1);\r\nconsole.log(2);\r\n"
+	s, err := Parse(filepath.FromSlash("scripts/My Script.user.js"), source)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if s.Name != "My Script" {
+		t.Errorf("Name = %q, want nama file", s.Name)
+	}
+	if !s.Isolated {
+		t.Error("Isolated = false tanpa @unwrap")
+	}
+	if s.RunAt != RunAtIdle {
+		t.Errorf("RunAt = %q, want %q", s.RunAt, RunAtIdle)
+	}
+	if !reflect.DeepEqual(s.Match, []string{"https://a.com/*"}) {
+		t.Errorf("Match = %v", s.Match)
+	}
+	if s.Code != "console.log(1);\nconsole.log(2);" {
+		t.Errorf("Code = %q", s.Code)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"tanpa blok", "console.log(1);", "==UserScript=="},
+		{"tidak ditutup", "// ==UserScript==\n// @name x\nconsole.log(1);", "ditutup"},
+		{"code kosong", "// ==UserScript==\n// ==/UserScript==\n\n  \n", "code kosong"},
+		{"run-at", "// ==UserScript==\n// @run-at document-body\n// ==/UserScript==\nx();", "baris 2"},
+	}
+	for _, tt := range tests {
+		if _, err := Parse("a.user.js", tt.source); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%s: error = %v, want %q", tt.name, err, tt.want)
+		}
+	}
+}
+
+func TestNormalizeRunAt(t *testing.T) {
+	for in, want := range map[string]string{
+		"":                 RunAtEnd,
+		" Document-Start ": RunAtStart,
+		"document-end":     RunAtEnd,
+		"document-idle":    RunAtIdle,
+		"idle":             RunAtIdle,
+	} {
+		if got, err := NormalizeRunAt(in); err != nil || got != want {
+			t.Errorf("NormalizeRunAt(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := NormalizeRunAt("document-body"); err == nil {
+		t.Error("NormalizeRunAt(document-body): error nil")
+	}
+}
+
+func TestPatternRegexp(t *testing.T) {
+	tests := []struct {
+		pattern string
+		match   []string
+		noMatch []string
+	}{
+		{
+			pattern: "*://*.example.com/*",
+			match:   []string{"https://example.com/", "http://www.example.com/a?b", "https://a.b.example.com:8443/x"},
+			noMatch: []string{"https://badexample.com/", "https://example.com.evil.org/", "ftp://example.com/", "https://example.org/?u=example.com/"},
+		},
+		{
+			pattern: "https://example.com/app/*",
+			match:   []string{"https://example.com/app/", "https://example.com:444/app/x"},
+			noMatch: []string{"http://example.com/app/", "https://example.com/apps", "https://www.example.com/app/"},
+		},
+		{
+			pattern: "*://*/*",
+			match:   []string{"https://a.com/", "http://localhost:3000/x"},
+			noMatch: []string{"file:///C:/a.html"},
+		},
+		{
+			pattern: "file:///*",
+			match:   []string{"file:///C:/a.html"},
+			noMatch: []string{"https://a.com/"},
+		},
+		{
+			pattern: "<all_urls>",
+			match:   []string{"https://a.com/", "file:///C:/a.html", "about:blank"},
+		},
+		{
+			pattern: " * ",
+			match:   []string{"https://a.com/"},
+		},
+		{
+			// Glob @include: * di host juga cocok dengan apa saja
+			pattern: "https://*.example.*/a.html",
+			match:   []string{"https://www.example.co.id/a.html", "https://x.example.com/a.html"},
+			noMatch: []string{"https://www.example.com/a.html?x"},
+		},
+		{
+			pattern: "http*://example.com/?q=a+b",
+			match:   []string{"https://example.com/?q=a+b", "http://example.com/?q=a+b"},
+			noMatch: []string{"https://example.com/?q=aab"},
+		},
+		{
+			pattern: `/^https:\/\/(www\.)?x\.com\/(?:a|b)$/`,
+			match:   []string{"https://x.com/a", "https://www.x.com/b"},
+			noMatch: []string{"https://x.com/c", "http://x.com/a"},
+		},
+	}
+	for _, tt := range tests {
+		got, err := PatternRegexp(tt.pattern)
+		if err != nil {
+			t.Errorf("PatternRegexp(%q): %v", tt.pattern, err)
+			continue
+		}
+		re, err := regexp.Compile(got)
+		if err != nil {
+			t.Errorf("PatternRegexp(%q) = %q: %v", tt.pattern, got, err)
+			continue
+		}
+		for _, u := range tt.match {
+			if !re.MatchString(u) {
+				t.Errorf("PatternRegexp(%q) = %q tidak cocok dengan %s", tt.pattern, got, u)
+			}
+		}
+		for _, u := range tt.noMatch {
+			if re.MatchString(u) {
+				t.Errorf("PatternRegexp(%q) = %q cocok dengan %s", tt.pattern, got, u)
+			}
+		}
+	}
+
+	// Regex dipakai apa adanya, termasuk sintaks yang hanya ada di JavaScript
+	if got, _ := PatternRegexp(`/^https:\/\/x\.com\/(?!admin)/`); got != `^https:\/\/x\.com\/(?!admin)` {
+		t.Errorf("PatternRegexp(regex) = %q", got)
+	}
+	for _, p := range []string{"", "  "} {
+		if _, err := PatternRegexp(p); err == nil {
+			t.Errorf("PatternRegexp(%q): error nil", p)
+		}
+	}
+}
+
+func TestScript(t *testing.T) {
+	tests := []struct {
+		name string
+		s    config.UserScript
+		want []string
+	}{
+		{"unwrap document-start", config.UserScript{Name: "a", RunAt: RunAtStart, Code: "var x = 1;"},
+			[]string{"var x = 1;"}},
+		{"unwrap document-end", config.UserScript{Name: "a", Code: "var x = 1;"},
+			[]string{`(0, eval)("var x = 1;")`, "DOMContentLoaded"}},
+		{"isolated idle", config.UserScript{Name: "a", RunAt: "document-idle", Isolated: true, Code: "var x = 1;"},
+			[]string{"var x = 1;", "}).call(window);", "requestIdleCallback"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.s.Match = []string{"*://*.example.com/*", `/^https:\/\/x\.com\/(?!admin)/`}
+			js, err := Script(tt.s)
+			if err != nil {
+				t.Fatalf("Script: %v", err)
+			}
+			for _, want := range append(tt.want, ErrorFunc, `"^https?://(?:[^/]*\\.)?example\\.com(?::\\d+)?/.*$"`) {
+				if !strings.Contains(js, want) {
+					t.Errorf("script tidak berisi %q:\n%s", want, js)
+				}
+			}
+			// Guard berada di dalam try, jadi regex yang tidak valid di
+			// JavaScript dilaporkan lewat ErrorFunc
+			try, guard := strings.Index(js, "try {"), strings.Index(js, "new RegExp")
+			if try < 0 || guard < try {
+				t.Errorf("guard di luar try:\n%s", js)
+			}
+		})
+	}
+}
+
+func TestScriptError(t *testing.T) {
+	tests := []struct {
+		s    config.UserScript
+		want string
+	}{
+		{config.UserScript{Code: " \n"}, "code kosong"},
+		{config.UserScript{Code: "x();", RunAt: "later"}, "run_at"},
+		{config.UserScript{Code: "x();", Match: []string{""}}, "match"},
+		{config.UserScript{Code: "x();", Exclude: []string{" "}}, "exclude"},
+	}
+	for _, tt := range tests {
+		if _, err := Script(tt.s); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("Script(%+v) error = %v, want %q", tt.s, err, tt.want)
+		}
+	}
+	err := Check([]config.UserScript{{Name: "ok", Code: "x();"}, {Name: "rusak"}})
+	if err == nil || !strings.Contains(err.Error(), "userscripts[1] (rusak)") {
+		t.Errorf("Check = %v", err)
+	}
+}
+
+func TestLoadDir(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"b.user.js":   "// ==UserScript==\n// @name B\n// ==/UserScript==\nb();",
+		"a.user.js":   "// ==UserScript==\n// ==/UserScript==\na();",
+		"c.js":        "c();",
+		"notes.txt":   "x",
+		"sub.user.js": "",
+	}
+	for name, data := range files {
+		if name == "sub.user.js" {
+			if err := os.Mkdir(filepath.Join(dir, name), 0755); err != nil {
+				t.Fatal(err)
+			}
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	scripts, err := LoadDir(dir)
+	if err != nil {
+		t.Fatalf("LoadDir: %v", err)
+	}
+	var names []string
+	for _, s := range scripts {
+		names = append(names, s.Name)
+	}
+	if want := []string{"a", "B"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("LoadDir = %v, want %v", names, want)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "d.user.js"), []byte("d();"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadDir(dir); err == nil || !strings.Contains(err.Error(), "d.user.js") {
+		t.Errorf("LoadDir dengan file rusak = %v", err)
+	}
+	if _, err := LoadDir(t.TempDir()); err == nil {
+		t.Error("LoadDir direktori kosong: error nil")
+	}
+}
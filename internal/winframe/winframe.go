@@ -0,0 +1,83 @@
+// Package winframe menghitung frame window app: bit style untuk opsi
+// frameless dan resizable, serta area resize di bawah kursor untuk window
+// frameless. Package ini tidak memanggil API Windows; stub yang membaca dan
+// menerapkan hasilnya.
+package winframe
+
+// Bit style window (GWL_STYLE) yang diatur Style
+const (
+	StyleOverlappedWindow uint32 = 0x00CF0000
+	StylePopup            uint32 = 0x80000000
+	StyleSysMenu          uint32 = 0x00080000
+	StyleThickFrame       uint32 = 0x00040000
+	StyleMinimizeBox      uint32 = 0x00020000
+	StyleMaximizeBox      uint32 = 0x00010000
+)
+
+// Style mengembalikan style dengan bit frame untuk opsi yang diberikan.
+//
+// Window frameless kehilangan caption dan border. Window resizable tetap
+// memakai WS_THICKFRAME supaya Windows yang mengurus resize dan snap; frame-nya
+// disembunyikan stub di WM_NCCALCSIZE. Hanya window resizable yang bisa
+// di-maximize. Bit yang tidak berhubungan dengan frame dipertahankan.
+func Style(style uint32, frameless, resizable bool) uint32 {
+	if frameless {
+		style = style&^StyleOverlappedWindow | StylePopup | StyleSysMenu | StyleMinimizeBox
+	}
+	if resizable {
+		return style | StyleThickFrame | StyleMaximizeBox
+	}
+	return style &^ (StyleThickFrame | StyleMaximizeBox)
+}
+
+// Hit adalah hasil WM_NCHITTEST: bagian window di bawah kursor
+type Hit uintptr
+
+const (
+	HitClient      Hit = 1
+	HitCaption     Hit = 2
+	HitLeft        Hit = 10
+	HitRight       Hit = 11
+	HitTop         Hit = 12
+	HitTopLeft     Hit = 13
+	HitTopRight    Hit = 14
+	HitBottom      Hit = 15
+	HitBottomLeft  Hit = 16
+	HitBottomRight Hit = 17
+)
+
+// Resize melaporkan apakah h adalah tepi atau sudut untuk resize
+func (h Hit) Resize() bool {
+	return h >= HitLeft && h <= HitBottomRight
+}
+
+// HitTest mengembalikan tepi resize di titik (x, y) pada window berukuran
+// width x height, dihitung dari pojok kiri atas window. Titik yang berjarak
+// kurang dari border dari tepi ada di tepi tersebut, dan di sudut jika dekat
+// dengan dua tepi. Titik lain menghasilkan HitClient.
+func HitTest(x, y, width, height, border int32) Hit {
+	top := y < border
+	bottom := !top && y >= height-border
+	left := x < border
+	right := !left && x >= width-border
+
+	switch {
+	case top && left:
+		return HitTopLeft
+	case top && right:
+		return HitTopRight
+	case bottom && left:
+		return HitBottomLeft
+	case bottom && right:
+		return HitBottomRight
+	case top:
+		return HitTop
+	case bottom:
+		return HitBottom
+	case left:
+		return HitLeft
+	case right:
+		return HitRight
+	}
+	return HitClient
+}
@@ -0,0 +1,91 @@
+package winframe
+
+import "testing"
+
+func TestStyle(t *testing.T) {
+	const (
+		overlapped = 0x14CF0000 // WS_OVERLAPPEDWINDOW | WS_VISIBLE | WS_CLIPSIBLINGS
+		visible    = 0x10000000
+		clip       = 0x04000000
+	)
+	tests := []struct {
+		name                 string
+		style                uint32
+		frameless, resizable bool
+		want                 uint32
+	}{
+		{"default resizable", overlapped, false, true, overlapped},
+		{"fixed size", overlapped, false, false, overlapped &^ (StyleThickFrame | StyleMaximizeBox)},
+		{"frameless resizable", overlapped, true, true,
+			visible | clip | StylePopup | StyleSysMenu | StyleMinimizeBox | StyleThickFrame | StyleMaximizeBox},
+		{"frameless fixed", overlapped, true, false,
+			visible | clip | StylePopup | StyleSysMenu | StyleMinimizeBox},
+		{"resizable adds frame", StylePopup, false, true, StylePopup | StyleThickFrame | StyleMaximizeBox},
+		{"idempotent", visible | clip | StylePopup | StyleSysMenu | StyleMinimizeBox, true, false,
+			visible | clip | StylePopup | StyleSysMenu | StyleMinimizeBox},
+	}
+	for _, tt := range tests {
+		if got := Style(tt.style, tt.frameless, tt.resizable); got != tt.want {
+			t.Errorf("%s: Style(0x%08x, %v, %v) = 0x%08x, want 0x%08x",
+				tt.name, tt.style, tt.frameless, tt.resizable, got, tt.want)
+		}
+	}
+}
+
+func TestHitTest(t *testing.T) {
+	const w, h, border = 800, 600, 6
+	tests := []struct {
+		x, y int32
+		want Hit
+	}{
+		{400, 300, HitClient},
+		{6, 6, HitClient},
+		{793, 593, HitClient},
+
+		{400, 0, HitTop},
+		{400, 5, HitTop},
+		{400, 599, HitBottom},
+		{400, 594, HitBottom},
+		{0, 300, HitLeft},
+		{5, 300, HitLeft},
+		{799, 300, HitRight},
+		{794, 300, HitRight},
+
+		{0, 0, HitTopLeft},
+		{5, 5, HitTopLeft},
+		{799, 0, HitTopRight},
+		{0, 599, HitBottomLeft},
+		{799, 599, HitBottomRight},
+		{794, 594, HitBottomRight},
+
+		// Di luar window tetap dianggap tepi terdekat
+		{-3, 300, HitLeft},
+		{400, 620, HitBottom},
+	}
+	for _, tt := range tests {
+		if got := HitTest(tt.x, tt.y, w, h, border); got != tt.want {
+			t.Errorf("HitTest(%d, %d) = %d, want %d", tt.x, tt.y, got, tt.want)
+		}
+	}
+
+	// Window lebih kecil dari dua kali border: atas dan kiri menang
+	if got := HitTest(3, 3, 8, 8, border); got != HitTopLeft {
+		t.Errorf("HitTest di window kecil = %d, want %d", got, HitTopLeft)
+	}
+	if got := HitTest(100, 100, w, h, 0); got != HitClient {
+		t.Errorf("HitTest tanpa border = %d, want %d", got, HitClient)
+	}
+}
+
+func TestHitResize(t *testing.T) {
+	for hit := HitLeft; hit <= HitBottomRight; hit++ {
+		if !hit.Resize() {
+			t.Errorf("Hit(%d).Resize() = false", hit)
+		}
+	}
+	for _, hit := range []Hit{0, HitClient, HitCaption, 18} {
+		if hit.Resize() {
+			t.Errorf("Hit(%d).Resize() = true", hit)
+		}
+	}
+}
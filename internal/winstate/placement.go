@@ -0,0 +1,113 @@
+package winstate
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Positions adalah nilai Placement.Position yang didukung
+var Positions = []string{
+	"center",
+	"top-left", "top", "top-right",
+	"left", "right",
+	"bottom-left", "bottom", "bottom-right",
+}
+
+// CheckMonitor mengecek format pilihan monitor: nomor mulai 1, "primary",
+// atau "largest"
+func CheckMonitor(spec string) error {
+	switch strings.ToLower(spec) {
+	case "", "primary", "largest":
+		return nil
+	}
+	if n, err := strconv.Atoi(spec); err != nil || n < 1 {
+		return fmt.Errorf("monitor %q harus nomor (mulai 1), primary, atau largest", spec)
+	}
+	return nil
+}
+
+// CheckPosition mengecek apakah pos adalah salah satu Positions
+func CheckPosition(pos string) error {
+	if pos == "" || slices.Contains(Positions, strings.ToLower(pos)) {
+		return nil
+	}
+	return fmt.Errorf("position %q tidak dikenal (pilih: %s)", pos, strings.Join(Positions, ", "))
+}
+
+// SelectMonitor memilih monitor sesuai spec (lihat CheckMonitor). Nomor
+// monitor dihitung dari kiri ke kanan, lalu atas ke bawah. found bernilai
+// false jika monitor yang diminta tidak ada.
+func SelectMonitor(spec string, monitors []Monitor) (m Monitor, found bool) {
+	if len(monitors) == 0 {
+		return Monitor{}, false
+	}
+
+	switch strings.ToLower(spec) {
+	case "primary":
+		for _, m := range monitors {
+			if m.Primary {
+				return m, true
+			}
+		}
+		return Monitor{}, false
+	case "largest":
+		largest := monitors[0]
+		for _, m := range monitors[1:] {
+			if m.Bounds.area() > largest.Bounds.area() {
+				largest = m
+			}
+		}
+		return largest, true
+	}
+
+	n, err := strconv.Atoi(spec)
+	if err != nil || n < 1 || n > len(monitors) {
+		return Monitor{}, false
+	}
+	sorted := slices.Clone(monitors)
+	slices.SortStableFunc(sorted, func(a, b Monitor) int {
+		if a.Bounds.Left != b.Bounds.Left {
+			return int(a.Bounds.Left - b.Bounds.Left)
+		}
+		return int(a.Bounds.Top - b.Bounds.Top)
+	})
+	return sorted[n-1], true
+}
+
+// Placement adalah posisi window yang diminta config
+type Placement struct {
+	Position string // Salah satu Positions, kosong berarti center
+	X, Y     *int   // Posisi relatif terhadap area kerja; menimpa Position untuk sumbu tersebut
+}
+
+// Rect menghitung posisi window berukuran width x height di area kerja work.
+// Hasilnya selalu berada di dalam work.
+func (p Placement) Rect(width, height int32, work Rect) Rect {
+	r := Center(width, height, work)
+	w, h := r.Width(), r.Height()
+
+	pos := strings.ToLower(p.Position)
+	switch {
+	case strings.HasSuffix(pos, "left"):
+		r.Left = work.Left
+	case strings.HasSuffix(pos, "right"):
+		r.Left = work.Right - w
+	}
+	switch {
+	case strings.HasPrefix(pos, "top"):
+		r.Top = work.Top
+	case strings.HasPrefix(pos, "bottom"):
+		r.Top = work.Bottom - h
+	}
+
+	if p.X != nil {
+		r.Left = work.Left + int32(*p.X)
+	}
+	if p.Y != nil {
+		r.Top = work.Top + int32(*p.Y)
+	}
+	r.Right, r.Bottom = r.Left+w, r.Top+h
+	return Clamp(r, work)
+}
@@ -0,0 +1,123 @@
+package winstate
+
+import (
+	"strings"
+	"testing"
+)
+
+// Monitor ketiga di atas monitor utama, lebih lebar dari monitor lain
+var top = Monitor{
+	ID:     `\\.\DISPLAY3`,
+	Bounds: Rect{0, -1080, 2560, 0},
+	Work:   Rect{0, -1080, 2560, 0},
+}
+
+func TestCheckMonitor(t *testing.T) {
+	for _, spec := range []string{"", "1", "2", "12", "primary", "Largest", "PRIMARY"} {
+		if err := CheckMonitor(spec); err != nil {
+			t.Errorf("CheckMonitor(%q): %v", spec, err)
+		}
+	}
+	for _, spec := range []string{"0", "-1", "1.5", " 1", "second", "utama"} {
+		if err := CheckMonitor(spec); err == nil || !strings.Contains(err.Error(), "primary, atau largest") {
+			t.Errorf("CheckMonitor(%q) = %v, want error", spec, err)
+		}
+	}
+}
+
+func TestCheckPosition(t *testing.T) {
+	for _, pos := range append([]string{"", "Top-Left", "BOTTOM"}, Positions...) {
+		if err := CheckPosition(pos); err != nil {
+			t.Errorf("CheckPosition(%q): %v", pos, err)
+		}
+	}
+	for _, pos := range []string{"middle", "top left", "left-top"} {
+		if err := CheckPosition(pos); err == nil {
+			t.Errorf("CheckPosition(%q): error nil", pos)
+		}
+	}
+}
+
+func TestSelectMonitor(t *testing.T) {
+	all := []Monitor{primary, secondary, top}
+	tests := []struct {
+		name     string
+		spec     string
+		monitors []Monitor
+		want     string
+		found    bool
+	}{
+		// Nomor dihitung dari kiri ke kanan, lalu atas ke bawah, bukan dari
+		// urutan sistem
+		{"nomor 1 paling kiri", "1", all, secondary.ID, true},
+		{"nomor 2 di atas", "2", all, top.ID, true},
+		{"nomor 3 di bawah", "3", all, primary.ID, true},
+		{"primary", "primary", all, primary.ID, true},
+		{"primary huruf besar", "PRIMARY", all, primary.ID, true},
+		{"largest", "largest", all, top.ID, true},
+		{"largest sama besar", "largest", []Monitor{primary, {ID: "kembar", Bounds: primary.Bounds}}, primary.ID, true},
+		{"satu monitor", "1", []Monitor{secondary}, secondary.ID, true},
+		// Monitor yang diminta sudah dilepas
+		{"monitor dilepas", "2", []Monitor{primary}, "", false},
+		{"nomor terlalu besar", "4", all, "", false},
+		{"tanpa primary", "primary", []Monitor{secondary, top}, "", false},
+		{"nomor 0", "0", all, "", false},
+		{"nomor negatif", "-1", all, "", false},
+		{"spec kosong", "", all, "", false},
+		{"tanpa monitor", "largest", nil, "", false},
+	}
+	for _, tt := range tests {
+		got, found := SelectMonitor(tt.spec, tt.monitors)
+		if got.ID != tt.want || found != tt.found {
+			t.Errorf("%s: SelectMonitor(%q) = %s, %v, want %s, %v", tt.name, tt.spec, got.ID, found, tt.want, tt.found)
+		}
+	}
+}
+
+func TestPlacementRect(t *testing.T) {
+	x := func(v int) *int { return &v }
+	tests := []struct {
+		name          string
+		p             Placement
+		width, height int32
+		work          Rect
+		want          Rect
+	}{
+		{"center", Placement{}, 800, 600, primary.Work, Rect{560, 220, 1360, 820}},
+		{"top-left", Placement{Position: "top-left"}, 800, 600, primary.Work, Rect{0, 0, 800, 600}},
+		{"top", Placement{Position: "top"}, 800, 600, primary.Work, Rect{560, 0, 1360, 600}},
+		{"top-right", Placement{Position: "top-right"}, 800, 600, primary.Work, Rect{1120, 0, 1920, 600}},
+		{"left", Placement{Position: "left"}, 800, 600, primary.Work, Rect{0, 220, 800, 820}},
+		{"right", Placement{Position: "right"}, 800, 600, primary.Work, Rect{1120, 220, 1920, 820}},
+		{"bottom-left", Placement{Position: "bottom-left"}, 800, 600, primary.Work, Rect{0, 440, 800, 1040}},
+		{"bottom", Placement{Position: "bottom"}, 800, 600, primary.Work, Rect{560, 440, 1360, 1040}},
+		{"bottom-right huruf besar", Placement{Position: "Bottom-Right"}, 800, 600, primary.Work, Rect{1120, 440, 1920, 1040}},
+		{"x dan y", Placement{X: x(100), Y: x(50)}, 800, 600, primary.Work, Rect{100, 50, 900, 650}},
+		{"x menimpa position", Placement{Position: "bottom-right", X: x(100)}, 800, 600, primary.Work, Rect{100, 440, 900, 1040}},
+		{"x di luar kanan", Placement{X: x(1500)}, 800, 600, primary.Work, Rect{1120, 220, 1920, 820}},
+		{"x negatif", Placement{X: x(-50), Y: x(-50)}, 800, 600, primary.Work, Rect{0, 0, 800, 600}},
+
+		// Koordinat relatif terhadap area kerja monitor dengan koordinat negatif
+		{"negatif center", Placement{}, 800, 600, secondary.Work, Rect{-1040, 12, -240, 612}},
+		{"negatif top-left", Placement{Position: "top-left"}, 800, 600, secondary.Work, Rect{-1280, -200, -480, 400}},
+		{"negatif bottom-right", Placement{Position: "bottom-right"}, 800, 600, secondary.Work, Rect{-800, 224, 0, 824}},
+		{"negatif x dan y", Placement{X: x(100), Y: x(100)}, 800, 600, secondary.Work, Rect{-1180, -100, -380, 500}},
+		{"negatif di luar kanan", Placement{X: x(1000)}, 800, 600, secondary.Work, Rect{-800, 12, 0, 612}},
+
+		// Window lebih besar dari area kerja dikecilkan
+		{"terlalu besar", Placement{}, 3000, 2000, primary.Work, primary.Work},
+		{"terlalu besar bottom-right", Placement{Position: "bottom-right"}, 3000, 2000, primary.Work, primary.Work},
+		{"terlalu lebar right", Placement{Position: "right"}, 2500, 600, primary.Work, Rect{0, 220, 1920, 820}},
+		{"terlalu tinggi y", Placement{Y: x(500)}, 800, 2000, primary.Work, Rect{560, 0, 1360, 1040}},
+		{"terlalu besar di monitor negatif", Placement{X: x(10)}, 3000, 2000, secondary.Work, secondary.Work},
+	}
+	for _, tt := range tests {
+		got := tt.p.Rect(tt.width, tt.height, tt.work)
+		if got != tt.want {
+			t.Errorf("%s: Rect(%d, %d, %+v) = %+v, want %+v", tt.name, tt.width, tt.height, tt.work, got, tt.want)
+		}
+		if got.Intersect(tt.work) != got {
+			t.Errorf("%s: Rect = %+v di luar area kerja %+v", tt.name, got, tt.work)
+		}
+	}
+}
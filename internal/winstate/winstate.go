@@ -0,0 +1,189 @@
+// Package winstate menyimpan posisi, ukuran, dan kondisi window app di antara
+// peluncuran, lalu menempatkannya kembali di monitor yang masih tersedia.
+// Package ini tidak memanggil API Windows; stub yang mengisi daftar monitor.
+package winstate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileName adalah nama file state di direktori data app
+const FileName = "window-state.json"
+
+// Rect adalah persegi dalam koordinat layar (pixel fisik)
+type Rect struct {
+	Left   int32 `json:"left"`
+	Top    int32 `json:"top"`
+	Right  int32 `json:"right"`
+	Bottom int32 `json:"bottom"`
+}
+
+func (r Rect) Width() int32  { return r.Right - r.Left }
+func (r Rect) Height() int32 { return r.Bottom - r.Top }
+
+// Empty mengecek apakah persegi tidak punya luas
+func (r Rect) Empty() bool { return r.Width() <= 0 || r.Height() <= 0 }
+
+// Intersect mengembalikan irisan dua persegi (Empty jika tidak beririsan)
+func (r Rect) Intersect(o Rect) Rect {
+	x := Rect{
+		Left:   max(r.Left, o.Left),
+		Top:    max(r.Top, o.Top),
+		Right:  min(r.Right, o.Right),
+		Bottom: min(r.Bottom, o.Bottom),
+	}
+	if x.Empty() {
+		return Rect{}
+	}
+	return x
+}
+
+func (r Rect) area() int64 {
+	if r.Empty() {
+		return 0
+	}
+	return int64(r.Width()) * int64(r.Height())
+}
+
+// Monitor adalah satu layar yang terpasang
+type Monitor struct {
+	ID      string // Nama device, mis. \\.\DISPLAY1
+	Bounds  Rect   // Seluruh layar
+	Work    Rect   // Area kerja (tanpa taskbar)
+	Primary bool
+}
+
+// State adalah kondisi window yang disimpan saat app ditutup
+type State struct {
+	Bounds    Rect   `json:"bounds"` // Posisi dan ukuran window dalam kondisi normal (tidak maximized)
+	Maximized bool   `json:"maximized,omitempty"`
+	Monitor   string `json:"monitor,omitempty"` // ID monitor tempat window terakhir berada
+
+	// Fullscreen dicatat supaya app kiosk kembali fullscreen di monitor yang
+	// sama. Apakah app fullscreen tetap ditentukan config, jadi app yang
+	// fullscreen-nya dimatikan lewat reconfigure tidak tertahan di fullscreen.
+	Fullscreen bool `json:"fullscreen,omitempty"`
+}
+
+// Load membaca state dari path. File yang belum ada bukan error: hasilnya nil.
+func Load(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var s State
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("state window %s tidak valid: %w", path, err)
+	}
+	if s.Bounds.Empty() {
+		return nil, fmt.Errorf("state window %s tidak valid: ukuran kosong", path)
+	}
+	return &s, nil
+}
+
+// Save menulis state ke path lewat file temporary, jadi app yang mati saat
+// menyimpan tidak meninggalkan file setengah jadi
+func Save(path string, s *State) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".window-state-*")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+	_, err = tmp.Write(append(data, '\n'))
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("gagal menyimpan state window: %w", err)
+	}
+	return nil
+}
+
+// Place menentukan posisi window dari state yang tersimpan. Monitor yang sama
+// dipakai jika masih ada; jika tidak, monitor terdekat. Window selalu dijepit
+// ke area kerja monitor tersebut. ok bernilai false jika tidak ada monitor.
+func Place(s *State, monitors []Monitor) (bounds Rect, monitor Monitor, ok bool) {
+	if len(monitors) == 0 {
+		return s.Bounds, Monitor{}, false
+	}
+
+	monitor = Nearest(s.Bounds, monitors)
+	for _, m := range monitors {
+		if s.Monitor != "" && m.ID == s.Monitor {
+			monitor = m
+			break
+		}
+	}
+	return Clamp(s.Bounds, monitor.Work), monitor, true
+}
+
+// Nearest mengembalikan monitor yang paling banyak beririsan dengan r, atau
+// yang paling dekat jika r tidak berada di monitor mana pun. monitors tidak
+// boleh kosong.
+func Nearest(r Rect, monitors []Monitor) Monitor {
+	best, bestArea := -1, int64(0)
+	for i, m := range monitors {
+		if a := r.Intersect(m.Bounds).area(); a > bestArea {
+			best, bestArea = i, a
+		}
+	}
+	if best >= 0 {
+		return monitors[best]
+	}
+
+	best, bestDist := 0, int64(-1)
+	for i, m := range monitors {
+		if d := distance(r, m.Bounds); bestDist < 0 || d < bestDist {
+			best, bestDist = i, d
+		}
+	}
+	return monitors[best]
+}
+
+// distance adalah kuadrat jarak terdekat antara dua persegi yang tidak beririsan
+func distance(a, b Rect) int64 {
+	dx := int64(max(b.Left-a.Right, a.Left-b.Right, 0))
+	dy := int64(max(b.Top-a.Bottom, a.Top-b.Bottom, 0))
+	return dx*dx + dy*dy
+}
+
+// Clamp memindahkan dan jika perlu mengecilkan r supaya seluruhnya berada di
+// dalam work
+func Clamp(r, work Rect) Rect {
+	w := min(r.Width(), work.Width())
+	h := min(r.Height(), work.Height())
+	left := min(max(r.Left, work.Left), work.Right-w)
+	top := min(max(r.Top, work.Top), work.Bottom-h)
+	return Rect{Left: left, Top: top, Right: left + w, Bottom: top + h}
+}
+
+// Center mengembalikan persegi berukuran width x height di tengah work,
+// dikecilkan jika lebih besar dari work
+func Center(width, height int32, work Rect) Rect {
+	w := min(width, work.Width())
+	h := min(height, work.Height())
+	left := work.Left + (work.Width()-w)/2
+	top := work.Top + (work.Height()-h)/2
+	return Rect{Left: left, Top: top, Right: left + w, Bottom: top + h}
+}
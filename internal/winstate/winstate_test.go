@@ -0,0 +1,218 @@
+package winstate
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// Monitor utama 1920x1080 dengan taskbar di bawah, dan monitor kedua di kiri
+// dengan koordinat negatif
+var (
+	primary = Monitor{
+		ID:      `\\.\DISPLAY1`,
+		Bounds:  Rect{0, 0, 1920, 1080},
+		Work:    Rect{0, 0, 1920, 1040},
+		Primary: true,
+	}
+	secondary = Monitor{
+		ID:     `\\.\DISPLAY2`,
+		Bounds: Rect{-1280, -200, 0, 824},
+		Work:   Rect{-1280, -200, 0, 824},
+	}
+	monitors = []Monitor{primary, secondary}
+)
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "profil", FileName)
+	want := &State{
+		Bounds:     Rect{-1000, -100, -200, 500},
+		Maximized:  true,
+		Monitor:    secondary.ID,
+		Fullscreen: true,
+	}
+	if err := Save(path, want); err != nil {
+		t.Fatalf("Save: %v", err)
+	}
+	got, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Load = %+v, want %+v", got, want)
+	}
+
+	// Save menimpa file lama tanpa meninggalkan file temporary
+	want.Maximized = false
+	if err := Save(path, want); err != nil {
+		t.Fatalf("Save ulang: %v", err)
+	}
+	if got, _ := Load(path); got == nil || got.Maximized {
+		t.Errorf("Load setelah Save ulang = %+v", got)
+	}
+	entries, _ := os.ReadDir(filepath.Dir(path))
+	if len(entries) != 1 {
+		t.Errorf("%d file di direktori, want 1", len(entries))
+	}
+}
+
+func TestLoadMissing(t *testing.T) {
+	s, err := Load(filepath.Join(t.TempDir(), FileName))
+	if s != nil || err != nil {
+		t.Errorf("Load = %v, %v, want nil, nil", s, err)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	for name, data := range map[string]string{
+		"corrupt":      `{"bounds": {"left": 1`,
+		"empty file":   ``,
+		"empty bounds": `{"bounds": {"left": 0, "top": 0, "right": 0, "bottom": 0}}`,
+		"no bounds":    `{"maximized": true}`,
+		"negative":     `{"bounds": {"left": 500, "top": 0, "right": 100, "bottom": 400}}`,
+	} {
+		path := filepath.Join(t.TempDir(), FileName)
+		if err := os.WriteFile(path, []byte(data), 0644); err != nil {
+			t.Fatal(err)
+		}
+		if s, err := Load(path); err == nil {
+			t.Errorf("%s: Load = %+v, error nil", name, s)
+		}
+	}
+}
+
+func TestPlace(t *testing.T) {
+	tests := []struct {
+		name        string
+		state       State
+		monitors    []Monitor
+		wantBounds  Rect
+		wantMonitor string
+	}{
+		{
+			name:        "same monitor",
+			state:       State{Bounds: Rect{100, 100, 900, 700}, Monitor: primary.ID},
+			monitors:    monitors,
+			wantBounds:  Rect{100, 100, 900, 700},
+			wantMonitor: primary.ID,
+		},
+		{
+			name:        "negative coordinates",
+			state:       State{Bounds: Rect{-1100, -150, -300, 450}, Monitor: secondary.ID},
+			monitors:    monitors,
+			wantBounds:  Rect{-1100, -150, -300, 450},
+			wantMonitor: secondary.ID,
+		},
+		{
+			// Monitor yang disimpan masih ada, tapi window sudah di luarnya
+			name:        "saved monitor wins",
+			state:       State{Bounds: Rect{100, 100, 900, 700}, Monitor: secondary.ID},
+			monitors:    monitors,
+			wantBounds:  Rect{-800, 100, 0, 700},
+			wantMonitor: secondary.ID,
+		},
+		{
+			name:        "monitor gone",
+			state:       State{Bounds: Rect{-1100, -150, -300, 450}, Monitor: secondary.ID},
+			monitors:    []Monitor{primary},
+			wantBounds:  Rect{0, 0, 800, 600},
+			wantMonitor: primary.ID,
+		},
+		{
+			name:        "unknown monitor uses overlap",
+			state:       State{Bounds: Rect{-400, 100, 200, 500}, Monitor: `\\.\DISPLAY9`},
+			monitors:    monitors,
+			wantBounds:  Rect{-600, 100, 0, 500},
+			wantMonitor: secondary.ID,
+		},
+		{
+			name:        "off screen uses nearest",
+			state:       State{Bounds: Rect{3000, 2000, 3800, 2600}},
+			monitors:    monitors,
+			wantBounds:  Rect{1120, 440, 1920, 1040},
+			wantMonitor: primary.ID,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bounds, monitor, ok := Place(&tt.state, tt.monitors)
+			if !ok {
+				t.Fatal("ok = false")
+			}
+			if bounds != tt.wantBounds {
+				t.Errorf("bounds = %+v, want %+v", bounds, tt.wantBounds)
+			}
+			if monitor.ID != tt.wantMonitor {
+				t.Errorf("monitor = %s, want %s", monitor.ID, tt.wantMonitor)
+			}
+		})
+	}
+
+	s := &State{Bounds: Rect{10, 10, 20, 20}}
+	if bounds, _, ok := Place(s, nil); ok || bounds != s.Bounds {
+		t.Errorf("Place tanpa monitor = %+v, %v", bounds, ok)
+	}
+}
+
+func TestNearest(t *testing.T) {
+	tests := []struct {
+		r    Rect
+		want string
+	}{
+		{Rect{10, 10, 20, 20}, primary.ID},
+		{Rect{-20, 10, -10, 20}, secondary.ID},
+		{Rect{-100, 0, 1000, 500}, primary.ID},   // lebih banyak di monitor utama
+		{Rect{-1000, 0, 100, 500}, secondary.ID}, // lebih banyak di monitor kedua
+		{Rect{-3000, -1000, -2000, -500}, secondary.ID},
+		{Rect{500, 1200, 900, 1500}, primary.ID},
+	}
+	for _, tt := range tests {
+		if got := Nearest(tt.r, monitors); got.ID != tt.want {
+			t.Errorf("Nearest(%+v) = %s, want %s", tt.r, got.ID, tt.want)
+		}
+	}
+}
+
+func TestClamp(t *testing.T) {
+	work := primary.Work
+	tests := []struct {
+		name string
+		r    Rect
+		want Rect
+	}{
+		{"inside", Rect{100, 100, 900, 700}, Rect{100, 100, 900, 700}},
+		{"past right", Rect{1500, 100, 2300, 700}, Rect{1120, 100, 1920, 700}},
+		{"past top left", Rect{-50, -30, 750, 570}, Rect{0, 0, 800, 600}},
+		{"under taskbar", Rect{100, 800, 900, 1400}, Rect{100, 440, 900, 1040}},
+		{"oversized", Rect{-100, -100, 2500, 1500}, Rect{0, 0, 1920, 1040}},
+		{"too wide", Rect{300, 200, 2600, 600}, Rect{0, 200, 1920, 600}},
+	}
+	for _, tt := range tests {
+		if got := Clamp(tt.r, work); got != tt.want {
+			t.Errorf("%s: Clamp(%+v) = %+v, want %+v", tt.name, tt.r, got, tt.want)
+		}
+	}
+
+	// Monitor dengan koordinat negatif
+	if got, want := Clamp(Rect{-2000, -500, -1200, 100}, secondary.Work), (Rect{-1280, -200, -480, 400}); got != want {
+		t.Errorf("Clamp di monitor kedua = %+v, want %+v", got, want)
+	}
+}
+
+func TestCenter(t *testing.T) {
+	tests := []struct {
+		width, height int32
+		work          Rect
+		want          Rect
+	}{
+		{800, 600, primary.Work, Rect{560, 220, 1360, 820}},
+		{800, 600, secondary.Work, Rect{-1040, 12, -240, 612}},
+		{3000, 2000, primary.Work, primary.Work},
+	}
+	for _, tt := range tests {
+		if got := Center(tt.width, tt.height, tt.work); got != tt.want {
+			t.Errorf("Center(%d, %d, %+v) = %+v, want %+v", tt.width, tt.height, tt.work, got, tt.want)
+		}
+	}
+}